@@ -0,0 +1,137 @@
+// Package arn builds and parses Alibaba Cloud resource names used by function compute,
+// such as trigger source ARNs, invocation roles and layer ARNs.
+//
+// An ARN has the form
+//
+//	acs:<service>:<region>:<account-id>:<resource>
+//
+// e.g. acs:oss:cn-shanghai:123:bucket or acs:ram::123:role/fc-invoke.
+package arn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	prefix = "acs"
+
+	ServiceOSS         = "oss"
+	ServiceLog         = "log"
+	ServiceMNS         = "mns"
+	ServiceCDN         = "cdn"
+	ServiceTablestore  = "ots"
+	ServiceEventBridge = "eventbridge"
+	ServiceRAM         = "ram"
+	ServiceFC          = "fc"
+)
+
+// ARN is a parsed Alibaba Cloud resource name.
+type ARN struct {
+	Service   string
+	Region    string
+	AccountID string
+	Resource  string
+}
+
+// Parse splits an ARN into its parts. The resource part may be empty, as in CDN ARNs.
+func Parse(s string) (ARN, error) {
+	parts := strings.SplitN(s, ":", 5)
+	if len(parts) < 4 || parts[0] != prefix {
+		return ARN{}, fmt.Errorf("invalid arn %q: expected acs:<service>:<region>:<account-id>:<resource>", s)
+	}
+	a := ARN{Service: parts[1], Region: parts[2], AccountID: parts[3]}
+	if len(parts) == 5 {
+		a.Resource = parts[4]
+	}
+	if a.Service == "" {
+		return ARN{}, fmt.Errorf("invalid arn %q: service is empty", s)
+	}
+	if a.AccountID == "" {
+		return ARN{}, fmt.Errorf("invalid arn %q: account id is empty", s)
+	}
+	return a, nil
+}
+
+// String formats the ARN.
+func (a ARN) String() string {
+	s := strings.Join([]string{prefix, a.Service, a.Region, a.AccountID}, ":")
+	if a.Resource != "" {
+		s += ":" + a.Resource
+	}
+	return s
+}
+
+// OSSBucket returns the ARN of an OSS bucket, the source of oss triggers.
+func OSSBucket(region, accountID, bucket string) ARN {
+	return ARN{Service: ServiceOSS, Region: region, AccountID: accountID, Resource: bucket}
+}
+
+// LogProject returns the ARN of a log service project, the source of log triggers.
+func LogProject(region, accountID, project string) ARN {
+	return ARN{Service: ServiceLog, Region: region, AccountID: accountID, Resource: "project/" + project}
+}
+
+// LogStore returns the ARN of a logstore in a log service project.
+func LogStore(region, accountID, project, logstore string) ARN {
+	return ARN{Service: ServiceLog, Region: region, AccountID: accountID,
+		Resource: "project/" + project + "/logstore/" + logstore}
+}
+
+// MNSTopic returns the ARN of a MNS topic, the source of mns_topic triggers.
+func MNSTopic(region, accountID, topic string) ARN {
+	return ARN{Service: ServiceMNS, Region: region, AccountID: accountID, Resource: "/topics/" + topic}
+}
+
+// MNSQueue returns the ARN of a MNS queue, e.g. an async invocation destination.
+func MNSQueue(region, accountID, queue string) ARN {
+	return ARN{Service: ServiceMNS, Region: region, AccountID: accountID, Resource: "/queues/" + queue}
+}
+
+// CDN returns the ARN of the CDN events of an account, the source of cdn_events triggers.
+func CDN(accountID string) ARN {
+	return ARN{Service: ServiceCDN, Region: "*", AccountID: accountID}
+}
+
+// TablestoreTable returns the ARN of a tablestore table, the source of tablestore triggers.
+func TablestoreTable(region, accountID, instance, table string) ARN {
+	return ARN{Service: ServiceTablestore, Region: region, AccountID: accountID,
+		Resource: "instance/" + instance + "/table/" + table}
+}
+
+// EventBus returns the ARN of an event bridge event bus.
+func EventBus(region, accountID, eventBus string) ARN {
+	return ARN{Service: ServiceEventBridge, Region: region, AccountID: accountID, Resource: "eventbus/" + eventBus}
+}
+
+// EventRule returns the ARN of an event bridge rule, the source of eventbridge triggers.
+func EventRule(region, accountID, eventBus, rule string) ARN {
+	return ARN{Service: ServiceEventBridge, Region: region, AccountID: accountID,
+		Resource: "eventbus/" + eventBus + "/rule/" + rule}
+}
+
+// RAMRole returns the ARN of a RAM role. RAM is a global service so the region is empty.
+func RAMRole(accountID, role string) ARN {
+	return ARN{Service: ServiceRAM, AccountID: accountID, Resource: "role/" + role}
+}
+
+// Service returns the ARN of a function compute service.
+func Service(region, accountID, service string) ARN {
+	return ARN{Service: ServiceFC, Region: region, AccountID: accountID, Resource: "services/" + service}
+}
+
+// Function returns the ARN of a function. The qualifier is optional.
+func Function(region, accountID, service, qualifier, function string) ARN {
+	if qualifier != "" {
+		service += "." + qualifier
+	}
+	return ARN{Service: ServiceFC, Region: region, AccountID: accountID,
+		Resource: "services/" + service + "/functions/" + function}
+}
+
+// Layer returns the ARN of a layer version.
+func Layer(region, accountID, layer string, version int32) ARN {
+	return ARN{Service: ServiceFC, Region: region, AccountID: accountID,
+		Resource: "layers/" + layer + "/versions/" + strconv.FormatInt(int64(version), 10)}
+}
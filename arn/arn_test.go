@@ -0,0 +1,149 @@
+package arn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestARN(t *testing.T) {
+	suite.Run(t, new(ARNTestSuite))
+}
+
+type ARNTestSuite struct {
+	suite.Suite
+}
+
+func (s *ARNTestSuite) TestBuilders() {
+	assert := s.Require()
+	assert.Equal("acs:oss:cn-shanghai:123:bucket", OSSBucket("cn-shanghai", "123", "bucket").String())
+	assert.Equal("acs:log:cn-shanghai:123:project/x", LogProject("cn-shanghai", "123", "x").String())
+	assert.Equal("acs:log:cn-shanghai:123:project/x/logstore/y", LogStore("cn-shanghai", "123", "x", "y").String())
+	assert.Equal("acs:mns:cn-shanghai:123:/topics/x", MNSTopic("cn-shanghai", "123", "x").String())
+	assert.Equal("acs:mns:cn-shanghai:123:/queues/x", MNSQueue("cn-shanghai", "123", "x").String())
+	assert.Equal("acs:cdn:*:123", CDN("123").String())
+	assert.Equal("acs:ots:cn-shanghai:123:instance/x/table/y", TablestoreTable("cn-shanghai", "123", "x", "y").String())
+	assert.Equal("acs:eventbridge:cn-shanghai:123:eventbus/default", EventBus("cn-shanghai", "123", "default").String())
+	assert.Equal("acs:eventbridge:cn-shanghai:123:eventbus/default/rule/r", EventRule("cn-shanghai", "123", "default", "r").String())
+	assert.Equal("acs:ram::123:role/fc-invoke", RAMRole("123", "fc-invoke").String())
+	assert.Equal("acs:fc:cn-shanghai:123:services/s", Service("cn-shanghai", "123", "s").String())
+	assert.Equal("acs:fc:cn-shanghai:123:services/s/functions/f", Function("cn-shanghai", "123", "s", "", "f").String())
+	assert.Equal("acs:fc:cn-shanghai:123:services/s.LATEST/functions/f", Function("cn-shanghai", "123", "s", "LATEST", "f").String())
+	assert.Equal("acs:fc:cn-shanghai:123:layers/l/versions/3", Layer("cn-shanghai", "123", "l", 3).String())
+}
+
+func (s *ARNTestSuite) TestParse() {
+	assert := s.Require()
+
+	a, err := Parse("acs:ots:cn-shanghai:123:instance/x/table/y")
+	assert.Nil(err)
+	assert.Equal(ARN{Service: "ots", Region: "cn-shanghai", AccountID: "123", Resource: "instance/x/table/y"}, a)
+
+	a, err = Parse("acs:cdn:*:123")
+	assert.Nil(err)
+	assert.Equal("", a.Resource)
+
+	for _, s := range []string{"", "dummy_arn", "arn:oss:cn-shanghai:123:bucket", "acs::cn-shanghai:123:bucket", "acs:oss:cn-shanghai::bucket", "acs:oss"} {
+		_, err := Parse(s)
+		assert.NotNil(err, s)
+	}
+}
+
+func (s *ARNTestSuite) TestRoundTrip() {
+	assert := s.Require()
+	for _, a := range []ARN{
+		OSSBucket("cn-shanghai", "123", "bucket"),
+		MNSTopic("cn-shanghai", "123", "x"),
+		CDN("123"),
+		RAMRole("123", "r"),
+		Layer("cn-shanghai", "official", "Python3-Flask2x", 2),
+	} {
+		parsed, err := Parse(a.String())
+		assert.Nil(err)
+		assert.Equal(a, parsed)
+	}
+}
+
+func (s *ARNTestSuite) TestResources() {
+	assert := s.Require()
+
+	bucket, err := OSSBucket("cn-shanghai", "123", "bucket").OSSBucket()
+	assert.Nil(err)
+	assert.Equal("bucket", bucket)
+
+	project, err := LogProject("cn-shanghai", "123", "x").LogProject()
+	assert.Nil(err)
+	assert.Equal("x", project)
+
+	topic, err := MNSTopic("cn-shanghai", "123", "t").MNSTopic()
+	assert.Nil(err)
+	assert.Equal("t", topic)
+
+	queue, err := MNSQueue("cn-shanghai", "123", "q").MNSQueue()
+	assert.Nil(err)
+	assert.Equal("q", queue)
+
+	account, err := CDN("123").CDN()
+	assert.Nil(err)
+	assert.Equal("123", account)
+
+	instance, table, err := TablestoreTable("cn-shanghai", "123", "i", "t").TablestoreTable()
+	assert.Nil(err)
+	assert.Equal([]string{"i", "t"}, []string{instance, table})
+
+	bus, rule, err := EventRule("cn-shanghai", "123", "default", "r").EventRule()
+	assert.Nil(err)
+	assert.Equal([]string{"default", "r"}, []string{bus, rule})
+
+	role, err := RAMRole("123", "r").RAMRole()
+	assert.Nil(err)
+	assert.Equal("r", role)
+
+	service, qualifier, err := Service("cn-shanghai", "123", "s").FCService()
+	assert.Nil(err)
+	assert.Equal([]string{"s", ""}, []string{service, qualifier})
+
+	service, qualifier, function, err := Function("cn-shanghai", "123", "s", "prod", "f").FCFunction()
+	assert.Nil(err)
+	assert.Equal([]string{"s", "prod", "f"}, []string{service, qualifier, function})
+
+	layer, version, err := Layer("cn-shanghai", "123", "l", 7).Layer()
+	assert.Nil(err)
+	assert.Equal("l", layer)
+	assert.Equal(int32(7), version)
+}
+
+func (s *ARNTestSuite) TestMalformedResources() {
+	assert := s.Require()
+
+	parse := func(str string) ARN {
+		a, err := Parse(str)
+		assert.Nil(err, str)
+		return a
+	}
+
+	_, err := parse("acs:log:cn-shanghai:123:bucket").OSSBucket()
+	assert.NotNil(err)
+	_, err = parse("acs:oss::123:bucket").OSSBucket()
+	assert.NotNil(err)
+	_, err = parse("acs:oss:cn-shanghai:123:bucket/key").OSSBucket()
+	assert.NotNil(err)
+	_, err = parse("acs:log:cn-shanghai:123:x").LogProject()
+	assert.NotNil(err)
+	_, err = parse("acs:mns:cn-shanghai:123:topics/x").MNSTopic()
+	assert.NotNil(err)
+	_, err = parse("acs:mns:cn-shanghai:123:/queues/x").MNSTopic()
+	assert.NotNil(err)
+	_, err = parse("acs:cdn:*:123:domain").CDN()
+	assert.NotNil(err)
+	_, _, err = parse("acs:ots:cn-shanghai:123:instance/x").TablestoreTable()
+	assert.NotNil(err)
+	_, _, err = parse("acs:ots:cn-shanghai:123:instance//table/y").TablestoreTable()
+	assert.NotNil(err)
+	_, err = parse("acs:ram:cn-hangzhou:123:role1").RAMRole()
+	assert.NotNil(err)
+	_, _, err = parse("acs:fc:cn-shanghai:123:layers/l/versions/latest").Layer()
+	assert.NotNil(err)
+	_, _, err = parse("acs:fc:cn-shanghai:123:layers/l/versions/0").Layer()
+	assert.NotNil(err)
+}
@@ -0,0 +1,205 @@
+package arn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// resourceParts splits the resource by "/" and checks the literal segments of the expected layout.
+// Names in the layout are written as "" and returned in order.
+func (a ARN) resourceParts(service string, layout ...string) ([]string, error) {
+	if a.Service != service {
+		return nil, fmt.Errorf("invalid %s arn %q: service is %q", service, a, a.Service)
+	}
+	parts := strings.Split(a.Resource, "/")
+	if len(parts) != len(layout) {
+		return nil, fmt.Errorf("invalid %s arn %q: expected resource %s", service, a, describe(layout))
+	}
+	var names []string
+	for i, want := range layout {
+		if want == "" {
+			if parts[i] == "" {
+				return nil, fmt.Errorf("invalid %s arn %q: expected resource %s", service, a, describe(layout))
+			}
+			names = append(names, parts[i])
+		} else if parts[i] != want {
+			return nil, fmt.Errorf("invalid %s arn %q: expected resource %s", service, a, describe(layout))
+		}
+	}
+	return names, nil
+}
+
+func describe(layout []string) string {
+	out := make([]string, len(layout))
+	for i, s := range layout {
+		if s == "" {
+			out[i] = "<name>"
+		} else {
+			out[i] = s
+		}
+	}
+	return strings.Join(out, "/")
+}
+
+func (a ARN) requireRegion() error {
+	if a.Region == "" {
+		return fmt.Errorf("invalid %s arn %q: region is empty", a.Service, a)
+	}
+	return nil
+}
+
+// OSSBucket returns the bucket name of an OSS bucket ARN.
+func (a ARN) OSSBucket() (string, error) {
+	if err := a.requireRegion(); err != nil {
+		return "", err
+	}
+	names, err := a.resourceParts(ServiceOSS, "")
+	if err != nil {
+		return "", err
+	}
+	return names[0], nil
+}
+
+// LogProject returns the project name of a log project ARN.
+func (a ARN) LogProject() (string, error) {
+	if err := a.requireRegion(); err != nil {
+		return "", err
+	}
+	names, err := a.resourceParts(ServiceLog, "project", "")
+	if err != nil {
+		return "", err
+	}
+	return names[0], nil
+}
+
+// MNSTopic returns the topic name of a MNS topic ARN.
+func (a ARN) MNSTopic() (string, error) {
+	if err := a.requireRegion(); err != nil {
+		return "", err
+	}
+	names, err := a.mnsResourceParts("topics")
+	if err != nil {
+		return "", err
+	}
+	return names[0], nil
+}
+
+// MNSQueue returns the queue name of a MNS queue ARN.
+func (a ARN) MNSQueue() (string, error) {
+	if err := a.requireRegion(); err != nil {
+		return "", err
+	}
+	names, err := a.mnsResourceParts("queues")
+	if err != nil {
+		return "", err
+	}
+	return names[0], nil
+}
+
+// mnsResourceParts checks the MNS resource, which unlike other services starts with a slash.
+func (a ARN) mnsResourceParts(kind string) ([]string, error) {
+	if !strings.HasPrefix(a.Resource, "/") {
+		return nil, fmt.Errorf("invalid %s arn %q: expected resource /%s/<name>", ServiceMNS, a, kind)
+	}
+	trimmed := a
+	trimmed.Resource = a.Resource[1:]
+	names, err := trimmed.resourceParts(ServiceMNS, kind, "")
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s arn %q: expected resource /%s/<name>", ServiceMNS, a, kind)
+	}
+	return names, nil
+}
+
+// CDN returns the account id of a CDN events ARN.
+func (a ARN) CDN() (string, error) {
+	if a.Service != ServiceCDN {
+		return "", fmt.Errorf("invalid %s arn %q: service is %q", ServiceCDN, a, a.Service)
+	}
+	if a.Resource != "" {
+		return "", fmt.Errorf("invalid %s arn %q: unexpected resource %q", ServiceCDN, a, a.Resource)
+	}
+	return a.AccountID, nil
+}
+
+// TablestoreTable returns the instance and table names of a tablestore table ARN.
+func (a ARN) TablestoreTable() (instance, table string, err error) {
+	if err := a.requireRegion(); err != nil {
+		return "", "", err
+	}
+	names, err := a.resourceParts(ServiceTablestore, "instance", "", "table", "")
+	if err != nil {
+		return "", "", err
+	}
+	return names[0], names[1], nil
+}
+
+// EventRule returns the event bus and rule names of an event bridge rule ARN.
+func (a ARN) EventRule() (eventBus, rule string, err error) {
+	if err := a.requireRegion(); err != nil {
+		return "", "", err
+	}
+	names, err := a.resourceParts(ServiceEventBridge, "eventbus", "", "rule", "")
+	if err != nil {
+		return "", "", err
+	}
+	return names[0], names[1], nil
+}
+
+// RAMRole returns the role name of a RAM role ARN.
+func (a ARN) RAMRole() (string, error) {
+	names, err := a.resourceParts(ServiceRAM, "role", "")
+	if err != nil {
+		return "", err
+	}
+	return names[0], nil
+}
+
+// FCService returns the service name and optional qualifier of a function compute service ARN.
+func (a ARN) FCService() (service, qualifier string, err error) {
+	if err := a.requireRegion(); err != nil {
+		return "", "", err
+	}
+	names, err := a.resourceParts(ServiceFC, "services", "")
+	if err != nil {
+		return "", "", err
+	}
+	service, qualifier = splitQualifier(names[0])
+	return service, qualifier, nil
+}
+
+// FCFunction returns the service, optional qualifier and function names of a function ARN.
+func (a ARN) FCFunction() (service, qualifier, function string, err error) {
+	if err := a.requireRegion(); err != nil {
+		return "", "", "", err
+	}
+	names, err := a.resourceParts(ServiceFC, "services", "", "functions", "")
+	if err != nil {
+		return "", "", "", err
+	}
+	service, qualifier = splitQualifier(names[0])
+	return service, qualifier, names[1], nil
+}
+
+// Layer returns the layer name and version of a layer version ARN.
+func (a ARN) Layer() (layer string, version int32, err error) {
+	if err := a.requireRegion(); err != nil {
+		return "", 0, err
+	}
+	names, err := a.resourceParts(ServiceFC, "layers", "", "versions", "")
+	if err != nil {
+		return "", 0, err
+	}
+	v, err := strconv.ParseInt(names[1], 10, 32)
+	if err != nil || v <= 0 {
+		return "", 0, fmt.Errorf("invalid %s arn %q: invalid layer version %q", ServiceFC, a, names[1])
+	}
+	return names[0], int32(v), nil
+}
+
+func splitQualifier(s string) (name, qualifier string) {
+	if i := strings.Index(s, "."); i >= 0 {
+		return s[:i], s[i+1:]
+	}
+	return s, ""
+}
@@ -0,0 +1,246 @@
+// Package bench load tests functions at a target request rate or concurrency, in ramping stages.
+//
+//	b := bench.New(bench.InvokeFunction(client, func() *fc.InvokeFunctionInput {
+//		return fc.NewInvokeFunctionInput("service", "function").WithPayload(payload)
+//	})).WithRPS().WithStage(30*time.Second, 10).WithStage(time.Minute, 100)
+//	report, err := b.Run(context.Background())
+package bench
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Modes of driving load
+const (
+	ModeRPS         = "rps"
+	ModeConcurrency = "concurrency"
+)
+
+// DefaultMaxConcurrency caps the requests in flight in rps mode
+const DefaultMaxConcurrency = 256
+
+// Stage runs for Duration while the target (requests per second or concurrent workers) ramps
+// linearly from the previous stage's target to Target. The first stage holds its target.
+type Stage struct {
+	Duration time.Duration `json:"duration"`
+	Target   float64       `json:"target"`
+}
+
+// Bench drives an Invoker through the stages.
+type Bench struct {
+	Invoker        Invoker
+	Mode           string
+	Stages         []Stage
+	MaxConcurrency int
+}
+
+// New returns a bench running in concurrency mode.
+func New(invoker Invoker) *Bench {
+	return &Bench{Invoker: invoker, Mode: ModeConcurrency, MaxConcurrency: DefaultMaxConcurrency}
+}
+
+// WithRPS drives the stage targets as requests per second.
+func (b *Bench) WithRPS() *Bench {
+	b.Mode = ModeRPS
+	return b
+}
+
+// WithConcurrency drives the stage targets as concurrent workers invoking back to back.
+func (b *Bench) WithConcurrency() *Bench {
+	b.Mode = ModeConcurrency
+	return b
+}
+
+func (b *Bench) WithStage(duration time.Duration, target float64) *Bench {
+	b.Stages = append(b.Stages, Stage{Duration: duration, Target: target})
+	return b
+}
+
+func (b *Bench) WithStages(stages ...Stage) *Bench {
+	b.Stages = append(b.Stages, stages...)
+	return b
+}
+
+// WithMaxConcurrency caps the requests in flight in rps mode; requests over the cap are counted as dropped.
+func (b *Bench) WithMaxConcurrency(maxConcurrency int) *Bench {
+	b.MaxConcurrency = maxConcurrency
+	return b
+}
+
+// Validate ...
+func (b *Bench) Validate() error {
+	if b.Invoker == nil {
+		return fmt.Errorf("Invoker is required but not provided")
+	}
+	if b.Mode != ModeRPS && b.Mode != ModeConcurrency {
+		return fmt.Errorf("invalid mode %s", b.Mode)
+	}
+	if len(b.Stages) == 0 {
+		return fmt.Errorf("Stages is required but not provided")
+	}
+	for _, s := range b.Stages {
+		if s.Duration <= 0 || s.Target < 0 {
+			return fmt.Errorf("invalid stage %s:%v", s.Duration, s.Target)
+		}
+	}
+	if b.Mode == ModeRPS && b.MaxConcurrency <= 0 {
+		return fmt.Errorf("invalid max concurrency %d", b.MaxConcurrency)
+	}
+	return nil
+}
+
+// Duration returns the total duration of the stages.
+func (b *Bench) Duration() time.Duration {
+	var total time.Duration
+	for _, s := range b.Stages {
+		total += s.Duration
+	}
+	return total
+}
+
+// targetAt returns the ramped target at the elapsed time, false once all stages are done.
+func (b *Bench) targetAt(elapsed time.Duration) (float64, bool) {
+	from := b.Stages[0].Target
+	for _, s := range b.Stages {
+		if elapsed < s.Duration {
+			return from + (s.Target-from)*float64(elapsed)/float64(s.Duration), true
+		}
+		elapsed -= s.Duration
+		from = s.Target
+	}
+	return 0, false
+}
+
+// Run drives the load until all stages are done or the context is canceled.
+func (b *Bench) Run(ctx context.Context) (*Report, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	recorder := newRecorder()
+	start := time.Now()
+	if b.Mode == ModeRPS {
+		b.runRPS(ctx, start, recorder)
+	} else {
+		b.runConcurrency(ctx, start, recorder)
+	}
+	return recorder.report(b.Mode, time.Since(start)), nil
+}
+
+const tick = 5 * time.Millisecond
+
+func (b *Bench) runRPS(ctx context.Context, start time.Time, recorder *recorder) {
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, b.MaxConcurrency)
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	last := start
+	tokens := 0.0
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case now := <-ticker.C:
+			rate, ok := b.targetAt(now.Sub(start))
+			if !ok {
+				wg.Wait()
+				return
+			}
+			tokens += rate * now.Sub(last).Seconds()
+			last = now
+			for ; tokens >= 1; tokens-- {
+				select {
+				case slots <- struct{}{}:
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						defer func() { <-slots }()
+						recorder.record(b.Invoker(ctx))
+					}()
+				default:
+					recorder.drop()
+				}
+			}
+		}
+	}
+}
+
+func (b *Bench) runConcurrency(ctx context.Context, start time.Time, recorder *recorder) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	workers := 0
+	done := false
+	worker := func(index int) {
+		defer wg.Done()
+		for {
+			mu.Lock()
+			active, stop := index < workers, done
+			mu.Unlock()
+			if stop || ctx.Err() != nil {
+				return
+			}
+			if !active {
+				time.Sleep(tick)
+				continue
+			}
+			recorder.record(b.Invoker(ctx))
+		}
+	}
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	spawned := 0
+	for {
+		target, ok := b.targetAt(time.Since(start))
+		mu.Lock()
+		workers = int(target + 0.5)
+		done = !ok || ctx.Err() != nil
+		stop := done
+		mu.Unlock()
+		if stop {
+			wg.Wait()
+			return
+		}
+		for ; spawned < workers; spawned++ {
+			wg.Add(1)
+			go worker(spawned)
+		}
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+}
+
+// ParseStages parses stages written as duration:target pairs, e.g. "30s:10,1m:100".
+func ParseStages(s string) ([]Stage, error) {
+	var stages []Stage
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid stage %s, expect duration:target", part)
+		}
+		duration, err := time.ParseDuration(kv[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid stage duration %s: %v", kv[0], err)
+		}
+		target, err := strconv.ParseFloat(kv[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stage target %s: %v", kv[1], err)
+		}
+		stages = append(stages, Stage{Duration: duration, Target: target})
+	}
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("no stages in %q", s)
+	}
+	return stages, nil
+}
@@ -0,0 +1,216 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+	"github.com/stretchr/testify/suite"
+)
+
+type BenchTestSuite struct {
+	suite.Suite
+}
+
+func TestBench(t *testing.T) {
+	suite.Run(t, new(BenchTestSuite))
+}
+
+func (s *BenchTestSuite) TestParseStages() {
+	assert := s.Require()
+
+	stages, err := ParseStages("30s:10, 1m:100")
+	assert.Nil(err)
+	assert.Equal([]Stage{{Duration: 30 * time.Second, Target: 10}, {Duration: time.Minute, Target: 100}}, stages)
+
+	for _, invalid := range []string{"", "30s", "x:1", "1s:x"} {
+		_, err := ParseStages(invalid)
+		assert.NotNil(err, invalid)
+	}
+}
+
+func (s *BenchTestSuite) TestTargetAt() {
+	assert := s.Require()
+
+	b := New(nil).WithStage(10*time.Second, 10).WithStage(10*time.Second, 110)
+	target, ok := b.targetAt(5 * time.Second)
+	assert.True(ok)
+	assert.Equal(10.0, target)
+	target, ok = b.targetAt(15 * time.Second)
+	assert.True(ok)
+	assert.Equal(60.0, target)
+	_, ok = b.targetAt(20 * time.Second)
+	assert.False(ok)
+	assert.Equal(20*time.Second, b.Duration())
+}
+
+func (s *BenchTestSuite) TestValidate() {
+	assert := s.Require()
+
+	invoker := func(ctx context.Context) Result { return Result{} }
+	assert.NotNil(New(nil).WithStage(time.Second, 1).Validate())
+	assert.NotNil(New(invoker).Validate())
+	assert.NotNil(New(invoker).WithStage(0, 1).Validate())
+	assert.NotNil(New(invoker).WithRPS().WithMaxConcurrency(0).WithStage(time.Second, 1).Validate())
+	assert.Nil(New(invoker).WithStage(time.Second, 1).Validate())
+}
+
+func (s *BenchTestSuite) TestRunConcurrency() {
+	assert := s.Require()
+
+	var inflight, peak int32
+	var calls int32
+	invoker := func(ctx context.Context) Result {
+		n := atomic.AddInt32(&inflight, 1)
+		defer atomic.AddInt32(&inflight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		call := atomic.AddInt32(&calls, 1)
+		if call%4 == 0 {
+			return Result{Latency: 10 * time.Millisecond, Error: "UnhandledInvocationError"}
+		}
+		return Result{Latency: 10 * time.Millisecond, Metrics: fc.InvocationMetrics{InstanceID: fmt.Sprintf("c-%d", call%2)}}
+	}
+	report, err := New(invoker).WithStage(200*time.Millisecond, 3).Run(context.Background())
+	assert.Nil(err)
+	assert.Equal(int32(3), peak)
+	assert.True(report.Requests > 20, report.Requests)
+	assert.Equal(report.Requests, int64(calls))
+	assert.Equal(report.Failed, report.Errors["UnhandledInvocationError"])
+	assert.Equal(report.Requests-report.Failed, report.Succeeded)
+	assert.Equal(2, report.Instances)
+	assert.Equal(int64(2), report.ColdStarts)
+	assert.Equal(10*time.Millisecond, report.Latency.P99)
+}
+
+func (s *BenchTestSuite) TestRunRPS() {
+	assert := s.Require()
+
+	invoker := func(ctx context.Context) Result {
+		time.Sleep(50 * time.Millisecond)
+		return Result{Latency: 50 * time.Millisecond}
+	}
+	report, err := New(invoker).WithRPS().WithStage(500*time.Millisecond, 100).Run(context.Background())
+	assert.Nil(err)
+	assert.InDelta(50, report.Requests, 15)
+	assert.Equal(int64(0), report.Dropped)
+
+	report, err = New(invoker).WithRPS().WithMaxConcurrency(1).WithStage(300*time.Millisecond, 100).Run(context.Background())
+	assert.Nil(err)
+	assert.True(report.Dropped > 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = New(invoker).WithRPS().WithStage(time.Minute, 10).Run(ctx)
+	assert.Nil(err)
+	assert.True(time.Since(start) < time.Second)
+}
+
+func (s *BenchTestSuite) TestInvokers() {
+	assert := s.Require()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/functions/missing/"):
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"ErrorCode":"FunctionNotFound"}`))
+		case strings.Contains(r.URL.Path, "/proxy/"):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Header().Set(fc.HTTPHeaderFCErrorType, "UnhandledInvocationError")
+			w.Header().Set(fc.HTTPHeaderInstanceID, "c-1")
+		}
+	}))
+	defer server.Close()
+	client, err := fc.NewClient(server.URL, fc.APIVersionV1, "ak", "sk")
+	assert.Nil(err)
+
+	result := InvokeFunction(client, func() *fc.InvokeFunctionInput {
+		return fc.NewInvokeFunctionInput("service", "function")
+	})(context.Background())
+	assert.Equal("UnhandledInvocationError", result.Error)
+	assert.Equal("c-1", result.Metrics.InstanceID)
+
+	result = InvokeFunction(client, func() *fc.InvokeFunctionInput {
+		return fc.NewInvokeFunctionInput("service", "missing")
+	})(context.Background())
+	assert.Equal("FunctionNotFound", result.Error)
+
+	result = DoHttpRequest(client, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, server.URL+"/2016-08-15/proxy/service/function/", nil)
+	})(context.Background())
+	assert.Equal("HTTP503", result.Error)
+
+	broken, _ := fc.NewClient("http://127.0.0.1:1", fc.APIVersionV1, "ak", "sk")
+	result = InvokeFunction(broken, func() *fc.InvokeFunctionInput {
+		return fc.NewInvokeFunctionInput("service", "function")
+	})(context.Background())
+	assert.Equal(ErrorCodeClient, result.Error)
+}
+
+func (s *BenchTestSuite) TestReportOutput() {
+	assert := s.Require()
+
+	r := newRecorder()
+	for i := 1; i <= 100; i++ {
+		r.record(Result{Latency: time.Duration(i) * time.Millisecond})
+	}
+	r.record(Result{Latency: time.Millisecond, Error: "FunctionNotFound"})
+	r.record(Result{Error: ErrorCodeClient})
+	r.record(Result{Latency: 2 * time.Millisecond, Metrics: fc.InvocationMetrics{InstanceID: "c-1"}})
+	r.record(Result{Latency: 100 * time.Millisecond, Metrics: fc.InvocationMetrics{InstanceID: "c-1"}})
+	report := r.report(ModeRPS, time.Second)
+	assert.Equal(int64(104), report.Requests)
+	assert.Equal(int64(2), report.Failed)
+	assert.Equal(int64(1), report.Errors[ErrorCodeClient])
+	assert.Equal(int64(1), report.ColdStarts)
+	assert.Equal(104.0, report.Throughput)
+	assert.Equal(100*time.Millisecond, report.Latency.Max)
+	assert.Equal(time.Millisecond, report.Latency.Min)
+	assert.Equal(int64(103), report.Histogram().Count())
+
+	buf := &bytes.Buffer{}
+	assert.Nil(report.WriteJSON(buf))
+	assert.Contains(buf.String(), `"FunctionNotFound": 1`)
+	assert.Contains(buf.String(), `"coldStarts": 1`)
+
+	buf.Reset()
+	assert.Nil(report.WriteText(buf))
+	assert.Contains(buf.String(), "error FunctionNotFound: 1")
+	assert.Contains(buf.String(), "instances: 1, cold starts: 1")
+
+	buf.Reset()
+	assert.Nil(report.WriteHdrHistogram(buf))
+	lines := strings.Split(buf.String(), "\n")
+	assert.Contains(lines[0], "Value")
+	assert.Contains(buf.String(), "     100.000 1.000000000000        103\n")
+	assert.Contains(buf.String(), "Total count    =          103]")
+}
+
+func (s *BenchTestSuite) TestReportBoundsLatencySamples() {
+	assert := s.Require()
+
+	r := newRecorder()
+	for i := 1; i <= 3*maxLatencySamples; i++ {
+		r.record(Result{Latency: time.Duration(i) * time.Microsecond})
+	}
+	report := r.report(ModeConcurrency, time.Second)
+	assert.Len(report.latencies, maxLatencySamples)
+	assert.Equal(time.Microsecond, report.Latency.Min)
+	assert.Equal(time.Duration(3*maxLatencySamples)*time.Microsecond, report.Latency.Max)
+	assert.InDelta(float64(15*time.Millisecond), float64(report.Latency.P50), float64(time.Millisecond))
+	assert.InDelta(float64(8660*time.Microsecond), float64(report.stdDev), float64(10*time.Microsecond))
+}
@@ -0,0 +1,80 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// ErrorCodeClient classifies failures that did not reach the service, e.g. network errors. Their
+// latency is left out of the report.
+const ErrorCodeClient = "ClientError"
+
+// Result is the outcome of one request.
+type Result struct {
+	Latency time.Duration
+	// Error classifies a failed request by X-Fc-Error-Type, ServiceError.ErrorCode, HTTP status
+	// or ErrorCodeClient; empty on success.
+	Error   string
+	Metrics fc.InvocationMetrics
+}
+
+// Invoker sends one request.
+type Invoker func(ctx context.Context) Result
+
+// InvokeFunction returns an Invoker calling InvokeFunction with a fresh input per request.
+func InvokeFunction(client *fc.Client, newInput func() *fc.InvokeFunctionInput) Invoker {
+	return func(ctx context.Context) Result {
+		start := time.Now()
+		output, err := client.InvokeFunction(newInput())
+		result := Result{Latency: time.Since(start)}
+		if err != nil {
+			result.Error = classifyError(err)
+			return result
+		}
+		result.Metrics = output.GetInvocationMetrics()
+		result.Error = output.GetErrorType()
+		return result
+	}
+}
+
+// DoHttpRequest returns an Invoker calling DoHttpRequest with a fresh request per call.
+func DoHttpRequest(client *fc.Client, newRequest func() (*http.Request, error)) Invoker {
+	return func(ctx context.Context) Result {
+		req, err := newRequest()
+		if err != nil {
+			return Result{Error: ErrorCodeClient}
+		}
+		start := time.Now()
+		resp, err := client.DoHttpRequest(req.WithContext(ctx))
+		if err != nil {
+			return Result{Latency: time.Since(start), Error: classifyError(err)}
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		result := Result{
+			Latency: time.Since(start),
+			Metrics: fc.InvokeFunctionOutput{Header: resp.Header}.GetInvocationMetrics(),
+			Error:   fc.GetErrorType(resp.Header),
+		}
+		if result.Error == "" && resp.StatusCode >= 400 {
+			result.Error = fmt.Sprintf("HTTP%d", resp.StatusCode)
+		}
+		return result
+	}
+}
+
+func classifyError(err error) string {
+	switch e := err.(type) {
+	case *fc.ServiceError:
+		return e.ErrorCode
+	case fc.ServiceError:
+		return e.ErrorCode
+	}
+	return ErrorCodeClient
+}
@@ -0,0 +1,236 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// Report summarizes a run. Latency covers the requests that got a response, not those failing
+// with ErrorCodeClient. Percentiles are computed from up to maxLatencySamples latencies sampled
+// uniformly, min, mean and max from all of them. A cold start is counted the first time an
+// instance serves a request, as fc.ColdStartTracker does.
+type Report struct {
+	Mode       string           `json:"mode"`
+	Duration   time.Duration    `json:"duration"`
+	Requests   int64            `json:"requests"`
+	Succeeded  int64            `json:"succeeded"`
+	Failed     int64            `json:"failed"`
+	Dropped    int64            `json:"dropped"`
+	Throughput float64          `json:"throughput"`
+	Instances  int              `json:"instances"`
+	ColdStarts int64            `json:"coldStarts"`
+	Errors     map[string]int64 `json:"errors"`
+	Latency    LatencySummary   `json:"latency"`
+
+	histogram *fc.LatencyHistogram
+	latencies []time.Duration
+	measured  int64
+	stdDev    time.Duration
+}
+
+// LatencySummary holds latency percentiles, in the JSON output as nanoseconds.
+type LatencySummary struct {
+	Min  time.Duration `json:"min"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P95  time.Duration `json:"p95"`
+	P99  time.Duration `json:"p99"`
+	P999 time.Duration `json:"p999"`
+	Max  time.Duration `json:"max"`
+}
+
+// Histogram returns the latency histogram of the run.
+func (r *Report) Histogram() *fc.LatencyHistogram {
+	return r.histogram
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(r)
+}
+
+// WriteText writes a human readable summary.
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "mode: %s, duration: %s\n", r.Mode, r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "requests: %d, succeeded: %d, failed: %d, dropped: %d, throughput: %.2f/s\n",
+		r.Requests, r.Succeeded, r.Failed, r.Dropped, r.Throughput)
+	fmt.Fprintf(w, "instances: %d, cold starts: %d\n", r.Instances, r.ColdStarts)
+	l := r.Latency
+	fmt.Fprintf(w, "latency: min=%s mean=%s p50=%s p90=%s p95=%s p99=%s p99.9=%s max=%s\n",
+		l.Min, l.Mean, l.P50, l.P90, l.P95, l.P99, l.P999, l.Max)
+	codes := make([]string, 0, len(r.Errors))
+	for code := range r.Errors {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "error %s: %d\n", code, r.Errors[code])
+	}
+	return nil
+}
+
+// WriteHdrHistogram writes the latency percentile distribution in milliseconds in the HdrHistogram
+// text format, which HdrHistogram's plotter and tools reading wrk2 output accept.
+func (r *Report) WriteHdrHistogram(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "%12s %14s %10s %14s\n\n", "Value", "Percentile", "TotalCount", "1/(1-Percentile)"); err != nil {
+		return err
+	}
+	n := len(r.latencies)
+	if n > 0 {
+		// halve the distance to 100% at each step, 5 steps per halving, as HdrHistogram does
+		for i := 0; ; i++ {
+			p := 1 - math.Pow(0.5, float64(i)/5)
+			count := int64(math.Ceil(p * float64(r.measured)))
+			if count < 1 {
+				count = 1
+			}
+			if count >= r.measured || i > 100 {
+				break
+			}
+			writeHdrLine(w, percentile(r.latencies, p*100), p, count)
+		}
+		writeHdrLine(w, r.Latency.Max, 1, r.measured)
+	}
+	_, err := fmt.Fprintf(w, "#[Mean    = %12.3f, StdDeviation   = %12.3f]\n#[Max     = %12.3f, Total count    = %12d]\n",
+		ms(r.Latency.Mean), ms(r.stdDev), ms(r.Latency.Max), r.measured)
+	return err
+}
+
+func writeHdrLine(w io.Writer, v time.Duration, p float64, count int64) {
+	if p >= 1 {
+		fmt.Fprintf(w, "%12.3f %14.12f %10d\n", ms(v), p, count)
+		return
+	}
+	fmt.Fprintf(w, "%12.3f %14.12f %10d %14.2f\n", ms(v), p, count, 1/(1-p))
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// maxLatencySamples bounds the latencies kept for percentiles; beyond it a uniform sample is kept.
+const maxLatencySamples = 10000
+
+// recorder collects results concurrently.
+type recorder struct {
+	mu        sync.Mutex
+	histogram *fc.LatencyHistogram
+	instances map[string]bool
+	requests  int64
+	errors    map[string]int64
+	failed    int64
+	dropped   int64
+
+	// latencies is a reservoir sample of the latencies of the requests that got a response
+	latencies []time.Duration
+	measured  int64
+	mean, m2  float64
+	random    *rand.Rand
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		histogram: fc.NewLatencyHistogram(),
+		instances: map[string]bool{},
+		errors:    map[string]int64{},
+		random:    rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (r *recorder) record(result Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	if result.Error != "" {
+		r.failed++
+		r.errors[result.Error]++
+	}
+	// client errors got no response, their latency says nothing about the function
+	if result.Error == ErrorCodeClient {
+		return
+	}
+	metrics := result.Metrics
+	metrics.Duration = result.Latency
+	if id := metrics.InstanceID; id != "" && !r.instances[id] {
+		r.instances[id] = true
+		metrics.ColdStart = true
+	}
+	r.histogram.Add(metrics)
+
+	r.measured++
+	if len(r.latencies) < maxLatencySamples {
+		r.latencies = append(r.latencies, result.Latency)
+	} else if i := r.random.Int63n(r.measured); i < maxLatencySamples {
+		r.latencies[i] = result.Latency
+	}
+	delta := float64(result.Latency) - r.mean
+	r.mean += delta / float64(r.measured)
+	r.m2 += delta * (float64(result.Latency) - r.mean)
+}
+
+func (r *recorder) drop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropped++
+}
+
+func (r *recorder) report(mode string, duration time.Duration) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	latencies := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	report := &Report{
+		Mode:       mode,
+		Duration:   duration,
+		Requests:   r.requests,
+		Succeeded:  r.requests - r.failed,
+		Failed:     r.failed,
+		Dropped:    r.dropped,
+		Instances:  r.histogram.Instances(),
+		ColdStarts: r.histogram.ColdStarts(),
+		Errors:     r.errors,
+		histogram:  r.histogram,
+		latencies:  latencies,
+		measured:   r.measured,
+	}
+	if duration > 0 {
+		report.Throughput = float64(r.requests) / duration.Seconds()
+	}
+	if r.measured > 0 {
+		report.stdDev = time.Duration(math.Sqrt(r.m2 / float64(r.measured)))
+		report.Latency = LatencySummary{
+			Min:  r.histogram.Percentile(0),
+			Mean: r.histogram.Mean(),
+			P50:  percentile(latencies, 50),
+			P90:  percentile(latencies, 90),
+			P95:  percentile(latencies, 95),
+			P99:  percentile(latencies, 99),
+			P999: percentile(latencies, 99.9),
+			Max:  r.histogram.Percentile(100),
+		}
+	}
+	return report
+}
+
+// percentile returns the nearest rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
@@ -273,6 +273,7 @@ func (c *Client) CreateFunction(input *CreateFunctionInput) (*CreateFunctionOutp
 
 	output.Header = httpResponse.Header()
 	json.Unmarshal(httpResponse.Body(), output)
+	output.secrets = input.secrets
 	return output, nil
 }
 
@@ -353,6 +354,7 @@ func (c *Client) UpdateFunction(input *UpdateFunctionInput) (*UpdateFunctionOutp
 	var output = new(UpdateFunctionOutput)
 	output.Header = httpResponse.Header()
 	json.Unmarshal(httpResponse.Body(), output)
+	output.secrets = input.secrets
 	return output, nil
 }
 
@@ -326,7 +326,7 @@ func (s *FcClientTestSuite) TestFunction() {
 	description := "create http trigger"
 	createTriggerInput := NewCreateTriggerInput(serviceName, functionName).WithTriggerName(triggerName).
 		WithDescription(description).WithInvocationRole(invocationRole).WithTriggerType("http").
-		WithSourceARN(sourceArn).WithTriggerConfig(
+		WithSourceARN(sourceArn).WithTypedTriggerConfig(
 		NewHTTPTriggerConfig().WithAuthType("function").WithMethods("GET", "POST"))
 
 	_, err = client.CreateTrigger(createTriggerInput)
@@ -389,7 +389,7 @@ func (s *FcClientTestSuite) testOssTrigger(client *Client, serviceName, function
 
 	createTriggerInput := NewCreateTriggerInput(serviceName, functionName).WithTriggerName(triggerName).
 		WithDescription(description).WithInvocationRole(invocationRole).WithTriggerType("oss").
-		WithSourceARN(sourceArn).WithTriggerConfig(
+		WithSourceARN(sourceArn).WithTypedTriggerConfig(
 		NewOSSTriggerConfig().WithEvents([]string{"oss:ObjectCreated:PostObject"}).WithFilterKeyPrefix(prefix).WithFilterKeySuffix(suffix))
 
 	createTriggerOutput, err := client.CreateTrigger(createTriggerInput)
@@ -410,7 +410,7 @@ func (s *FcClientTestSuite) testOssTrigger(client *Client, serviceName, function
 	listTriggersOutput, err := client.ListTriggers(NewListTriggersInput(serviceName, functionName))
 	assert.Nil(err)
 	assert.Equal(len(listTriggersOutput.Triggers), 1)
-	_, errReCreate := client.CreateTrigger(createTriggerInput.WithTriggerName(triggerName + "-new").WithTriggerConfig(
+	_, errReCreate := client.CreateTrigger(createTriggerInput.WithTriggerName(triggerName + "-new").WithTypedTriggerConfig(
 		NewOSSTriggerConfig().WithEvents([]string{"oss:ObjectCreated:PostObject"}).WithFilterKeyPrefix(prefix + "-new").WithFilterKeySuffix(suffix + "-new")))
 	assert.Nil(errReCreate)
 	listTriggersOutput2, err := client.ListTriggers(NewListTriggersInput(serviceName, functionName))
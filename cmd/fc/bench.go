@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	fc "github.com/aliyun/fc-go-sdk"
+	"github.com/aliyun/fc-go-sdk/bench"
+)
+
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	service := flags.String("service", "", "service name")
+	function := flags.String("function", "", "function name")
+	qualifier := flags.String("qualifier", "", "version or alias")
+	payload := flags.String("payload", "", "invocation payload, or @file to read it from a file")
+	httpPath := flags.String("http-path", "", "send http trigger requests to this path instead of invoking")
+	method := flags.String("method", http.MethodGet, "http method of http trigger requests")
+	rps := flags.Bool("rps", false, "stage targets are requests per second instead of concurrent workers")
+	stages := flags.String("stages", "30s:1", "comma separated duration:target stages, targets ramp linearly between stages")
+	maxConcurrency := flags.Int("max-concurrency", bench.DefaultMaxConcurrency, "requests in flight cap in rps mode")
+	output := flags.String("output", "text", "report format: text, json or hdr")
+	flags.Parse(args)
+
+	if *service == "" || *function == "" {
+		return fmt.Errorf("-service and -function are required")
+	}
+	parsed, err := bench.ParseStages(*stages)
+	if err != nil {
+		return err
+	}
+	body := []byte(*payload)
+	if strings.HasPrefix(*payload, "@") {
+		if body, err = ioutil.ReadFile((*payload)[1:]); err != nil {
+			return err
+		}
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var invoker bench.Invoker
+	if *httpPath != "" {
+		serviceWithQualifier := *service
+		if *qualifier != "" {
+			serviceWithQualifier += "." + *qualifier
+		}
+		url := fmt.Sprintf("%s/%s/proxy/%s/%s/%s", client.Config.Endpoint, client.Config.APIVersion,
+			serviceWithQualifier, *function, strings.TrimPrefix(*httpPath, "/"))
+		invoker = bench.DoHttpRequest(client, func() (*http.Request, error) {
+			return http.NewRequest(*method, url, bytes.NewReader(body))
+		})
+	} else {
+		invoker = bench.InvokeFunction(client, func() *fc.InvokeFunctionInput {
+			input := fc.NewInvokeFunctionInput(*service, *function).WithPayload(body)
+			if *qualifier != "" {
+				input.WithQualifier(*qualifier)
+			}
+			return input
+		})
+	}
+
+	b := bench.New(invoker).WithStages(parsed...).WithMaxConcurrency(*maxConcurrency)
+	if *rps {
+		b.WithRPS()
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := b.Run(ctx)
+	if err != nil {
+		return err
+	}
+	switch *output {
+	case "json":
+		return report.WriteJSON(os.Stdout)
+	case "hdr":
+		return report.WriteHdrHistogram(os.Stdout)
+	default:
+		return report.WriteText(os.Stdout)
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aliyun/fc-go-sdk/versiongc"
+)
+
+func runVersionGC(args []string) error {
+	flags := flag.NewFlagSet("version-gc", flag.ExitOnError)
+	service := flags.String("service", "", "service name")
+	keepLast := flags.Int("keep-last", 10, "always keep the newest n versions")
+	minAge := flags.Duration("min-age", 7*24*time.Hour, "always keep versions younger than this")
+	dryRun := flags.Bool("dry-run", true, "only report the versions that would be deleted")
+	flags.Parse(args)
+
+	if *service == "" {
+		return fmt.Errorf("-service is required")
+	}
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+	c := versiongc.New(client, *service).WithKeepLast(*keepLast).WithMinAge(*minAge)
+	if *dryRun {
+		c.WithDryRun()
+	}
+	report, err := c.Run()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stdout, report)
+	return nil
+}
@@ -0,0 +1,59 @@
+// Command fc runs tooling built on the SDK. The client is configured from the environment:
+// ENDPOINT, ACCESS_KEY_ID, ACCESS_KEY_SECRET and optionally SECURITY_TOKEN and ACCOUNT_ID.
+//
+//	fc bench -service s -function f -rps -stages 30s:10,1m:100
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// commands maps subcommand names to their entry points.
+var commands = map[string]func(args []string) error{
+	"bench":      runBench,
+	"version-gc": runVersionGC,
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		usage()
+		os.Exit(2)
+	}
+	if err := commands[os.Args[1]](os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "usage: fc <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, name := range names {
+		fmt.Fprintln(os.Stderr, "  "+name)
+	}
+}
+
+// newClient creates a client from the environment.
+func newClient() (*fc.Client, error) {
+	endpoint := os.Getenv("ENDPOINT")
+	if endpoint == "" {
+		return nil, fmt.Errorf("ENDPOINT is required but not provided")
+	}
+	var opts []fc.ClientOption
+	if token := os.Getenv("SECURITY_TOKEN"); token != "" {
+		opts = append(opts, fc.WithSecurityToken(token))
+	}
+	if accountID := os.Getenv("ACCOUNT_ID"); accountID != "" {
+		opts = append(opts, fc.WithAccountID(accountID))
+	}
+	return fc.NewClient(endpoint, fc.APIVersionV1, os.Getenv("ACCESS_KEY_ID"), os.Getenv("ACCESS_KEY_SECRET"), opts...)
+}
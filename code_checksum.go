@@ -0,0 +1,105 @@
+package fc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"os"
+	"strconv"
+)
+
+var crc64ECMATable = crc64.MakeTable(crc64.ECMA)
+
+// CodeChecksum returns the checksum Function Compute reports as CodeChecksum for a code zip file:
+// the CRC-64/ECMA of the file, as computed by OSS, formatted as a decimal string.
+func CodeChecksum(data []byte) string {
+	return strconv.FormatUint(crc64.Checksum(data, crc64ECMATable), 10)
+}
+
+// CodeChecksumReader returns the CodeChecksum of everything read from r.
+func CodeChecksumReader(r io.Reader) (string, error) {
+	h := crc64.New(crc64ECMATable)
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(h.Sum64(), 10), nil
+}
+
+// CodeChecksumFile returns the CodeChecksum of the zip file at path.
+func CodeChecksumFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return CodeChecksumReader(f)
+}
+
+// Checksum returns the CodeChecksum the service will report for the zip file.
+func (c *Code) Checksum() (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	if c.ZipFile == nil {
+		return "", fmt.Errorf("Zip file is required but not provided")
+	}
+	data, err := base64.StdEncoding.DecodeString(*c.ZipFile)
+	if err != nil {
+		return "", err
+	}
+	return CodeChecksum(data), nil
+}
+
+// UpdateFunctionIfCodeChanged updates the function like UpdateFunction, but leaves the inline zip
+// file out of the request when its checksum equals the CodeChecksum of the current function.
+// Unless IfMatch is set, the update is conditional on the etag of the function that was compared.
+// When nothing else is updated no request is sent and the output holds the current function.
+// The returned bool reports whether UpdateFunction was called.
+func (c *Client) UpdateFunctionIfCodeChanged(input *UpdateFunctionInput) (*UpdateFunctionOutput, bool, error) {
+	if input == nil {
+		input = new(UpdateFunctionInput)
+	}
+	if err := input.Validate(); err != nil {
+		return nil, false, err
+	}
+	if input.Code == nil || input.Code.ZipFile == nil {
+		output, err := c.UpdateFunction(input)
+		return output, err == nil, err
+	}
+	checksum, err := input.Code.Checksum()
+	if err != nil {
+		return nil, false, err
+	}
+
+	current, err := c.GetFunction(NewGetFunctionInput(*input.ServiceName, *input.FunctionName))
+	if err != nil {
+		return nil, false, err
+	}
+	update := *input
+	if update.IfMatch == nil && current.GetEtag() != "" {
+		update.WithIfMatch(current.GetEtag())
+	}
+	if current.CodeChecksum == nil || *current.CodeChecksum != checksum {
+		output, err := c.UpdateFunction(&update)
+		return output, err == nil, err
+	}
+
+	update.Code = nil
+	if isEmptyFunctionUpdate(update.FunctionUpdateObject) {
+		return &UpdateFunctionOutput{Header: current.Header, functionMetadata: current.functionMetadata, secrets: input.secrets}, false, nil
+	}
+	output, err := c.UpdateFunction(&update)
+	return output, err == nil, err
+}
+
+// isEmptyFunctionUpdate reports whether the update object sets no field.
+func isEmptyFunctionUpdate(o FunctionUpdateObject) bool {
+	payload, err := json.Marshal(o)
+	if err != nil {
+		return false
+	}
+	empty, _ := json.Marshal(FunctionUpdateObject{})
+	return string(payload) == string(empty)
+}
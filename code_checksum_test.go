@@ -0,0 +1,105 @@
+package fc
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CodeChecksumTestSuite struct {
+	suite.Suite
+}
+
+func TestCodeChecksum(t *testing.T) {
+	suite.Run(t, new(CodeChecksumTestSuite))
+}
+
+func (s *CodeChecksumTestSuite) TestChecksum() {
+	assert := s.Require()
+
+	// CRC-64/XZ check value
+	assert.Equal("11051210869376104954", CodeChecksum([]byte("123456789")))
+	assert.Equal("0", CodeChecksum(nil))
+
+	checksum, err := CodeChecksumReader(strings.NewReader("123456789"))
+	assert.Nil(err)
+	assert.Equal("11051210869376104954", checksum)
+
+	f, err := ioutil.TempFile("", "fc_checksum_")
+	assert.Nil(err)
+	defer os.Remove(f.Name())
+	f.WriteString("123456789")
+	f.Close()
+	checksum, err = CodeChecksumFile(f.Name())
+	assert.Nil(err)
+	assert.Equal("11051210869376104954", checksum)
+
+	checksum, err = NewCode().WithZipFile([]byte("123456789")).Checksum()
+	assert.Nil(err)
+	assert.Equal("11051210869376104954", checksum)
+	_, err = NewCode().Checksum()
+	assert.NotNil(err)
+}
+
+func (s *CodeChecksumTestSuite) TestUpdateFunctionIfCodeChanged() {
+	assert := s.Require()
+
+	remoteChecksum := CodeChecksum([]byte("v1"))
+	var updates []map[string]interface{}
+	var ifMatches []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2016-08-15/services/svc/functions/fn" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Etag", "etag-1")
+			w.Write([]byte(`{"functionName":"fn","codeChecksum":"` + remoteChecksum + `"}`))
+		case http.MethodPut:
+			body := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			updates = append(updates, body)
+			ifMatches = append(ifMatches, r.Header.Get("If-Match"))
+			w.Write([]byte(`{"functionName":"fn","codeChecksum":"new"}`))
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL, APIVersionV1, "ak", "sk")
+	assert.Nil(err)
+
+	// unchanged code and nothing else to update
+	output, updated, err := client.UpdateFunctionIfCodeChanged(
+		NewUpdateFunctionInput("svc", "fn").WithCode(NewCode().WithZipFile([]byte("v1"))))
+	assert.Nil(err)
+	assert.False(updated)
+	assert.Equal(remoteChecksum, *output.CodeChecksum)
+	assert.Empty(updates)
+
+	// unchanged code with another change
+	_, updated, err = client.UpdateFunctionIfCodeChanged(
+		NewUpdateFunctionInput("svc", "fn").WithMemorySize(256).WithCode(NewCode().WithZipFile([]byte("v1"))))
+	assert.Nil(err)
+	assert.True(updated)
+	assert.Len(updates, 1)
+	assert.Nil(updates[0]["code"])
+	assert.Equal(float64(256), updates[0]["memorySize"])
+	assert.Equal("etag-1", ifMatches[0])
+
+	// changed code
+	input := NewUpdateFunctionInput("svc", "fn").WithIfMatch("mine").WithCode(NewCode().WithZipFile([]byte("v2")))
+	output, updated, err = client.UpdateFunctionIfCodeChanged(input)
+	assert.Nil(err)
+	assert.True(updated)
+	assert.Equal("new", *output.CodeChecksum)
+	assert.Len(updates, 2)
+	assert.NotNil(updates[1]["code"])
+	assert.Equal("mine", ifMatches[1])
+	assert.NotNil(input.Code)
+}
@@ -0,0 +1,316 @@
+package fc
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"hash/crc64"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DownloadFunctionCode downloads the code of the function at the qualifier, verifies it against
+// the function's CodeChecksum and unpacks it into destDir with Unzip. An empty qualifier means LATEST.
+func (c *Client) DownloadFunctionCode(ctx context.Context, serviceName, functionName, qualifier, destDir string) error {
+	url, checksum, err := c.functionCodeLocation(serviceName, functionName, qualifier)
+	if err != nil {
+		return err
+	}
+	return downloadCodeToDir(ctx, url, checksum, destDir)
+}
+
+// DownloadFunctionCodeFS downloads and verifies the code of the function like DownloadFunctionCode,
+// returning the archive as an in-memory file system.
+func (c *Client) DownloadFunctionCodeFS(ctx context.Context, serviceName, functionName, qualifier string) (fs.FS, error) {
+	url, checksum, err := c.functionCodeLocation(serviceName, functionName, qualifier)
+	if err != nil {
+		return nil, err
+	}
+	return downloadCodeFS(ctx, url, checksum)
+}
+
+func (c *Client) functionCodeLocation(serviceName, functionName, qualifier string) (string, string, error) {
+	getFunction := NewGetFunctionInput(serviceName, functionName)
+	getCode := NewGetFunctionCodeInput(serviceName, functionName)
+	if qualifier != "" {
+		getFunction.WithQualifier(qualifier)
+		getCode.WithQualifier(qualifier)
+	}
+	function, err := c.GetFunction(getFunction)
+	if err != nil {
+		return "", "", err
+	}
+	code, err := c.GetFunctionCode(getCode)
+	if err != nil {
+		return "", "", err
+	}
+	checksum := ""
+	if function.CodeChecksum != nil {
+		checksum = *function.CodeChecksum
+	}
+	return code.URL, checksum, nil
+}
+
+// DownloadLayerVersion downloads the code of the layer version, verifies it against the layer's
+// CodeChecksum and unpacks it into destDir with Unzip.
+func (c *Client) DownloadLayerVersion(ctx context.Context, layerName string, version int32, destDir string) error {
+	url, checksum, err := c.layerCodeLocation(layerName, version)
+	if err != nil {
+		return err
+	}
+	return downloadCodeToDir(ctx, url, checksum, destDir)
+}
+
+// DownloadLayerVersionFS downloads and verifies the code of the layer version like
+// DownloadLayerVersion, returning the archive as an in-memory file system.
+func (c *Client) DownloadLayerVersionFS(ctx context.Context, layerName string, version int32) (fs.FS, error) {
+	url, checksum, err := c.layerCodeLocation(layerName, version)
+	if err != nil {
+		return nil, err
+	}
+	return downloadCodeFS(ctx, url, checksum)
+}
+
+// DownloadLayerVersionByArn downloads the code of the layer version with the ARN, verifies it
+// against the layer's CodeChecksum and unpacks it into destDir with Unzip.
+func (c *Client) DownloadLayerVersionByArn(ctx context.Context, arn, destDir string) error {
+	url, checksum, err := c.layerCodeLocationByArn(arn)
+	if err != nil {
+		return err
+	}
+	return downloadCodeToDir(ctx, url, checksum, destDir)
+}
+
+// DownloadLayerVersionByArnFS downloads and verifies the code of the layer version like
+// DownloadLayerVersionByArn, returning the archive as an in-memory file system.
+func (c *Client) DownloadLayerVersionByArnFS(ctx context.Context, arn string) (fs.FS, error) {
+	url, checksum, err := c.layerCodeLocationByArn(arn)
+	if err != nil {
+		return nil, err
+	}
+	return downloadCodeFS(ctx, url, checksum)
+}
+
+func (c *Client) layerCodeLocation(layerName string, version int32) (string, string, error) {
+	layer, err := c.GetLayerVersion(NewGetLayerVersionInput(layerName, version))
+	if err != nil {
+		return "", "", err
+	}
+	return layerCode(layer.Layer)
+}
+
+func (c *Client) layerCodeLocationByArn(arn string) (string, string, error) {
+	layer, err := c.GetLayerVersionByArn(NewGetLayerVersionByArnInput(arn))
+	if err != nil {
+		return "", "", err
+	}
+	return layerCode(layer.Layer)
+}
+
+func layerCode(layer Layer) (string, string, error) {
+	if layer.Code.Location == nil {
+		return "", "", fmt.Errorf("Layer code location is required but not provided")
+	}
+	return *layer.Code.Location, layer.CodeChecksum, nil
+}
+
+// fetchCode streams the zip file at url to w, verifying its CodeChecksum unless checksum is empty.
+func fetchCode(ctx context.Context, url, checksum string, w io.Writer) error {
+	if url == "" {
+		return fmt.Errorf("Code URL is required but not provided")
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download code: %s", resp.Status)
+	}
+
+	h := crc64.New(crc64ECMATable)
+	if _, err := io.Copy(io.MultiWriter(w, h), resp.Body); err != nil {
+		return err
+	}
+	if actual := strconv.FormatUint(h.Sum64(), 10); checksum != "" && actual != checksum {
+		return fmt.Errorf("code checksum mismatch: expected %s, got %s", checksum, actual)
+	}
+	return nil
+}
+
+func downloadCodeFS(ctx context.Context, url, checksum string) (*zip.Reader, error) {
+	buf := &bytes.Buffer{}
+	if err := fetchCode(ctx, url, checksum, buf); err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
+
+func downloadCodeToDir(ctx context.Context, url, checksum, destDir string) error {
+	zipFile, err := ioutil.TempFile("", "fc_temp_file_")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(zipFile.Name())
+	defer zipFile.Close()
+	if err := fetchCode(ctx, url, checksum, zipFile); err != nil {
+		return err
+	}
+	info, err := zipFile.Stat()
+	if err != nil {
+		return err
+	}
+	archive, err := zip.NewReader(zipFile, info.Size())
+	if err != nil {
+		return err
+	}
+	return Unzip(archive, destDir)
+}
+
+// Unzip unpacks the archive into destDir, restoring file modes and symlinks. Entries resolving
+// outside destDir are rejected, and symlinks are created last so no entry is written through one.
+func Unzip(archive *zip.Reader, destDir string) error {
+	destDir, err := filepath.Abs(destDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+
+	var links []*zip.File
+	for _, f := range archive.File {
+		target, err := unzipTarget(destDir, f.Name)
+		if err != nil {
+			return err
+		}
+		if err := checkNoSymlinkParents(destDir, target); err != nil {
+			return err
+		}
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			err = os.MkdirAll(target, 0755)
+			if err == nil && mode.Perm() != 0 {
+				err = os.Chmod(target, mode.Perm())
+			}
+		case mode&os.ModeSymlink != 0:
+			links = append(links, f)
+		default:
+			err = unzipFile(f, target)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, f := range links {
+		target, _ := unzipTarget(destDir, f.Name)
+		if err := unzipLink(f, destDir, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unzipTarget returns the path of the entry in destDir, rejecting names escaping it.
+func unzipTarget(destDir, name string) (string, error) {
+	if strings.Contains(name, `\`) || filepath.IsAbs(name) || strings.HasPrefix(name, "/") {
+		return "", fmt.Errorf("illegal file path in archive: %s", name)
+	}
+	target := filepath.Join(destDir, filepath.FromSlash(name))
+	rel, err := filepath.Rel(destDir, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("illegal file path in archive: %s", name)
+	}
+	return target, nil
+}
+
+// checkNoSymlinkParents fails if a directory between destDir and target is a symlink.
+func checkNoSymlinkParents(destDir, target string) error {
+	for dir := filepath.Dir(target); len(dir) > len(destDir); dir = filepath.Dir(dir) {
+		info, err := os.Lstat(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("illegal file path in archive: %s is a symlink", dir)
+		}
+	}
+	return nil
+}
+
+// removeSymlink removes a symlink left at target by an earlier unpack so it is not written through.
+func removeSymlink(target string) error {
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return os.Remove(target)
+	}
+	return nil
+}
+
+func unzipFile(f *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := removeSymlink(target); err != nil {
+		return err
+	}
+	perm := f.Mode().Perm()
+	if perm == 0 {
+		perm = 0644
+	}
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	// the mode given to OpenFile is subject to the umask
+	return os.Chmod(target, perm)
+}
+
+func unzipLink(f *zip.File, destDir, target string) error {
+	if err := checkNoSymlinkParents(destDir, target); err != nil {
+		return err
+	}
+	src, err := f.Open()
+	if err != nil {
+		return err
+	}
+	dest, err := ioutil.ReadAll(src)
+	src.Close()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(string(dest), target)
+}
@@ -0,0 +1,190 @@
+package fc
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/fs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type CodeDownloadTestSuite struct {
+	suite.Suite
+	srcDir   string
+	destDir  string
+	zipBytes []byte
+	checksum string
+	server   *httptest.Server
+	client   *Client
+}
+
+func TestCodeDownload(t *testing.T) {
+	suite.Run(t, new(CodeDownloadTestSuite))
+}
+
+func (s *CodeDownloadTestSuite) SetupTest() {
+	assert := s.Require()
+
+	var err error
+	s.srcDir, err = ioutil.TempDir("", "fc_download_src_")
+	assert.Nil(err)
+	s.destDir, err = ioutil.TempDir("", "fc_download_dest_")
+	assert.Nil(err)
+	assert.Nil(os.MkdirAll(filepath.Join(s.srcDir, "lib", "empty"), 0755))
+	assert.Nil(ioutil.WriteFile(filepath.Join(s.srcDir, "bootstrap"), []byte("#!/bin/sh"), 0755))
+	assert.Nil(ioutil.WriteFile(filepath.Join(s.srcDir, "lib", "util.py"), []byte("x = 1"), 0600))
+	assert.Nil(os.Symlink("lib/util.py", filepath.Join(s.srcDir, "util.py")))
+	buf := &bytes.Buffer{}
+	assert.Nil(ZipDir(s.srcDir, buf))
+	s.zipBytes = buf.Bytes()
+	s.checksum = CodeChecksum(s.zipBytes)
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/2016-08-15/layerarn/") {
+			r.URL.Path = "/2016-08-15/layers/lib/versions/3"
+		}
+		switch r.URL.Path {
+		case "/2016-08-15/services/svc.prod/functions/fn":
+			w.Write([]byte(`{"functionName":"fn","codeChecksum":"` + s.checksum + `"}`))
+		case "/2016-08-15/services/svc.prod/functions/fn/code":
+			w.Write([]byte(`{"url":"` + s.server.URL + `/code.zip"}`))
+		case "/2016-08-15/layers/lib/versions/3":
+			w.Write([]byte(`{"layerName":"lib","version":3,"codeChecksum":"` + s.checksum + `",
+				"code":{"repositoryType":"OSS","location":"` + s.server.URL + `/code.zip"}}`))
+		case "/code.zip":
+			w.Write(s.zipBytes)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"ErrorCode":"NotFound"}`))
+		}
+	}))
+	s.client, err = NewClient(s.server.URL, APIVersionV1, "ak", "sk")
+	assert.Nil(err)
+}
+
+func (s *CodeDownloadTestSuite) TearDownTest() {
+	s.server.Close()
+	os.RemoveAll(s.srcDir)
+	os.RemoveAll(s.destDir)
+}
+
+func (s *CodeDownloadTestSuite) assertUnpacked() {
+	assert := s.Require()
+
+	data, err := ioutil.ReadFile(filepath.Join(s.destDir, "lib", "util.py"))
+	assert.Nil(err)
+	assert.Equal("x = 1", string(data))
+	info, err := os.Stat(filepath.Join(s.destDir, "bootstrap"))
+	assert.Nil(err)
+	assert.Equal(os.FileMode(0755), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(s.destDir, "lib", "util.py"))
+	assert.Nil(err)
+	assert.Equal(os.FileMode(0600), info.Mode().Perm())
+	info, err = os.Stat(filepath.Join(s.destDir, "lib", "empty"))
+	assert.Nil(err)
+	assert.True(info.IsDir())
+	link, err := os.Readlink(filepath.Join(s.destDir, "util.py"))
+	assert.Nil(err)
+	assert.Equal("lib/util.py", link)
+}
+
+func (s *CodeDownloadTestSuite) TestDownloadFunctionCode() {
+	assert := s.Require()
+
+	assert.Nil(s.client.DownloadFunctionCode(context.Background(), "svc", "fn", "prod", s.destDir))
+	s.assertUnpacked()
+
+	// unpacking again over the previous content
+	assert.Nil(s.client.DownloadFunctionCode(context.Background(), "svc", "fn", "prod", s.destDir))
+	s.assertUnpacked()
+
+	fsys, err := s.client.DownloadFunctionCodeFS(context.Background(), "svc", "fn", "prod")
+	assert.Nil(err)
+	data, err := fs.ReadFile(fsys, "lib/util.py")
+	assert.Nil(err)
+	assert.Equal("x = 1", string(data))
+
+	_, err = s.client.DownloadFunctionCodeFS(context.Background(), "svc", "missing", "prod")
+	assert.NotNil(err)
+}
+
+func (s *CodeDownloadTestSuite) TestDownloadLayerVersion() {
+	assert := s.Require()
+
+	assert.Nil(s.client.DownloadLayerVersion(context.Background(), "lib", 3, s.destDir))
+	s.assertUnpacked()
+
+	fsys, err := s.client.DownloadLayerVersionFS(context.Background(), "lib", 3)
+	assert.Nil(err)
+	data, err := fs.ReadFile(fsys, "bootstrap")
+	assert.Nil(err)
+	assert.Equal("#!/bin/sh", string(data))
+
+	arn := "acs:fc:cn-hangzhou:123:layers/lib/versions/3"
+	assert.Nil(os.RemoveAll(s.destDir))
+	assert.Nil(s.client.DownloadLayerVersionByArn(context.Background(), arn, s.destDir))
+	s.assertUnpacked()
+	fsys, err = s.client.DownloadLayerVersionByArnFS(context.Background(), arn)
+	assert.Nil(err)
+	_, err = fs.Stat(fsys, "lib/util.py")
+	assert.Nil(err)
+}
+
+func (s *CodeDownloadTestSuite) TestChecksumMismatch() {
+	assert := s.Require()
+
+	s.checksum = "1"
+	err := s.client.DownloadFunctionCode(context.Background(), "svc", "fn", "prod", s.destDir)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "checksum mismatch")
+	_, err = os.Stat(filepath.Join(s.destDir, "bootstrap"))
+	assert.True(os.IsNotExist(err))
+}
+
+func craftZip(entries ...*zip.FileHeader) *zip.Reader {
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for _, h := range entries {
+		f, err := w.CreateHeader(h)
+		if err != nil {
+			panic(err)
+		}
+		f.Write([]byte(h.Comment))
+	}
+	w.Close()
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (s *CodeDownloadTestSuite) TestZipSlip() {
+	assert := s.Require()
+
+	outside := filepath.Join(s.srcDir, "outside")
+	assert.Nil(os.MkdirAll(outside, 0755))
+
+	err := Unzip(craftZip(&zip.FileHeader{Name: "../evil.txt", Comment: "x"}), s.destDir)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "illegal file path")
+
+	err = Unzip(craftZip(&zip.FileHeader{Name: "/etc/evil.txt", Comment: "x"}), s.destDir)
+	assert.NotNil(err)
+
+	link := &zip.FileHeader{Name: "link", Comment: outside}
+	link.SetMode(os.ModeSymlink | 0777)
+	assert.Nil(Unzip(craftZip(link), s.destDir))
+	err = Unzip(craftZip(&zip.FileHeader{Name: "link/evil.txt", Comment: "x"}), s.destDir)
+	assert.NotNil(err)
+	_, err = os.Stat(filepath.Join(outside, "evil.txt"))
+	assert.True(os.IsNotExist(err))
+}
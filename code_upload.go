@@ -0,0 +1,181 @@
+package fc
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	// DefaultInlineCodeSizeLimit is the largest base64 encoded zip file sent inline in the request body.
+	DefaultInlineCodeSizeLimit int64 = 50 << 20
+
+	// DefaultMultipartThreshold is the zip file size from which the upload to OSS is multipart.
+	DefaultMultipartThreshold int64 = 100 << 20
+
+	// DefaultUploadPartSize is the size of each part of a multipart upload.
+	DefaultUploadPartSize int64 = 16 << 20
+
+	// minUploadPartSize is the smallest part OSS accepts, except for the last one.
+	minUploadPartSize int64 = 100 << 10
+)
+
+// CodeUploader builds a Code for a zip file, inlining it when small enough and otherwise
+// uploading it to the temporary bucket returned by GetTempBucketToken.
+type CodeUploader struct {
+	client             *Client
+	ossEndpoint        string
+	httpClient         *http.Client
+	inlineSizeLimit    int64
+	multipartThreshold int64
+	partSize           int64
+}
+
+func NewCodeUploader(client *Client) *CodeUploader {
+	return &CodeUploader{
+		client:             client,
+		httpClient:         http.DefaultClient,
+		inlineSizeLimit:    DefaultInlineCodeSizeLimit,
+		multipartThreshold: DefaultMultipartThreshold,
+		partSize:           DefaultUploadPartSize,
+	}
+}
+
+// WithOSSEndpoint sets the OSS endpoint, e.g. an OSS compatible service, addressed with the bucket
+// in the path. By default the public endpoint of the temporary bucket's region is used.
+func (u *CodeUploader) WithOSSEndpoint(endpoint string) *CodeUploader {
+	u.ossEndpoint = endpoint
+	return u
+}
+
+func (u *CodeUploader) WithHTTPClient(httpClient *http.Client) *CodeUploader {
+	u.httpClient = httpClient
+	return u
+}
+
+// WithInlineSizeLimit sets the largest base64 encoded zip file sent inline; 0 always uploads to OSS.
+func (u *CodeUploader) WithInlineSizeLimit(limit int64) *CodeUploader {
+	u.inlineSizeLimit = limit
+	return u
+}
+
+func (u *CodeUploader) WithMultipartThreshold(threshold int64) *CodeUploader {
+	u.multipartThreshold = threshold
+	return u
+}
+
+func (u *CodeUploader) WithPartSize(partSize int64) *CodeUploader {
+	u.partSize = partSize
+	return u
+}
+
+func (u *CodeUploader) Validate() error {
+	if u.client == nil {
+		return fmt.Errorf("Client is required but not provided")
+	}
+	if u.inlineSizeLimit < 0 {
+		return fmt.Errorf("Inline size limit must not be negative")
+	}
+	if u.partSize < minUploadPartSize {
+		return fmt.Errorf("Part size must be at least %d bytes", minUploadPartSize)
+	}
+	return nil
+}
+
+// UploadCode returns a Code for the zip file at path.
+func (u *CodeUploader) UploadCode(ctx context.Context, zipFile string) (*Code, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(zipFile)
+	if err != nil {
+		return nil, err
+	}
+	if int64(base64.StdEncoding.EncodedLen(int(info.Size()))) <= u.inlineSizeLimit {
+		data, err := ioutil.ReadFile(zipFile)
+		if err != nil {
+			return nil, err
+		}
+		return NewCode().WithZipFile(data), nil
+	}
+
+	token, err := u.client.GetTempBucketToken()
+	if err != nil {
+		return nil, err
+	}
+	oss := u.ossClient(token)
+	f, err := os.Open(zipFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if info.Size() >= u.multipartThreshold {
+		err = oss.multipartUpload(ctx, token.ObjectName, f, u.partSize)
+	} else {
+		var data []byte
+		if data, err = ioutil.ReadAll(f); err == nil {
+			err = oss.putObject(ctx, token.ObjectName, data)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewCode().WithOSSBucketName(token.OssBucket).WithOSSObjectName(token.ObjectName), nil
+}
+
+// UploadDir zips the directory like ZipDirWithOptions and returns a Code for it.
+func (u *CodeUploader) UploadDir(ctx context.Context, dir string, opts ZipOptions) (*Code, error) {
+	zipFile, err := ioutil.TempFile("", "fc_temp_file_")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(zipFile.Name())
+	err = ZipDirWithOptions(dir, zipFile, opts)
+	if closeErr := zipFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return u.UploadCode(ctx, zipFile.Name())
+}
+
+func (u *CodeUploader) ossClient(token *GetTempBucketTokenOutput) *ossClient {
+	oss := &ossClient{
+		endpoint:   u.ossEndpoint,
+		pathStyle:  u.ossEndpoint != "",
+		bucket:     token.OssBucket,
+		creds:      token.Credentials,
+		httpClient: u.httpClient,
+	}
+	if oss.endpoint == "" {
+		region := token.OssRegion
+		if !strings.HasPrefix(region, "oss-") {
+			region = "oss-" + region
+		}
+		oss.endpoint = "https://" + region + ".aliyuncs.com"
+	}
+	return oss
+}
+
+// UploadCode returns a Code for the zip file at path, uploading it to the temporary bucket when too
+// large to be sent inline.
+func (c *Client) UploadCode(ctx context.Context, zipFile string) (*Code, error) {
+	return NewCodeUploader(c).UploadCode(ctx, zipFile)
+}
+
+// WithLargeDir packages the directory like WithDir, using the uploader to reference it from the
+// temporary bucket when it is too large to be sent inline.
+func (c *Code) WithLargeDir(uploader *CodeUploader, dir string) *Code {
+	code, err := uploader.UploadDir(context.Background(), dir, c.zipOptions)
+	if err != nil {
+		c.err = err
+		return c
+	}
+	c.OSSBucketName, c.OSSObjectName, c.ZipFile = code.OSSBucketName, code.OSSObjectName, code.ZipFile
+	return c
+}
@@ -0,0 +1,234 @@
+package fc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeOSS is a path style OSS stand-in checking signatures made with the temporary credentials.
+type fakeOSS struct {
+	sync.Mutex
+	objects map[string][]byte
+	parts   map[string]map[int][]byte
+	aborted int
+	calls   []string
+}
+
+func (f *fakeOSS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	segments := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucket, object := segments[0], segments[1]
+	query := url.Values{}
+	for k, v := range r.URL.Query() {
+		query[k] = v
+	}
+	expected := "OSS tmp-ak:" + ossSignature("tmp-sk", r.Method, r.Header, ossResource(bucket, object, query))
+	if r.Header.Get("Authorization") != expected || r.Header.Get(ossHeaderSecurityToken) != "tmp-token" {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<Error><Code>SignatureDoesNotMatch</Code><RequestId>r1</RequestId><HostId>tmp-bucket.oss</HostId></Error>`))
+		return
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	_, uploads := query["uploads"]
+	uploadID := query.Get("uploadId")
+	switch {
+	case r.Method == http.MethodPut && uploadID == "":
+		f.calls = append(f.calls, "put")
+		f.objects[object] = body
+	case r.Method == http.MethodPost && uploads:
+		f.calls = append(f.calls, "initiate")
+		f.parts["u1"] = map[int][]byte{}
+		w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>u1</UploadId></InitiateMultipartUploadResult>`))
+	case r.Method == http.MethodPut:
+		f.calls = append(f.calls, "part")
+		var number int
+		fmt.Sscanf(query.Get("partNumber"), "%d", &number)
+		f.parts[uploadID][number] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, number))
+	case r.Method == http.MethodPost:
+		f.calls = append(f.calls, "complete")
+		complete := ossCompleteMultipartUpload{}
+		xml.Unmarshal(body, &complete)
+		data := []byte{}
+		for _, p := range complete.Parts {
+			data = append(data, f.parts[uploadID][p.PartNumber]...)
+		}
+		f.objects[object] = data
+	case r.Method == http.MethodDelete:
+		f.aborted++
+	}
+}
+
+type CodeUploadTestSuite struct {
+	suite.Suite
+	oss      *fakeOSS
+	ossURL   string
+	closers  []func()
+	client   *Client
+	zipFile  string
+	zipBytes []byte
+}
+
+func TestCodeUpload(t *testing.T) {
+	suite.Run(t, new(CodeUploadTestSuite))
+}
+
+func (s *CodeUploadTestSuite) SetupTest() {
+	assert := s.Require()
+
+	s.oss = &fakeOSS{objects: map[string][]byte{}, parts: map[string]map[int][]byte{}}
+	ossServer := httptest.NewServer(s.oss)
+	s.ossURL = ossServer.URL
+	fcServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"credentials":{"AccessKeyId":"tmp-ak","AccessKeySecret":"tmp-sk","SecurityToken":"tmp-token"},
+			"ossRegion":"cn-hangzhou","ossBucket":"tmp-bucket","objectName":"123/code.zip"}`))
+	}))
+	s.closers = []func(){ossServer.Close, fcServer.Close}
+	client, err := NewClient(fcServer.URL, APIVersionV1, "ak", "sk")
+	assert.Nil(err)
+	s.client = client
+
+	s.zipBytes = bytes.Repeat([]byte("0123456789"), 30<<10)
+	f, err := ioutil.TempFile("", "fc_upload_")
+	assert.Nil(err)
+	f.Write(s.zipBytes)
+	f.Close()
+	s.zipFile = f.Name()
+}
+
+func (s *CodeUploadTestSuite) TearDownTest() {
+	for _, c := range s.closers {
+		c()
+	}
+	os.Remove(s.zipFile)
+}
+
+func (s *CodeUploadTestSuite) TestSignature() {
+	assert := s.Require()
+
+	header := http.Header{}
+	header.Set("Content-MD5", "ODBGOERFMDMzQTczRUY3NUE3NzA5QzdFNUYzMDQxNEM=")
+	header.Set("Content-Type", "text/html")
+	header.Set("Date", "Thu, 17 Nov 2005 18:49:58 GMT")
+	header.Set("X-OSS-Magic", "abracadabra")
+	header.Set("X-OSS-Meta-Author", "foo@example.com")
+	header.Set("X-Fc-Ignored", "x")
+	stringToSign := "PUT\nODBGOERFMDMzQTczRUY3NUE3NzA5QzdFNUYzMDQxNEM=\ntext/html\nThu, 17 Nov 2005 18:49:58 GMT\n" +
+		"x-oss-magic:abracadabra\nx-oss-meta-author:foo@example.com\n/oss-example/nelson"
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte(stringToSign))
+	assert.Equal(base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+		ossSignature("secret", http.MethodPut, header, "/oss-example/nelson"))
+
+	assert.Equal("/b/o?partNumber=1&uploadId=x", ossResource("b", "o", url.Values{"uploadId": {"x"}, "partNumber": {"1"}}))
+	assert.Equal("/b/o?uploads", ossResource("b", "o", url.Values{"uploads": {""}}))
+
+	oss := NewCodeUploader(s.client).ossClient(&GetTempBucketTokenOutput{OssRegion: "cn-hangzhou", OssBucket: "b"})
+	assert.Equal("https://b.oss-cn-hangzhou.aliyuncs.com/dir/a%20b.zip?uploads", oss.objectURL("dir/a b.zip", url.Values{"uploads": {""}}))
+}
+
+func (s *CodeUploadTestSuite) TestInline() {
+	assert := s.Require()
+
+	code, err := s.client.UploadCode(context.Background(), s.zipFile)
+	assert.Nil(err)
+	assert.Nil(code.OSSBucketName)
+	assert.Equal(base64.StdEncoding.EncodeToString(s.zipBytes), *code.ZipFile)
+	assert.Empty(s.oss.calls)
+}
+
+func (s *CodeUploadTestSuite) TestPutObject() {
+	assert := s.Require()
+
+	uploader := NewCodeUploader(s.client).WithOSSEndpoint(s.ossURL).WithInlineSizeLimit(1024)
+	code, err := uploader.UploadCode(context.Background(), s.zipFile)
+	assert.Nil(err)
+	assert.Nil(code.ZipFile)
+	assert.Equal("tmp-bucket", *code.OSSBucketName)
+	assert.Equal("123/code.zip", *code.OSSObjectName)
+	assert.Equal([]string{"put"}, s.oss.calls)
+	assert.Equal(s.zipBytes, s.oss.objects["123/code.zip"])
+}
+
+func (s *CodeUploadTestSuite) TestMultipart() {
+	assert := s.Require()
+
+	uploader := NewCodeUploader(s.client).WithOSSEndpoint(s.ossURL).WithInlineSizeLimit(0).
+		WithMultipartThreshold(200 << 10).WithPartSize(100 << 10)
+	code, err := uploader.UploadCode(context.Background(), s.zipFile)
+	assert.Nil(err)
+	assert.Equal("123/code.zip", *code.OSSObjectName)
+	assert.Equal([]string{"initiate", "part", "part", "part", "complete"}, s.oss.calls)
+	assert.Equal(s.zipBytes, s.oss.objects["123/code.zip"])
+
+	assert.NotNil(NewCodeUploader(s.client).WithPartSize(1).Validate())
+
+	// an empty file is uploaded as one empty part
+	s.oss.calls = nil
+	token, err := s.client.GetTempBucketToken()
+	assert.Nil(err)
+	oss := uploader.ossClient(token)
+	assert.Nil(oss.multipartUpload(context.Background(), "123/empty.zip", bytes.NewReader(nil), 100<<10))
+	assert.Equal([]string{"initiate", "part", "complete"}, s.oss.calls)
+	assert.Equal([]byte{}, s.oss.objects["123/empty.zip"])
+}
+
+func (s *CodeUploadTestSuite) TestUploadFailure() {
+	assert := s.Require()
+
+	uploader := NewCodeUploader(s.client).WithOSSEndpoint(s.ossURL).WithInlineSizeLimit(0)
+	oss := uploader.ossClient(&GetTempBucketTokenOutput{
+		OssBucket:   "tmp-bucket",
+		Credentials: Credentials{AccessKeyID: "tmp-ak", AccessKeySecret: "wrong", SecurityToken: "tmp-token"},
+	})
+	err := oss.putObject(context.Background(), "x.zip", []byte("x"))
+	assert.NotNil(err)
+	_, ok := err.(*ServiceError)
+	assert.False(ok)
+	ossError, ok := err.(*OSSError)
+	assert.True(ok)
+	assert.Equal(http.StatusForbidden, ossError.HTTPStatus)
+	assert.Equal("SignatureDoesNotMatch", ossError.Code)
+	assert.Equal("r1", ossError.RequestID)
+	assert.Equal("tmp-bucket.oss", ossError.HostID)
+	assert.Contains(err.Error(), "SignatureDoesNotMatch")
+}
+
+func (s *CodeUploadTestSuite) TestWithLargeDir() {
+	assert := s.Require()
+
+	dir, err := ioutil.TempDir("", "fc_large_dir_")
+	assert.Nil(err)
+	defer os.RemoveAll(dir)
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "main.py"), s.zipBytes, 0644))
+
+	uploader := NewCodeUploader(s.client).WithOSSEndpoint(s.ossURL).WithInlineSizeLimit(0)
+	code := NewCode().WithZipOptions(ZipOptions{Deterministic: true}).WithLargeDir(uploader, dir)
+	assert.Nil(code.err)
+	assert.Equal("tmp-bucket", *code.OSSBucketName)
+
+	buf := &bytes.Buffer{}
+	assert.Nil(ZipDirWithOptions(dir, buf, ZipOptions{Deterministic: true}))
+	assert.Equal(buf.Bytes(), s.oss.objects["123/code.zip"])
+
+	code = NewCode().WithLargeDir(uploader, filepath.Join(dir, "missing"))
+	assert.NotNil(code.err)
+}
@@ -0,0 +1,237 @@
+package fc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// configDiffIgnoredFields are metadata fields which differ between any two qualifiers
+var configDiffIgnoredFields = map[string]bool{
+	"functionId":       true,
+	"serviceId":        true,
+	"createdTime":      true,
+	"lastModifiedTime": true,
+}
+
+// ConfigChange is a changed configuration field. Path is the dotted JSON path of the field,
+// e.g. "memorySize" or "environmentVariables.LOG_LEVEL". From or To is nil when the field is unset.
+type ConfigChange struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// CompareFunctionConfigInput defines the function and the two qualifiers to compare.
+// When FromQualifier is empty the version preceding ToQualifier's version is used, the newest
+// version for LATEST.
+type CompareFunctionConfigInput struct {
+	ServiceName   *string
+	FunctionName  *string
+	FromQualifier *string
+	ToQualifier   *string
+}
+
+func NewCompareFunctionConfigInput(serviceName, functionName string) *CompareFunctionConfigInput {
+	return &CompareFunctionConfigInput{ServiceName: &serviceName, FunctionName: &functionName}
+}
+
+func (i *CompareFunctionConfigInput) WithFromQualifier(qualifier string) *CompareFunctionConfigInput {
+	i.FromQualifier = &qualifier
+	return i
+}
+
+func (i *CompareFunctionConfigInput) WithToQualifier(qualifier string) *CompareFunctionConfigInput {
+	i.ToQualifier = &qualifier
+	return i
+}
+
+func (i *CompareFunctionConfigInput) Validate() error {
+	if IsBlank(i.ServiceName) {
+		return fmt.Errorf("Service name is required but not provided")
+	}
+	if IsBlank(i.FunctionName) {
+		return fmt.Errorf("Function name is required but not provided")
+	}
+	if IsBlank(i.ToQualifier) {
+		return fmt.Errorf("To qualifier is required but not provided")
+	}
+	return nil
+}
+
+// CompareFunctionConfigOutput holds the changes from FromQualifier to ToQualifier.
+type CompareFunctionConfigOutput struct {
+	FromQualifier   string         `json:"fromQualifier"`
+	ToQualifier     string         `json:"toQualifier"`
+	ServiceChanges  []ConfigChange `json:"serviceChanges"`
+	FunctionChanges []ConfigChange `json:"functionChanges"`
+	CodeChanged     bool           `json:"codeChanged"`
+}
+
+func (o CompareFunctionConfigOutput) String() string {
+	b, err := json.MarshalIndent(o, "", printIndent)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// HasChanges reports whether anything differs between the qualifiers.
+func (o CompareFunctionConfigOutput) HasChanges() bool {
+	return o.CodeChanged || len(o.ServiceChanges) > 0 || len(o.FunctionChanges) > 0
+}
+
+// CompareFunctionConfig diffs the service and function metadata between two qualifiers
+func (c *Client) CompareFunctionConfig(input *CompareFunctionConfigInput) (*CompareFunctionConfigOutput, error) {
+	if err := input.Validate(); err != nil {
+		return nil, err
+	}
+	from := ""
+	if input.FromQualifier != nil {
+		from = *input.FromQualifier
+	}
+	to := *input.ToQualifier
+	if from == "" {
+		previous, err := c.previousVersion(*input.ServiceName, to)
+		if err != nil {
+			return nil, err
+		}
+		from = previous
+	}
+
+	fromService, err := c.GetService(NewGetServiceInput(*input.ServiceName).WithQualifier(from))
+	if err != nil {
+		return nil, err
+	}
+	toService, err := c.GetService(NewGetServiceInput(*input.ServiceName).WithQualifier(to))
+	if err != nil {
+		return nil, err
+	}
+	fromFunction, err := c.GetFunction(NewGetFunctionInput(*input.ServiceName, *input.FunctionName).WithQualifier(from))
+	if err != nil {
+		return nil, err
+	}
+	toFunction, err := c.GetFunction(NewGetFunctionInput(*input.ServiceName, *input.FunctionName).WithQualifier(to))
+	if err != nil {
+		return nil, err
+	}
+
+	return &CompareFunctionConfigOutput{
+		FromQualifier:   from,
+		ToQualifier:     to,
+		ServiceChanges:  DiffConfig(fromService.serviceMetadata, toService.serviceMetadata),
+		FunctionChanges: DiffConfig(fromFunction.functionMetadata, toFunction.functionMetadata),
+		CodeChanged:     !equalStringPtr(fromFunction.CodeChecksum, toFunction.CodeChecksum),
+	}, nil
+}
+
+// previousVersion returns the newest version older than the version the qualifier resolves to,
+// or the newest version for LATEST, which is newer than every version.
+func (c *Client) previousVersion(serviceName, qualifier string) (string, error) {
+	current, err := strconv.ParseInt(qualifier, 10, 64)
+	if strings.EqualFold(qualifier, "LATEST") {
+		current, err = math.MaxInt64, nil
+	}
+	if err != nil {
+		alias, err := c.GetAlias(NewGetAliasInput(serviceName, qualifier))
+		if err != nil {
+			return "", err
+		}
+		if alias.VersionID == nil {
+			return "", fmt.Errorf("alias %s has no version", qualifier)
+		}
+		if current, err = strconv.ParseInt(*alias.VersionID, 10, 64); err != nil {
+			return "", fmt.Errorf("invalid version ID %s of alias %s", *alias.VersionID, qualifier)
+		}
+	}
+
+	var previous int64
+	input := NewListServiceVersionsInput(serviceName)
+	for {
+		output, err := c.ListServiceVersions(input)
+		if err != nil {
+			return "", err
+		}
+		for _, v := range output.Versions {
+			if v.VersionID == nil {
+				continue
+			}
+			if id, err := strconv.ParseInt(*v.VersionID, 10, 64); err == nil && id < current && id > previous {
+				previous = id
+			}
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		input.WithNextToken(*output.NextToken)
+	}
+	if previous == 0 {
+		if current == math.MaxInt64 {
+			return "", fmt.Errorf("%w: service %s has no versions", ErrNoPreviousVersion, serviceName)
+		}
+		return "", fmt.Errorf("%w: no version precedes version %d", ErrNoPreviousVersion, current)
+	}
+	return strconv.FormatInt(previous, 10), nil
+}
+
+// DiffConfig compares two metadata values by their JSON fields, descending into objects.
+// Timestamps and resource IDs are ignored.
+func DiffConfig(from, to interface{}) []ConfigChange {
+	var changes []ConfigChange
+	diffValues("", toJSONValue(from), toJSONValue(to), &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func toJSONValue(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out interface{}
+	json.Unmarshal(b, &out)
+	return out
+}
+
+func diffValues(path string, from, to interface{}, changes *[]ConfigChange) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap || toIsMap {
+		if !fromIsMap && from != nil || !toIsMap && to != nil {
+			*changes = append(*changes, ConfigChange{Path: path, From: from, To: to})
+			return
+		}
+		keys := map[string]bool{}
+		for k := range fromMap {
+			keys[k] = true
+		}
+		for k := range toMap {
+			keys[k] = true
+		}
+		for k := range keys {
+			if path == "" && configDiffIgnoredFields[k] {
+				continue
+			}
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			diffValues(child, fromMap[k], toMap[k], changes)
+		}
+		return
+	}
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, ConfigChange{Path: path, From: from, To: to})
+	}
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return strings.EqualFold(*a, *b)
+}
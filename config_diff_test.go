@@ -0,0 +1,100 @@
+package fc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigDiffTestSuite struct {
+	suite.Suite
+}
+
+func TestConfigDiff(t *testing.T) {
+	suite.Run(t, new(ConfigDiffTestSuite))
+}
+
+func (s *ConfigDiffTestSuite) TestDiffConfig() {
+	assert := s.Require()
+
+	id1, id2, runtime := "id-1", "id-2", "python3"
+	memory1, memory2 := int32(128), int32(256)
+	from := functionMetadata{
+		FunctionID:           &id1,
+		Runtime:              &runtime,
+		MemorySize:           &memory1,
+		EnvironmentVariables: map[string]string{"A": "1", "B": "2"},
+		Layers:               []string{"acs:fc:cn-hangzhou:123:layers/l/versions/1"},
+	}
+	to := functionMetadata{
+		FunctionID:            &id2,
+		Runtime:               &runtime,
+		MemorySize:            &memory2,
+		EnvironmentVariables:  map[string]string{"A": "1", "C": "3"},
+		Layers:                []string{"acs:fc:cn-hangzhou:123:layers/l/versions/2"},
+		CustomContainerConfig: NewCustomContainerConfig().WithImage("registry/image:v2"),
+	}
+	changes := DiffConfig(from, to)
+	paths := []string{}
+	for _, c := range changes {
+		paths = append(paths, c.Path)
+	}
+	assert.Equal([]string{"customContainerConfig.image", "environmentVariables.B", "environmentVariables.C", "layers", "memorySize"}, paths)
+	assert.Equal(ConfigChange{Path: "memorySize", From: float64(128), To: float64(256)}, changes[4])
+	assert.Equal(ConfigChange{Path: "environmentVariables.B", From: "2", To: nil}, changes[1])
+	assert.Empty(DiffConfig(from, from))
+}
+
+func (s *ConfigDiffTestSuite) TestCompareFunctionConfig() {
+	assert := s.Require()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/2016-08-15")
+		switch path {
+		case "/services/svc/aliases/prod":
+			w.Write([]byte(`{"aliasName":"prod","versionId":"5"}`))
+		case "/services/svc/versions":
+			w.Write([]byte(`{"versions":[{"versionId":"6"},{"versionId":"5"},{"versionId":"3"},{"versionId":"1"}]}`))
+		case "/services/svc.3", "/services/svc.6", "/services/svc.LATEST":
+			w.Write([]byte(`{"serviceName":"svc","role":"acs:ram::123:role/a","serviceId":"x"}`))
+		case "/services/svc.prod":
+			w.Write([]byte(`{"serviceName":"svc","role":"acs:ram::123:role/b","serviceId":"x"}`))
+		case "/services/svc.3/functions/fn", "/services/svc.6/functions/fn", "/services/svc.LATEST/functions/fn":
+			w.Write([]byte(`{"functionName":"fn","handler":"index.handler","codeChecksum":"111","lastModifiedTime":"a"}`))
+		case "/services/svc.prod/functions/fn":
+			w.Write([]byte(`{"functionName":"fn","handler":"index.handler","codeChecksum":"222","lastModifiedTime":"b"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"ErrorCode":"NotFound"}`))
+		}
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL, APIVersionV1, "ak", "sk")
+	assert.Nil(err)
+
+	output, err := client.CompareFunctionConfig(NewCompareFunctionConfigInput("svc", "fn").WithToQualifier("prod"))
+	assert.Nil(err)
+	assert.Equal("3", output.FromQualifier)
+	assert.Equal("prod", output.ToQualifier)
+	assert.True(output.CodeChanged)
+	assert.True(output.HasChanges())
+	assert.Equal([]ConfigChange{{Path: "role", From: "acs:ram::123:role/a", To: "acs:ram::123:role/b"}}, output.ServiceChanges)
+	assert.Equal([]ConfigChange{{Path: "codeChecksum", From: "111", To: "222"}}, output.FunctionChanges)
+
+	_, err = client.CompareFunctionConfig(NewCompareFunctionConfigInput("svc", "fn").WithToQualifier("1"))
+	assert.NotNil(err)
+	assert.True(errors.Is(err, ErrNoPreviousVersion))
+	assert.Contains(err.Error(), "no version precedes version 1")
+
+	// LATEST is compared with the newest version
+	output, err = client.CompareFunctionConfig(NewCompareFunctionConfigInput("svc", "fn").WithToQualifier("LATEST"))
+	assert.Nil(err)
+	assert.Equal("6", output.FromQualifier)
+
+	_, err = client.CompareFunctionConfig(NewCompareFunctionConfigInput("svc", "fn"))
+	assert.NotNil(err)
+}
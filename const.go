@@ -82,3 +82,6 @@ const (
 	TracingTypeJaeger = "Jaeger"
 )
 
+// DefaultCustomRuntimePort is the port a custom runtime or custom container function listens on
+// when CAPort is not set.
+const DefaultCustomRuntimePort = 9000
@@ -0,0 +1,227 @@
+// Package cron parses the cron dialect accepted by function compute timer triggers.
+//
+// Three forms are supported:
+//
+//	0 0/5 8-18 * * MON-FRI            six fields: second minute hour day-of-month month day-of-week
+//	@every 1h30m                      fixed interval, at least one minute
+//	CRON_TZ=Asia/Shanghai 0 0 8 * * * either form prefixed with the time zone it is evaluated in
+//
+// Expressions without CRON_TZ prefix are evaluated in UTC, as the service does.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinEveryInterval is the shortest interval accepted by @every expressions.
+const MinEveryInterval = time.Minute
+
+// Schedule describes when a timer trigger fires.
+type Schedule interface {
+	// Next returns the first activation time strictly after t, or the zero time if there is none.
+	Next(t time.Time) time.Time
+}
+
+type bounds struct {
+	name     string
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	secondBounds = bounds{name: "second", min: 0, max: 59}
+	minuteBounds = bounds{name: "minute", min: 0, max: 59}
+	hourBounds   = bounds{name: "hour", min: 0, max: 23}
+	domBounds    = bounds{name: "day-of-month", min: 1, max: 31}
+	monthBounds  = bounds{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowBounds = bounds{name: "day-of-week", min: 0, max: 6, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// Parse parses a timer trigger cron expression.
+func Parse(expr string) (Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if spec == "" {
+		return nil, fmt.Errorf("invalid cron expression: empty")
+	}
+
+	loc := time.UTC
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		i := strings.IndexAny(spec, " \t")
+		if i == -1 {
+			return nil, fmt.Errorf("invalid cron expression %q: missing schedule after time zone", expr)
+		}
+		zone := spec[strings.Index(spec, "=")+1 : i]
+		var err error
+		if loc, err = time.LoadLocation(zone); err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: unknown time zone %q", expr, zone)
+		}
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		return parseDescriptor(expr, spec)
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 6 fields (second minute hour day-of-month month day-of-week), got %d", expr, len(fields))
+	}
+
+	s := &SpecSchedule{Location: loc}
+	var err error
+	if s.Second, err = parseField(fields[0], secondBounds, false); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	if s.Minute, err = parseField(fields[1], minuteBounds, false); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	if s.Hour, err = parseField(fields[2], hourBounds, false); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	if s.Dom, err = parseField(fields[3], domBounds, true); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	if s.Month, err = parseField(fields[4], monthBounds, false); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	if s.Dow, err = parseField(fields[5], dowBounds, true); err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	s.domStar = isStar(fields[3])
+	s.dowStar = isStar(fields[5])
+	return s, nil
+}
+
+// Validate reports whether expr is a valid timer trigger cron expression.
+func Validate(expr string) error {
+	_, err := Parse(expr)
+	return err
+}
+
+// NextN returns the next n activation times of the schedule strictly after from, or nil if n
+// is not positive.
+func NextN(s Schedule, n int, from time.Time) []time.Time {
+	if n <= 0 {
+		return nil
+	}
+	out := make([]time.Time, 0, n)
+	t := from
+	for i := 0; i < n; i++ {
+		t = s.Next(t)
+		if t.IsZero() {
+			break
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+func parseDescriptor(expr, spec string) (Schedule, error) {
+	const every = "@every "
+	if !strings.HasPrefix(spec, every) {
+		return nil, fmt.Errorf("invalid cron expression %q: unsupported descriptor, only @every is supported", expr)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(spec[len(every):]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %v", expr, err)
+	}
+	if d < MinEveryInterval {
+		return nil, fmt.Errorf("invalid cron expression %q: interval must be at least %v", expr, MinEveryInterval)
+	}
+	return EverySchedule{Interval: d.Truncate(time.Second)}, nil
+}
+
+func isStar(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// parseField parses a comma separated list of ranges into a bit set.
+func parseField(field string, b bounds, allowQuestion bool) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		r, err := parseRange(part, b, allowQuestion)
+		if err != nil {
+			return 0, err
+		}
+		bits |= r
+	}
+	return bits, nil
+}
+
+// parseRange parses "*", "?", "a", "a-b" optionally followed by "/step".
+func parseRange(expr string, b bounds, allowQuestion bool) (uint64, error) {
+	rangeAndStep := strings.Split(expr, "/")
+	if len(rangeAndStep) > 2 {
+		return 0, fmt.Errorf("%s field %q: too many slashes", b.name, expr)
+	}
+	var start, end uint
+	lowAndHigh := strings.Split(rangeAndStep[0], "-")
+	switch {
+	case rangeAndStep[0] == "*":
+		start, end = b.min, b.max
+	case rangeAndStep[0] == "?":
+		if !allowQuestion {
+			return 0, fmt.Errorf("%s field %q: '?' is only allowed in day-of-month and day-of-week", b.name, expr)
+		}
+		if len(rangeAndStep) > 1 {
+			return 0, fmt.Errorf("%s field %q: '?' does not take a step", b.name, expr)
+		}
+		start, end = b.min, b.max
+	case len(lowAndHigh) > 2:
+		return 0, fmt.Errorf("%s field %q: too many hyphens", b.name, expr)
+	default:
+		var err error
+		if start, err = parseValue(lowAndHigh[0], b); err != nil {
+			return 0, err
+		}
+		end = start
+		if len(lowAndHigh) == 2 {
+			if end, err = parseValue(lowAndHigh[1], b); err != nil {
+				return 0, err
+			}
+		} else if len(rangeAndStep) == 2 {
+			// "a/step" means from a to the end of the range.
+			end = b.max
+		}
+	}
+	if start > end {
+		return 0, fmt.Errorf("%s field %q: beginning of range after end", b.name, expr)
+	}
+
+	step := uint(1)
+	if len(rangeAndStep) == 2 {
+		n, err := strconv.ParseUint(rangeAndStep[1], 10, 8)
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("%s field %q: invalid step %q", b.name, expr, rangeAndStep[1])
+		}
+		step = uint(n)
+	}
+
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << i
+	}
+	return bits, nil
+}
+
+func parseValue(s string, b bounds) (uint, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%s field: invalid value %q", b.name, s)
+	}
+	if uint(n) < b.min || uint(n) > b.max {
+		return 0, fmt.Errorf("%s field: value %d out of range [%d, %d]", b.name, n, b.min, b.max)
+	}
+	return uint(n), nil
+}
@@ -0,0 +1,181 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestCron(t *testing.T) {
+	suite.Run(t, new(CronTestSuite))
+}
+
+type CronTestSuite struct {
+	suite.Suite
+}
+
+var from = time.Date(2021, time.March, 1, 10, 15, 30, 0, time.UTC) // a Monday
+
+func (s *CronTestSuite) next(expr string, n int) []time.Time {
+	assert := s.Require()
+	schedule, err := Parse(expr)
+	assert.Nil(err, expr)
+	return NextN(schedule, n, from)
+}
+
+func (s *CronTestSuite) TestValid() {
+	assert := s.Require()
+	for _, expr := range []string{
+		"0 0 4 * * *",
+		"*/10 * * * * *",
+		"0 0/5 8-18 * * MON-FRI",
+		"0 30 9 1,15 * ?",
+		"0 0 0 ? JAN-MAR SUN",
+		"@every 1m",
+		"@every 1h30m",
+		"CRON_TZ=Asia/Shanghai 0 0 8 * * *",
+		"CRON_TZ=UTC @every 5m",
+	} {
+		assert.Nil(Validate(expr), expr)
+	}
+}
+
+func (s *CronTestSuite) TestInvalid() {
+	assert := s.Require()
+	for _, expr := range []string{
+		"",
+		"0 0 4 * *",
+		"0 0 4 * * * *",
+		"60 * * * * *",
+		"* 60 * * * *",
+		"* * 24 * * *",
+		"* * * 0 * *",
+		"* * * * 13 *",
+		"* * * * * 7",
+		"? * * * * *",
+		"* * * * * ?/2",
+		"* * 5-3 * * *",
+		"*/0 * * * * *",
+		"1-2-3 * * * * *",
+		"a * * * * *",
+		"@hourly",
+		"@every 30s",
+		"@every soon",
+		"CRON_TZ=Mars/Olympus 0 0 8 * * *",
+		"CRON_TZ=UTC",
+	} {
+		assert.NotNil(Validate(expr), expr)
+	}
+}
+
+func (s *CronTestSuite) TestDaily() {
+	assert := s.Require()
+	assert.Equal([]time.Time{
+		time.Date(2021, time.March, 2, 4, 0, 0, 0, time.UTC),
+		time.Date(2021, time.March, 3, 4, 0, 0, 0, time.UTC),
+	}, s.next("0 0 4 * * *", 2))
+}
+
+func (s *CronTestSuite) TestSeconds() {
+	assert := s.Require()
+	assert.Equal([]time.Time{
+		time.Date(2021, time.March, 1, 10, 15, 40, 0, time.UTC),
+		time.Date(2021, time.March, 1, 10, 15, 50, 0, time.UTC),
+		time.Date(2021, time.March, 1, 10, 16, 0, 0, time.UTC),
+	}, s.next("*/10 * * * * *", 3))
+}
+
+func (s *CronTestSuite) TestWeekdays() {
+	assert := s.Require()
+	times := s.next("0 0 9 * * SAT,SUN", 3)
+	assert.Equal([]time.Time{
+		time.Date(2021, time.March, 6, 9, 0, 0, 0, time.UTC),
+		time.Date(2021, time.March, 7, 9, 0, 0, 0, time.UTC),
+		time.Date(2021, time.March, 13, 9, 0, 0, 0, time.UTC),
+	}, times)
+}
+
+func (s *CronTestSuite) TestDayOfMonthOrDayOfWeek() {
+	assert := s.Require()
+	// both day fields restricted: fires on the 5th and on every Friday
+	assert.Equal([]time.Time{
+		time.Date(2021, time.March, 5, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, time.March, 12, 0, 0, 0, 0, time.UTC),
+	}, s.next("0 0 0 5 * FRI", 2))
+	// Sunday the 5th only when day-of-week is left open
+	assert.Equal([]time.Time{
+		time.Date(2021, time.April, 5, 0, 0, 0, 0, time.UTC),
+	}, s.next("0 0 0 5 APR ?", 1))
+}
+
+func (s *CronTestSuite) TestMonthWrap() {
+	assert := s.Require()
+	assert.Equal([]time.Time{
+		time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC),
+	}, s.next("0 0 0 1 FEB *", 1))
+	assert.Equal([]time.Time{
+		time.Date(2021, time.March, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2021, time.May, 31, 0, 0, 0, 0, time.UTC),
+	}, s.next("0 0 0 31 * *", 2))
+}
+
+func (s *CronTestSuite) TestTimeZone() {
+	assert := s.Require()
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		s.T().Skip("time zone database not available")
+	}
+	times := s.next("CRON_TZ=Asia/Shanghai 0 0 8 * * *", 1)
+	assert.Equal(1, len(times))
+	assert.Equal(time.Date(2021, time.March, 2, 8, 0, 0, 0, shanghai).Unix(), times[0].Unix())
+	assert.Equal(time.UTC, times[0].Location())
+}
+
+func (s *CronTestSuite) TestDaylightSavingFallBack() {
+	assert := s.Require()
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		s.T().Skip("time zone database not available")
+	}
+	schedule, err := Parse("CRON_TZ=America/New_York 0 45 1 * * *")
+	assert.Nil(err)
+
+	// 01:00 to 02:00 happens twice on 2021-11-07, first in EDT (UTC-4) then in EST (UTC-5)
+	firstHalf := time.Date(2021, time.November, 7, 5, 30, 0, 0, time.UTC)
+	secondHalf := time.Date(2021, time.November, 7, 6, 30, 0, 0, time.UTC)
+	assert.Equal("01:30 EDT", firstHalf.In(newYork).Format("15:04 MST"))
+	assert.Equal("01:30 EST", secondHalf.In(newYork).Format("15:04 MST"))
+
+	assert.Equal(time.Date(2021, time.November, 7, 5, 45, 0, 0, time.UTC), schedule.Next(firstHalf))
+	next := schedule.Next(secondHalf)
+	assert.True(next.After(secondHalf), next.String())
+	assert.Equal(time.Date(2021, time.November, 8, 6, 45, 0, 0, time.UTC), next)
+
+	every, err := Parse("CRON_TZ=America/New_York 0 */20 * * * *")
+	assert.Nil(err)
+	prev := secondHalf
+	for _, t := range NextN(every, 10, secondHalf) {
+		assert.True(t.After(prev), t.String())
+		prev = t
+	}
+}
+
+func (s *CronTestSuite) TestNextNNotPositive() {
+	assert := s.Require()
+	assert.Nil(s.next("0 0 4 * * *", 0))
+	assert.Nil(s.next("0 0 4 * * *", -1))
+}
+
+func (s *CronTestSuite) TestEvery() {
+	assert := s.Require()
+	assert.Equal([]time.Time{
+		from.Add(90 * time.Minute),
+		from.Add(180 * time.Minute),
+	}, s.next("@every 1h30m", 2))
+}
+
+func (s *CronTestSuite) TestImpossible() {
+	assert := s.Require()
+	assert.Empty(s.next("0 0 0 30 FEB ?", 1))
+}
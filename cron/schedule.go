@@ -0,0 +1,98 @@
+package cron
+
+import "time"
+
+// SpecSchedule is a six field cron schedule. Each field is a bit set of the allowed values.
+type SpecSchedule struct {
+	Second, Minute, Hour, Dom, Month, Dow uint64
+	Location                              *time.Location
+
+	// domStar and dowStar record whether the day fields were unrestricted.
+	// When both are restricted a day matches if either of them matches.
+	domStar, dowStar bool
+}
+
+// Next returns the first activation time strictly after t, in t's location.
+// The zero time is returned if no activation exists within five years. Wall clock times in the hour
+// repeated when daylight saving time ends activate only in its first occurrence.
+func (s *SpecSchedule) Next(t time.Time) time.Time {
+	// Normalizing a wall clock time in the hour repeated when daylight saving time ends can go
+	// back to its first occurrence, before t; search again from there until past t.
+	next := s.next(t)
+	for !next.IsZero() && !next.After(t) {
+		next = s.next(next)
+	}
+	return next
+}
+
+func (s *SpecSchedule) next(t time.Time) time.Time {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	origLoc := t.Location()
+	t = t.In(loc).Truncate(time.Second).Add(time.Second)
+	yearLimit := t.Year() + 5
+
+WRAP:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for 1<<uint(t.Month())&s.Month == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		if t.Month() == time.January {
+			goto WRAP
+		}
+	}
+
+	for !s.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		if t.Day() == 1 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Hour())&s.Hour == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		if t.Hour() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Minute())&s.Minute == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+		if t.Minute() == 0 {
+			goto WRAP
+		}
+	}
+
+	for 1<<uint(t.Second())&s.Second == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second()+1, 0, loc)
+		if t.Second() == 0 {
+			goto WRAP
+		}
+	}
+
+	return t.In(origLoc)
+}
+
+func (s *SpecSchedule) dayMatches(t time.Time) bool {
+	domMatch := 1<<uint(t.Day())&s.Dom > 0
+	dowMatch := 1<<uint(t.Weekday())&s.Dow > 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// EverySchedule fires at a fixed interval. The service anchors the interval to
+// the time the trigger is created, so Next counts from the given time.
+type EverySchedule struct {
+	Interval time.Duration
+}
+
+// Next returns t plus the interval, rounded down to the second.
+func (s EverySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.Interval).Truncate(time.Second)
+}
@@ -0,0 +1,333 @@
+// Package emulator runs a custom runtime or custom container function locally and exposes
+// an endpoint fc.Client can invoke it through:
+//
+//	e := emulator.New("service", function).WithCodeDir("./code")
+//	if err := e.Start(); err != nil {
+//		return err
+//	}
+//	defer e.Close()
+//	client, _ := fc.NewClient(e.Endpoint(), fc.APIVersionV1, "ak", "sk")
+//
+// Container images are not pulled; the CustomContainerConfig command runs on the host.
+package emulator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// Defaults applied when the function does not configure them
+const (
+	DefaultTimeout               = 3
+	DefaultInitializationTimeout = 3
+	DefaultInstanceConcurrency   = 1
+	DefaultStartupTimeout        = 10 * time.Second
+)
+
+// Emulator runs one instance of a function.
+type Emulator struct {
+	ServiceName    string
+	Function       *fc.FunctionCreateObject
+	CodeDir        string
+	Command        []string
+	Region         string
+	AccountID      string
+	Credentials    Credentials
+	StartupTimeout time.Duration
+	QueueTimeout   time.Duration
+	Output         io.Writer
+
+	cmd      *exec.Cmd
+	exited   chan struct{}
+	listener net.Listener
+	server   *http.Server
+	client   *http.Client
+	slots    chan struct{}
+
+	initMu      sync.Mutex
+	initialized bool
+
+	// ctx is cancelled on Close to stop async invocations, which async tracks.
+	ctx     context.Context
+	cancel  context.CancelFunc
+	asyncMu sync.Mutex
+	async   sync.WaitGroup
+	closed  bool
+}
+
+// Credentials are passed to the function as the service role credentials.
+type Credentials struct {
+	AccessKeyID     string
+	AccessKeySecret string
+	SecurityToken   string
+}
+
+// New returns an emulator for the function of the service.
+func New(serviceName string, function *fc.FunctionCreateObject) *Emulator {
+	return &Emulator{
+		ServiceName:    serviceName,
+		Function:       function,
+		CodeDir:        ".",
+		Region:         "cn-local",
+		AccountID:      "123456789",
+		StartupTimeout: DefaultStartupTimeout,
+		Output:         os.Stderr,
+	}
+}
+
+// WithCodeDir sets the directory holding the function code, where the bootstrap is looked up.
+func (e *Emulator) WithCodeDir(dir string) *Emulator {
+	e.CodeDir = dir
+	return e
+}
+
+// WithCommand overrides the command launching the function.
+func (e *Emulator) WithCommand(command ...string) *Emulator {
+	e.Command = command
+	return e
+}
+
+func (e *Emulator) WithRegion(region string) *Emulator {
+	e.Region = region
+	return e
+}
+
+func (e *Emulator) WithAccountID(accountID string) *Emulator {
+	e.AccountID = accountID
+	return e
+}
+
+func (e *Emulator) WithCredentials(accessKeyID, accessKeySecret, securityToken string) *Emulator {
+	e.Credentials = Credentials{AccessKeyID: accessKeyID, AccessKeySecret: accessKeySecret, SecurityToken: securityToken}
+	return e
+}
+
+func (e *Emulator) WithStartupTimeout(timeout time.Duration) *Emulator {
+	e.StartupTimeout = timeout
+	return e
+}
+
+// WithQueueTimeout sets how long a synchronous invocation waits for a free instance slot before
+// failing with ResourceExhausted. Function Compute starts more instances instead while the emulator
+// runs one, so waiting is the closer behavior. 0, the default, waits up to the function timeout and
+// a negative timeout fails at once.
+func (e *Emulator) WithQueueTimeout(timeout time.Duration) *Emulator {
+	e.QueueTimeout = timeout
+	return e
+}
+
+// WithOutput sets where the function's stdout and stderr go.
+func (e *Emulator) WithOutput(w io.Writer) *Emulator {
+	e.Output = w
+	return e
+}
+
+// Validate ...
+func (e *Emulator) Validate() error {
+	if e.ServiceName == "" {
+		return fmt.Errorf("Service name is required but not provided")
+	}
+	if e.Function == nil || fc.IsBlank(e.Function.FunctionName) {
+		return fmt.Errorf("Function name is required but not provided")
+	}
+	return nil
+}
+
+// Start launches the function process, waits for it to listen on CAPort and starts serving the endpoint.
+func (e *Emulator) Start() error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	command, err := e.command()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = e.CodeDir
+	cmd.Env = e.environ()
+	cmd.Stdout = e.Output
+	cmd.Stderr = e.Output
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to launch function: %v", err)
+	}
+	e.cmd = cmd
+	e.client = &http.Client{}
+	e.ctx, e.cancel = context.WithCancel(context.Background())
+	e.exited = make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(e.exited)
+	}()
+
+	if err := e.waitReady(); err != nil {
+		e.kill()
+		return err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		e.kill()
+		return err
+	}
+	e.listener = listener
+	e.slots = make(chan struct{}, e.instanceConcurrency())
+	e.server = &http.Server{Handler: e}
+	go e.server.Serve(listener)
+	return nil
+}
+
+// Endpoint returns the endpoint to create an fc.Client with.
+func (e *Emulator) Endpoint() string {
+	if e.listener == nil {
+		return ""
+	}
+	return "http://" + e.listener.Addr().String()
+}
+
+// Close cancels the async invocations in flight and waits for them, then calls the pre-stop hook,
+// stops the function process and the endpoint.
+func (e *Emulator) Close() error {
+	if e.cmd == nil {
+		return nil
+	}
+	e.asyncMu.Lock()
+	closed := e.closed
+	e.closed = true
+	e.asyncMu.Unlock()
+	if closed {
+		return nil
+	}
+	e.cancel()
+	e.async.Wait()
+
+	e.callLifecycle(context.Background(), http.MethodGet, pathPreStop, e.initializationTimeout())
+	e.kill()
+	if e.server != nil {
+		return e.server.Close()
+	}
+	return nil
+}
+
+func (e *Emulator) kill() {
+	e.cmd.Process.Kill()
+	<-e.exited
+}
+
+// command resolves the launch command: the override, the custom container command and args,
+// or the bootstrap file in the code directory.
+func (e *Emulator) command() ([]string, error) {
+	if len(e.Command) > 0 {
+		return e.Command, nil
+	}
+	if c := e.Function.CustomContainerConfig; c != nil && !fc.IsBlank(c.Command) {
+		var command, args []string
+		if err := json.Unmarshal([]byte(*c.Command), &command); err != nil {
+			return nil, fmt.Errorf("invalid custom container command %s: %v", *c.Command, err)
+		}
+		if !fc.IsBlank(c.Args) {
+			if err := json.Unmarshal([]byte(*c.Args), &args); err != nil {
+				return nil, fmt.Errorf("invalid custom container args %s: %v", *c.Args, err)
+			}
+		}
+		if len(command) == 0 {
+			return nil, fmt.Errorf("custom container command is empty")
+		}
+		return append(command, args...), nil
+	}
+	bootstrap, err := filepath.Abs(filepath.Join(e.CodeDir, "bootstrap"))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(bootstrap); err != nil {
+		return nil, fmt.Errorf("bootstrap not found in %s: %v", e.CodeDir, err)
+	}
+	return []string{bootstrap}, nil
+}
+
+func (e *Emulator) environ() []string {
+	env := os.Environ()
+	env = append(env,
+		"FC_SERVER_PORT="+strconv.Itoa(e.caPort()),
+		"FC_SERVICE_NAME="+e.ServiceName,
+		"FC_FUNCTION_NAME="+*e.Function.FunctionName,
+		"FC_REGION="+e.Region,
+		"FC_ACCOUNT_ID="+e.AccountID,
+		"FC_FUNC_CODE_PATH="+e.CodeDir,
+	)
+	for k, v := range e.Function.EnvironmentVariables {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+func (e *Emulator) waitReady() error {
+	address := e.functionAddress()
+	deadline := time.Now().Add(e.StartupTimeout)
+	for {
+		conn, err := net.DialTimeout("tcp", address, 100*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		select {
+		case <-e.exited:
+			return fmt.Errorf("function exited before listening on %s", address)
+		case <-time.After(50 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("function did not listen on %s within %s", address, e.StartupTimeout)
+		}
+	}
+}
+
+func (e *Emulator) functionAddress() string {
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(e.caPort()))
+}
+
+func (e *Emulator) caPort() int {
+	if e.Function.CAPort != nil && *e.Function.CAPort > 0 {
+		return int(*e.Function.CAPort)
+	}
+	return fc.DefaultCustomRuntimePort
+}
+
+func (e *Emulator) timeout() int {
+	if e.Function.Timeout != nil && *e.Function.Timeout > 0 {
+		return int(*e.Function.Timeout)
+	}
+	return DefaultTimeout
+}
+
+func (e *Emulator) initializationTimeout() int {
+	if e.Function.InitializationTimeout != nil && *e.Function.InitializationTimeout > 0 {
+		return int(*e.Function.InitializationTimeout)
+	}
+	return DefaultInitializationTimeout
+}
+
+func (e *Emulator) queueTimeout() time.Duration {
+	if e.QueueTimeout == 0 {
+		return time.Duration(e.timeout()) * time.Second
+	}
+	return e.QueueTimeout
+}
+
+func (e *Emulator) instanceConcurrency() int {
+	if e.Function.InstanceConcurrency != nil && *e.Function.InstanceConcurrency > 0 {
+		return int(*e.Function.InstanceConcurrency)
+	}
+	return DefaultInstanceConcurrency
+}
@@ -0,0 +1,206 @@
+package emulator
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+	"github.com/aliyun/fc-go-sdk/runtime"
+	"github.com/stretchr/testify/suite"
+)
+
+// TestHelperProcess is the function process launched by the emulator in tests.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("EMULATOR_HELPER_PROCESS") != "1" {
+		return
+	}
+	initialized := false
+	server := runtime.NewServer().
+		WithInitializer(func(ctx *runtime.Context) error {
+			initialized = true
+			return nil
+		}).
+		WithEventHandler(func(ctx *runtime.Context, event []byte) ([]byte, error) {
+			switch string(event) {
+			case "sleep":
+				time.Sleep(2 * time.Second)
+			case "fail":
+				return nil, errors.New("failed")
+			}
+			return []byte(fmt.Sprintf("%s %s %s %v %s", ctx.Service.Name, ctx.Function.Name, ctx.Service.Qualifier, initialized, os.Getenv("GREETING"))), nil
+		}).
+		WithHTTPHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, _ := runtime.FromContext(r.Context())
+			w.Header().Set("X-Path", r.URL.Path)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(r.Method + " " + ctx.RequestID))
+		}))
+	server.ListenAndServe()
+	os.Exit(0)
+}
+
+type EmulatorTestSuite struct {
+	suite.Suite
+	emulator *Emulator
+	client   *fc.Client
+}
+
+func TestEmulator(t *testing.T) {
+	suite.Run(t, new(EmulatorTestSuite))
+}
+
+func freePort() int32 {
+	l, _ := net.Listen("tcp", "127.0.0.1:0")
+	defer l.Close()
+	return int32(l.Addr().(*net.TCPAddr).Port)
+}
+
+func (s *EmulatorTestSuite) SetupTest() {
+	assert := s.Require()
+
+	os.Setenv("EMULATOR_HELPER_PROCESS", "1")
+	defer os.Unsetenv("EMULATOR_HELPER_PROCESS")
+	function := fc.NewCreateFunctionInput("service").
+		WithFunctionName("function").
+		WithHandler("main").
+		WithInitializer("init").
+		WithTimeout(1).
+		WithInstanceConcurrency(1).
+		WithCAPort(freePort()).
+		WithEnvironmentVariables(map[string]string{"GREETING": "hello"})
+	s.emulator = New("service", &function.FunctionCreateObject).
+		WithCommand(os.Args[0], "-test.run=TestHelperProcess").
+		WithOutput(ioutil.Discard)
+	assert.Nil(s.emulator.Start())
+	client, err := fc.NewClient(s.emulator.Endpoint(), fc.APIVersionV1, "ak", "sk")
+	assert.Nil(err)
+	s.client = client
+}
+
+func (s *EmulatorTestSuite) TearDownTest() {
+	s.emulator.Close()
+}
+
+func (s *EmulatorTestSuite) TestInvokeFunction() {
+	assert := s.Require()
+
+	output, err := s.client.InvokeFunction(fc.NewInvokeFunctionInput("service", "function").WithQualifier("prod").WithPayload([]byte("hi")))
+	assert.Nil(err)
+	assert.Equal("service function prod true hello", string(output.Payload))
+	assert.NotEmpty(output.GetRequestID())
+	assert.Empty(fc.GetErrorType(output.Header))
+
+	output, err = s.client.InvokeFunction(fc.NewInvokeFunctionInput("service", "function").WithPayload([]byte("fail")))
+	assert.Nil(err)
+	assert.Equal(ErrorTypeHandled, fc.GetErrorType(output.Header))
+	assert.Contains(string(output.Payload), `"errorMessage":"failed"`)
+
+	_, err = s.client.InvokeFunction(fc.NewInvokeFunctionInput("service", "other"))
+	assert.NotNil(err)
+	assert.Equal(ErrorCodeFunctionNotFound, err.(*fc.ServiceError).ErrorCode)
+}
+
+func (s *EmulatorTestSuite) TestTimeoutAndConcurrency() {
+	assert := s.Require()
+
+	// invokes twice, the second invocation while the first is running
+	invoke := func() ([]*fc.InvokeFunctionOutput, []error) {
+		var wg sync.WaitGroup
+		outputs := make([]*fc.InvokeFunctionOutput, 2)
+		errs := make([]error, 2)
+		for i := range outputs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if i == 1 {
+					time.Sleep(200 * time.Millisecond)
+				}
+				outputs[i], errs[i] = s.client.InvokeFunction(fc.NewInvokeFunctionInput("service", "function").WithPayload([]byte("sleep")))
+			}(i)
+		}
+		wg.Wait()
+		return outputs, errs
+	}
+
+	// the second invocation waits for the first to time out
+	outputs, errs := invoke()
+	for i := range outputs {
+		assert.Nil(errs[i])
+		assert.Equal(ErrorTypeUnhandled, fc.GetErrorType(outputs[i].Header))
+		assert.Contains(string(outputs[i].Payload), "timed out after 1 seconds")
+	}
+
+	// without queueing it fails at once
+	s.emulator.WithQueueTimeout(-1)
+	_, errs = invoke()
+	assert.Nil(errs[0])
+	assert.NotNil(errs[1])
+	assert.Equal(http.StatusTooManyRequests, errs[1].(*fc.ServiceError).HTTPStatus)
+}
+
+func (s *EmulatorTestSuite) TestCloseStopsAsyncInvocations() {
+	assert := s.Require()
+
+	_, err := s.client.InvokeFunction(fc.NewInvokeFunctionInput("service", "function").
+		WithInvocationType(invocationTypeAsync).WithPayload([]byte("sleep")))
+	assert.Nil(err)
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	assert.Nil(s.emulator.Close())
+	assert.True(time.Since(start) < time.Second, time.Since(start).String())
+	done := make(chan struct{})
+	go func() {
+		s.emulator.async.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		s.Fail("async invocation still running after Close")
+	}
+
+	// async invocations are refused once closed
+	req := httptest.NewRequest(http.MethodPost, "/2016-08-15/services/service/functions/function/invocations", strings.NewReader("hi"))
+	req.Header.Set(fc.HTTPHeaderInvocationType, invocationTypeAsync)
+	w := httptest.NewRecorder()
+	s.emulator.ServeHTTP(w, req)
+	assert.Equal(http.StatusServiceUnavailable, w.Code)
+	assert.Contains(w.Body.String(), ErrorCodeServiceUnavailable)
+}
+
+func (s *EmulatorTestSuite) TestDoHttpRequest() {
+	assert := s.Require()
+
+	req, err := http.NewRequest(http.MethodPost, s.emulator.Endpoint()+"/2016-08-15/proxy/service/function/users/1", strings.NewReader("{}"))
+	assert.Nil(err)
+	resp, err := s.client.DoHttpRequest(req)
+	assert.Nil(err)
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(http.StatusCreated, resp.StatusCode)
+	assert.Equal("/users/1", resp.Header.Get("X-Path"))
+	assert.Equal("POST "+resp.Header.Get(fc.HTTPHeaderRequestID), string(body))
+}
+
+func (s *EmulatorTestSuite) TestStartErrors() {
+	assert := s.Require()
+
+	assert.NotNil(New("", &fc.FunctionCreateObject{}).Start())
+	function := fc.NewCreateFunctionInput("service").WithFunctionName("function")
+	err := New("service", &function.FunctionCreateObject).WithCodeDir(os.TempDir()).Start()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "bootstrap not found")
+
+	function.WithCustomContainerConfig(fc.NewCustomContainerConfig().WithCommand("not json"))
+	assert.NotNil(New("service", &function.FunctionCreateObject).Start())
+}
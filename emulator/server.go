@@ -0,0 +1,331 @@
+package emulator
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+	"github.com/aliyun/fc-go-sdk/runtime"
+)
+
+// Paths of the custom runtime protocol
+const (
+	pathInvoke     = runtime.PathInvoke
+	pathInitialize = runtime.PathInitialize
+	pathPreStop    = runtime.PathPreStop
+)
+
+// Error types reported in the X-Fc-Error-Type header
+const (
+	ErrorTypeHandled   = "HandledInvocationError"
+	ErrorTypeUnhandled = "UnhandledInvocationError"
+)
+
+// Error codes returned by the endpoint
+const (
+	ErrorCodeFunctionNotFound   = "FunctionNotFound"
+	ErrorCodeResourceExhausted  = "ResourceExhausted"
+	ErrorCodeServiceUnavailable = "ServiceUnavailable"
+)
+
+const invocationTypeAsync = "Async"
+
+// ServeHTTP serves the invocation and http proxy APIs for the emulated function.
+func (e *Emulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := newRequestID()
+	w.Header().Set(fc.HTTPHeaderRequestID, requestID)
+
+	// /{apiVersion}/services/{service[.qualifier]}/functions/{function}/invocations
+	// /{apiVersion}/proxy/{service[.qualifier]}/{function}/{path}
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 5)
+	var service, function, path string
+	invocation := false
+	switch {
+	case len(parts) == 5 && parts[1] == "services" && parts[3] == "functions":
+		function = strings.TrimSuffix(parts[4], "/invocations")
+		service, invocation, path = parts[2], true, pathInvoke
+		if function == parts[4] || r.Method != http.MethodPost {
+			service = ""
+		}
+	case len(parts) >= 4 && parts[1] == "proxy":
+		service, function, path = parts[2], parts[3], "/"
+		if len(parts) == 5 {
+			path += parts[4]
+		}
+	}
+	qualifier := ""
+	if i := strings.Index(service, "."); i >= 0 {
+		service, qualifier = service[:i], service[i+1:]
+	}
+	if service != e.ServiceName || function != *e.Function.FunctionName {
+		writeServiceError(w, requestID, http.StatusNotFound, ErrorCodeFunctionNotFound,
+			fmt.Sprintf("function not found: %s", r.URL.Path))
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeServiceError(w, requestID, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+	forward := &invocationRequest{
+		requestID:  requestID,
+		qualifier:  qualifier,
+		method:     r.Method,
+		path:       path,
+		query:      r.URL.RawQuery,
+		header:     r.Header,
+		body:       body,
+		invocation: invocation,
+	}
+
+	if invocation && r.Header.Get(fc.HTTPHeaderInvocationType) == invocationTypeAsync {
+		if !e.startAsync(forward) {
+			writeServiceError(w, requestID, http.StatusServiceUnavailable, ErrorCodeServiceUnavailable, "emulator is closed")
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if !e.acquireSlot(r.Context()) {
+		writeServiceError(w, requestID, http.StatusTooManyRequests, ErrorCodeResourceExhausted,
+			fmt.Sprintf("instance concurrency %d exceeded", e.instanceConcurrency()))
+		return
+	}
+	defer func() { <-e.slots }()
+	e.invoke(r.Context(), w, forward)
+}
+
+// acquireSlot waits up to the queue timeout for an instance slot, reporting false if none frees up.
+func (e *Emulator) acquireSlot(ctx context.Context) bool {
+	select {
+	case e.slots <- struct{}{}:
+		return true
+	default:
+	}
+	timeout := e.queueTimeout()
+	if timeout < 0 {
+		return false
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case e.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	case <-e.ctx.Done():
+	}
+	return false
+}
+
+// startAsync runs the invocation in the background until it completes or the emulator is closed.
+// It reports false if the emulator is already closed.
+func (e *Emulator) startAsync(in *invocationRequest) bool {
+	e.asyncMu.Lock()
+	defer e.asyncMu.Unlock()
+	if e.closed {
+		return false
+	}
+	e.async.Add(1)
+	go func() {
+		defer e.async.Done()
+		select {
+		case e.slots <- struct{}{}:
+			defer func() { <-e.slots }()
+		case <-e.ctx.Done():
+			return
+		}
+		e.invoke(e.ctx, &discardResponseWriter{header: http.Header{}}, in)
+	}()
+	return true
+}
+
+type invocationRequest struct {
+	requestID  string
+	qualifier  string
+	method     string
+	path       string
+	query      string
+	header     http.Header
+	body       []byte
+	invocation bool
+}
+
+// invoke runs the initializer if needed and forwards the request to the function within its timeout.
+func (e *Emulator) invoke(ctx context.Context, w http.ResponseWriter, in *invocationRequest) {
+	if err := e.initialize(ctx, in); err != nil {
+		writeFunctionError(w, in, err.Error())
+		return
+	}
+
+	timeout := e.timeout()
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+	url := "http://" + e.functionAddress() + in.path
+	if in.query != "" {
+		url += "?" + in.query
+	}
+	req, err := http.NewRequestWithContext(ctx, in.method, url, bytes.NewReader(in.body))
+	if err != nil {
+		writeFunctionError(w, in, err.Error())
+		return
+	}
+	if !in.invocation {
+		for k, v := range in.header {
+			req.Header[k] = v
+		}
+	}
+	e.setHeaders(req.Header, in)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			writeFunctionError(w, in, fmt.Sprintf("Function timed out after %d seconds", timeout))
+			return
+		}
+		writeFunctionError(w, in, err.Error())
+		return
+	}
+	defer resp.Body.Close()
+	payload, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		writeFunctionError(w, in, err.Error())
+		return
+	}
+
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	status := resp.StatusCode
+	if resp.Header.Get(runtime.HeaderStatus) == "404" {
+		w.Header().Del(runtime.HeaderStatus)
+		w.Header().Set(fc.HTTPHeaderFCErrorType, ErrorTypeUnhandled)
+		if in.invocation {
+			status = http.StatusOK
+		}
+	} else if in.invocation && status >= http.StatusInternalServerError {
+		// an error returned by the handler
+		w.Header().Set(fc.HTTPHeaderFCErrorType, ErrorTypeHandled)
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(payload)
+}
+
+// initialize calls the initializer once; a failed initialization is retried on the next invocation.
+func (e *Emulator) initialize(ctx context.Context, in *invocationRequest) error {
+	if fc.IsBlank(e.Function.Initializer) {
+		return nil
+	}
+	e.initMu.Lock()
+	defer e.initMu.Unlock()
+	if e.initialized {
+		return nil
+	}
+	if err := e.callLifecycle(ctx, http.MethodPost, pathInitialize, e.initializationTimeout()); err != nil {
+		return fmt.Errorf("initialization failed: %v", err)
+	}
+	e.initialized = true
+	return nil
+}
+
+// callLifecycle calls a lifecycle hook of the function.
+func (e *Emulator) callLifecycle(ctx context.Context, method, path string, timeout int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, "http://"+e.functionAddress()+path, nil)
+	if err != nil {
+		return err
+	}
+	e.setHeaders(req.Header, &invocationRequest{requestID: newRequestID()})
+	resp, err := e.client.Do(req)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("timed out after %d seconds", timeout)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.Header.Get(runtime.HeaderStatus) == "404" || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %d: %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (e *Emulator) setHeaders(header http.Header, in *invocationRequest) {
+	f := e.Function
+	header.Set(runtime.HeaderRequestID, in.requestID)
+	header.Set(runtime.HeaderAccessKeyID, e.Credentials.AccessKeyID)
+	header.Set(runtime.HeaderAccessKeySecret, e.Credentials.AccessKeySecret)
+	header.Set(runtime.HeaderSecurityToken, e.Credentials.SecurityToken)
+	header.Set(runtime.HeaderFunctionName, *f.FunctionName)
+	header.Set(runtime.HeaderFunctionTimeout, strconv.Itoa(e.timeout()))
+	header.Set(runtime.HeaderInitializationTimeout, strconv.Itoa(e.initializationTimeout()))
+	header.Set(runtime.HeaderServiceName, e.ServiceName)
+	header.Set(runtime.HeaderRegion, e.Region)
+	header.Set(runtime.HeaderAccountID, e.AccountID)
+	if in.qualifier != "" {
+		header.Set(runtime.HeaderQualifier, in.qualifier)
+	} else {
+		header.Set(runtime.HeaderQualifier, "LATEST")
+	}
+	if f.Handler != nil {
+		header.Set(runtime.HeaderFunctionHandler, *f.Handler)
+	}
+	if f.Initializer != nil {
+		header.Set(runtime.HeaderFunctionInitializer, *f.Initializer)
+	}
+	if f.MemorySize != nil {
+		header.Set(runtime.HeaderFunctionMemory, strconv.Itoa(int(*f.MemorySize)))
+	}
+}
+
+func writeFunctionError(w http.ResponseWriter, in *invocationRequest, message string) {
+	w.Header().Set(fc.HTTPHeaderFCErrorType, ErrorTypeUnhandled)
+	w.Header().Set(fc.HTTPHeaderContentType, "application/json")
+	if in.invocation {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	json.NewEncoder(w).Encode(&runtime.ErrorResponse{ErrorMessage: message, ErrorType: ErrorTypeUnhandled})
+}
+
+func writeServiceError(w http.ResponseWriter, requestID string, status int, code, message string) {
+	w.Header().Set(fc.HTTPHeaderContentType, "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&fc.ServiceError{
+		HTTPStatus:   status,
+		RequestID:    requestID,
+		ErrorCode:    code,
+		ErrorMessage: message,
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	s := hex.EncodeToString(b)
+	return s[:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// discardResponseWriter drops the response of an async invocation.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d *discardResponseWriter) Header() http.Header         { return d.header }
+func (d *discardResponseWriter) Write(b []byte) (int, error) { return ioutil.Discard.Write(b) }
+func (d *discardResponseWriter) WriteHeader(int)             {}
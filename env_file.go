@@ -0,0 +1,268 @@
+package fc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LoadEnvFile reads environment variables from a JSON (.json) or YAML (.yaml, .yml) file, or
+// otherwise a .env file. Values are templates expanded by Environment, where "$$" is a literal "$".
+func LoadEnvFile(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var env map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		env, err = ParseEnvJSON(data)
+	case ".yaml", ".yml":
+		env, err = ParseEnvYAML(data)
+	default:
+		env, err = ParseDotEnv(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %v", path, err)
+	}
+	return env, nil
+}
+
+// ParseDotEnv parses NAME=value lines, optionally prefixed by "export". Blank lines and lines
+// starting with # are skipped. Unquoted values end at a " #" comment; double-quoted values
+// support \n, \t, \", \\ and \$ escapes; single-quoted values are literal and not expanded.
+func ParseDotEnv(data []byte) (map[string]string, error) {
+	env := map[string]string{}
+	err := scanEnvLines(data, true, func(n int, line string) error {
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			line = strings.TrimSpace(line[len("export"):])
+		}
+		i := strings.IndexByte(line, '=')
+		if i < 0 {
+			return fmt.Errorf("line %d: expected NAME=value", n)
+		}
+		name := strings.TrimSpace(line[:i])
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("line %d: invalid environment variable name %q", n, name)
+		}
+		value, err := parseDotEnvValue(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+		env[name] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+func parseDotEnvValue(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	switch s[0] {
+	case '\'':
+		end := strings.IndexByte(s[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single-quoted value")
+		}
+		if err := checkTrailingComment(s[end+2:]); err != nil {
+			return "", err
+		}
+		return strings.ReplaceAll(s[1:end+1], "$", "$$"), nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(s); i++ {
+			c := s[i]
+			if c == '"' {
+				if err := checkTrailingComment(s[i+1:]); err != nil {
+					return "", err
+				}
+				return b.String(), nil
+			}
+			if c != '\\' || i+1 == len(s) {
+				b.WriteByte(c)
+				continue
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '$':
+				b.WriteString("$$")
+			case '"', '\\':
+				b.WriteByte(s[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(s[i])
+			}
+		}
+		return "", fmt.Errorf("unterminated double-quoted value")
+	}
+	return stripInlineComment(s), nil
+}
+
+// ParseEnvJSON parses a JSON object of environment variables. Numbers and booleans are converted
+// to their text and null to an empty value.
+func ParseEnvJSON(data []byte) (map[string]string, error) {
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	env := make(map[string]string, len(raw))
+	for name, v := range raw {
+		switch v := v.(type) {
+		case string:
+			env[name] = v
+		case json.Number:
+			env[name] = v.String()
+		case bool:
+			env[name] = strconv.FormatBool(v)
+		case nil:
+			env[name] = ""
+		default:
+			return nil, fmt.Errorf("value of %s must be a string, number or boolean", name)
+		}
+	}
+	return env, nil
+}
+
+// ParseEnvYAML parses a flat YAML mapping of environment variables. Only the subset used for such
+// maps is supported: one "NAME: value" per line, with plain, single- or double-quoted scalars and
+// # comments. Nested mappings, lists, multi-line and block scalars are rejected. ~ and null are
+// empty values.
+func ParseEnvYAML(data []byte) (map[string]string, error) {
+	env := map[string]string{}
+	err := scanEnvLines(data, false, func(n int, line string) error {
+		if line == "---" || line == "..." {
+			return nil
+		}
+		if strings.HasPrefix(line, "- ") || line == "-" {
+			return fmt.Errorf("line %d: lists are not supported", n)
+		}
+		var name, value string
+		if i := strings.Index(line, ": "); i >= 0 {
+			name, value = line[:i], strings.TrimSpace(line[i+2:])
+		} else if strings.HasSuffix(line, ":") {
+			name = line[:len(line)-1]
+		} else {
+			return fmt.Errorf("line %d: expected NAME: value", n)
+		}
+		name = strings.TrimSpace(name)
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("line %d: invalid environment variable name %q", n, name)
+		}
+		v, err := parseYAMLScalar(value)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+		env[name] = v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+func parseYAMLScalar(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	switch s[0] {
+	case '\'':
+		for i := 1; i < len(s); i++ {
+			if s[i] != '\'' {
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			if err := checkTrailingComment(s[i+1:]); err != nil {
+				return "", err
+			}
+			return strings.ReplaceAll(s[1:i], "''", "'"), nil
+		}
+		return "", fmt.Errorf("unterminated single-quoted value")
+	case '"':
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] != '"' {
+				continue
+			}
+			if err := checkTrailingComment(s[i+1:]); err != nil {
+				return "", err
+			}
+			v, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", fmt.Errorf("invalid double-quoted value %s", s[:i+1])
+			}
+			return v, nil
+		}
+		return "", fmt.Errorf("unterminated double-quoted value")
+	case '|', '>':
+		return "", fmt.Errorf("block scalars are not supported")
+	case '[', '{':
+		return "", fmt.Errorf("nested values are not supported")
+	}
+	v := stripInlineComment(s)
+	if v == "~" || v == "null" {
+		return "", nil
+	}
+	return v, nil
+}
+
+// scanEnvLines calls fn with the number and trimmed content of each line that is not blank or a
+// comment. Unless allowIndent, indented lines are rejected, as in YAML they nest a mapping.
+func scanEnvLines(data []byte, allowIndent bool, fn func(n int, line string) error) error {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		raw := strings.TrimRight(scanner.Text(), " \t\r")
+		if n == 1 {
+			raw = strings.TrimPrefix(raw, "\ufeff")
+		}
+		line := strings.TrimSpace(raw)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if !allowIndent && (raw[0] == ' ' || raw[0] == '\t') {
+			return fmt.Errorf("line %d: indented lines are not supported", n)
+		}
+		if err := fn(n, line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func stripInlineComment(s string) string {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '#' && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return strings.TrimSpace(s[:i])
+		}
+	}
+	return s
+}
+
+func checkTrailingComment(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && rest[0] != '#' {
+		return fmt.Errorf("unexpected %q after quoted value", rest)
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package fc
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MaxEnvironmentVariablesSize is the largest total size of the names and values of the
+// environment variables of a function.
+const MaxEnvironmentVariablesSize = 4 << 10
+
+// ReservedEnvPrefix starts the names of the environment variables set by Function Compute.
+const ReservedEnvPrefix = "FC_"
+
+// RedactedValue replaces secret values in the String output of functions.
+const RedactedValue = "******"
+
+// MinRedactedSecretLength is the shortest secret redacted where it appears inside a value rather
+// than only as a whole value.
+const MinRedactedSecretLength = 8
+
+// reservedEnvNames are the credentials Function Compute sets in the instance.
+var reservedEnvNames = map[string]bool{
+	"ALIBABA_CLOUD_ACCESS_KEY_ID":     true,
+	"ALIBABA_CLOUD_ACCESS_KEY_SECRET": true,
+	"ALIBABA_CLOUD_SECURITY_TOKEN":    true,
+}
+
+var (
+	envNamePattern      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	sensitiveEnvPattern = regexp.MustCompile(`(?i)(SECRET|PASSWORD|PASSWD|TOKEN|PRIVATE_KEY|ACCESS_KEY|API_KEY|CREDENTIAL)`)
+)
+
+// ValidateEnvironmentVariables checks the names are valid and not reserved, and the variables fit
+// in MaxEnvironmentVariablesSize.
+func ValidateEnvironmentVariables(env map[string]string) error {
+	size := 0
+	for _, name := range sortedEnvNames(env) {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid environment variable name %q", name)
+		}
+		if strings.HasPrefix(strings.ToUpper(name), ReservedEnvPrefix) || reservedEnvNames[name] {
+			return fmt.Errorf("environment variable name %s is reserved", name)
+		}
+		size += len(name) + len(env[name])
+	}
+	if size > MaxEnvironmentVariablesSize {
+		return fmt.Errorf("environment variables are %d bytes, more than the limit of %d bytes", size, MaxEnvironmentVariablesSize)
+	}
+	return nil
+}
+
+// SecretResolver returns the value of a secret referenced as ${secret:name}, e.g. from KMS or a
+// vault.
+type SecretResolver interface {
+	ResolveSecret(ctx context.Context, name string) (string, error)
+}
+
+// SecretResolverFunc adapts a function to a SecretResolver.
+type SecretResolverFunc func(ctx context.Context, name string) (string, error)
+
+// ResolveSecret ...
+func (f SecretResolverFunc) ResolveSecret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+type envSource struct {
+	path string
+	vars map[string]string
+}
+
+// Environment builds the environment variables of a function from files and maps, later sources
+// overriding earlier ones, and expands the values:
+//
+//	$NAME, ${NAME}     the variable NAME, or the lookup value if it is not defined or is the
+//	                   variable being expanded
+//	${NAME:-default}   default if NAME is undefined or empty
+//	${secret:name}     the secret from the SecretResolver
+//	$$                 a literal $
+//
+//	env, err := fc.NewEnvironment().
+//		WithFile(".env").
+//		WithFile(".env.production").
+//		WithSecretResolver(resolver).
+//		Resolve(ctx)
+type Environment struct {
+	sources []envSource
+	lookup  func(string) (string, bool)
+	secrets SecretResolver
+
+	secretValues []string
+}
+
+func NewEnvironment() *Environment {
+	return &Environment{}
+}
+
+// WithFile adds the variables of a file read by LoadEnvFile when resolving.
+func (e *Environment) WithFile(path string) *Environment {
+	e.sources = append(e.sources, envSource{path: path})
+	return e
+}
+
+// WithVars adds variables whose values are expanded.
+func (e *Environment) WithVars(vars map[string]string) *Environment {
+	e.sources = append(e.sources, envSource{vars: vars})
+	return e
+}
+
+// WithVar adds a variable whose value is expanded.
+func (e *Environment) WithVar(name, value string) *Environment {
+	return e.WithVars(map[string]string{name: value})
+}
+
+// WithLookup resolves references to variables not defined in the environment, e.g. with
+// os.LookupEnv. Looked up values are not expanded or added to the environment.
+func (e *Environment) WithLookup(lookup func(string) (string, bool)) *Environment {
+	e.lookup = lookup
+	return e
+}
+
+func (e *Environment) WithSecretResolver(resolver SecretResolver) *Environment {
+	e.secrets = resolver
+	return e
+}
+
+// Resolve loads the sources, expands the values and validates the result with
+// ValidateEnvironmentVariables. Resolved secret values are kept to be redacted by Redact.
+func (e *Environment) Resolve(ctx context.Context) (map[string]string, error) {
+	e.secretValues = nil
+	templates := map[string]string{}
+	for _, s := range e.sources {
+		vars := s.vars
+		if s.path != "" {
+			var err error
+			if vars, err = LoadEnvFile(s.path); err != nil {
+				return nil, err
+			}
+		}
+		for name, value := range vars {
+			templates[name] = value
+		}
+	}
+	r := &envExpander{
+		ctx:       ctx,
+		env:       e,
+		templates: templates,
+		resolved:  map[string]string{},
+		expanding: map[string]bool{},
+		secrets:   map[string]string{},
+	}
+	for _, name := range sortedEnvNames(templates) {
+		if _, _, err := r.variable(name); err != nil {
+			return nil, fmt.Errorf("failed to expand environment variable %s: %v", name, err)
+		}
+	}
+	if err := ValidateEnvironmentVariables(r.resolved); err != nil {
+		return nil, err
+	}
+	for _, v := range r.secrets {
+		e.secretValues = append(e.secretValues, v)
+	}
+	return r.resolved, nil
+}
+
+type envExpander struct {
+	ctx       context.Context
+	env       *Environment
+	templates map[string]string
+	resolved  map[string]string
+	expanding map[string]bool
+	stack     []string
+	secrets   map[string]string
+}
+
+func (r *envExpander) variable(name string) (string, bool, error) {
+	if v, ok := r.resolved[name]; ok {
+		return v, true, nil
+	}
+	t, ok := r.templates[name]
+	// a variable referring to itself, e.g. PATH=$PATH:/opt/bin, refers to the lookup value
+	if !ok || len(r.stack) > 0 && r.stack[len(r.stack)-1] == name {
+		if r.env.lookup != nil {
+			v, ok := r.env.lookup(name)
+			return v, ok, nil
+		}
+		return "", false, nil
+	}
+	if r.expanding[name] {
+		return "", false, fmt.Errorf("%s refers to itself through %s", name, strings.Join(r.stack, ", "))
+	}
+	r.expanding[name] = true
+	r.stack = append(r.stack, name)
+	v, err := r.expand(t)
+	r.stack = r.stack[:len(r.stack)-1]
+	delete(r.expanding, name)
+	if err != nil {
+		return "", false, err
+	}
+	r.resolved[name] = v
+	return v, true, nil
+}
+
+func (r *envExpander) expand(t string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(t); {
+		if t[i] != '$' || i+1 == len(t) {
+			b.WriteByte(t[i])
+			i++
+			continue
+		}
+		var ref string
+		switch next := t[i+1]; {
+		case next == '$':
+			b.WriteByte('$')
+			i += 2
+			continue
+		case next == '{':
+			end := strings.IndexByte(t[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated reference %s", t[i:])
+			}
+			ref = t[i+2 : i+2+end]
+			i += end + 3
+		case isEnvNameByte(next, true):
+			j := i + 2
+			for j < len(t) && isEnvNameByte(t[j], false) {
+				j++
+			}
+			ref = t[i+1 : j]
+			i = j
+		default:
+			b.WriteByte('$')
+			i++
+			continue
+		}
+		v, err := r.reference(ref)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(v)
+	}
+	return b.String(), nil
+}
+
+func (r *envExpander) reference(ref string) (string, error) {
+	if strings.HasPrefix(ref, "secret:") {
+		return r.secret(strings.TrimPrefix(ref, "secret:"))
+	}
+	name, def, hasDefault := ref, "", false
+	if i := strings.Index(ref, ":-"); i >= 0 {
+		name, def, hasDefault = ref[:i], ref[i+2:], true
+	}
+	if !envNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid reference ${%s}", ref)
+	}
+	v, ok, err := r.variable(name)
+	if err != nil {
+		return "", err
+	}
+	if hasDefault && v == "" {
+		return def, nil
+	}
+	if !ok {
+		return "", fmt.Errorf("variable %s is not defined", name)
+	}
+	return v, nil
+}
+
+func (r *envExpander) secret(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("secret name is empty")
+	}
+	if v, ok := r.secrets[name]; ok {
+		return v, nil
+	}
+	if r.env.secrets == nil {
+		return "", fmt.Errorf("secret %s is referenced but no SecretResolver is provided", name)
+	}
+	v, err := r.env.secrets.ResolveSecret(r.ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %v", name, err)
+	}
+	r.secrets[name] = v
+	return v, nil
+}
+
+func isEnvNameByte(c byte, first bool) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || !first && '0' <= c && c <= '9'
+}
+
+func sortedEnvNames(env map[string]string) []string {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RedactEnvironmentVariables returns a copy of env with the values of variables with sensitive
+// names, e.g. DB_PASSWORD, and values equal to one of the secrets replaced by RedactedValue.
+// Secrets of at least MinRedactedSecretLength bytes are also replaced where they appear in a value;
+// shorter ones would redact unrelated text.
+func RedactEnvironmentVariables(env map[string]string, secrets ...string) map[string]string {
+	if env == nil {
+		return nil
+	}
+	whole := make(map[string]bool, len(secrets))
+	var long []string
+	for _, s := range secrets {
+		if s == "" {
+			continue
+		}
+		whole[s] = true
+		if len(s) >= MinRedactedSecretLength {
+			long = append(long, s)
+		}
+	}
+	// longest first, so a secret containing another is replaced whole
+	sort.Slice(long, func(i, j int) bool { return len(long[i]) > len(long[j]) })
+	pairs := make([]string, 0, 2*len(long))
+	for _, s := range long {
+		pairs = append(pairs, s, RedactedValue)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	redacted := make(map[string]string, len(env))
+	for name, value := range env {
+		if value != "" && sensitiveEnvPattern.MatchString(name) || whole[value] {
+			redacted[name] = RedactedValue
+			continue
+		}
+		redacted[name] = replacer.Replace(value)
+	}
+	return redacted
+}
+
+// Redact redacts env with RedactEnvironmentVariables and the secrets of the last Resolve.
+func (e *Environment) Redact(env map[string]string) map[string]string {
+	return RedactEnvironmentVariables(env, e.secretValues...)
+}
+
+// WithEnvironment resolves the environment and uses its variables. Resolution errors are returned
+// by Validate. The resolved secrets are redacted from the String output of the function created
+// with the input.
+func (i *CreateFunctionInput) WithEnvironment(ctx context.Context, env *Environment) *CreateFunctionInput {
+	vars, err := env.Resolve(ctx)
+	if err != nil {
+		i.err = err
+		return i
+	}
+	i.EnvironmentVariables = vars
+	i.secrets = env.secretValues
+	return i
+}
+
+// WithEnvironment resolves the environment and uses its variables like
+// CreateFunctionInput.WithEnvironment.
+func (i *UpdateFunctionInput) WithEnvironment(ctx context.Context, env *Environment) *UpdateFunctionInput {
+	vars, err := env.Resolve(ctx)
+	if err != nil {
+		i.err = err
+		return i
+	}
+	i.EnvironmentVariables = vars
+	i.secrets = env.secretValues
+	return i
+}
+
+// WithRedaction redacts the secrets resolved by env from the String output, in addition to the
+// variables with sensitive names.
+func (o *CreateFunctionOutput) WithRedaction(env *Environment) *CreateFunctionOutput {
+	o.secrets = append(o.secrets, env.secretValues...)
+	return o
+}
+
+// WithRedaction redacts the secrets resolved by env from the String output, in addition to the
+// variables with sensitive names.
+func (o *UpdateFunctionOutput) WithRedaction(env *Environment) *UpdateFunctionOutput {
+	o.secrets = append(o.secrets, env.secretValues...)
+	return o
+}
+
+// WithRedaction redacts the secrets resolved by env from the String output, in addition to the
+// variables with sensitive names.
+func (o *GetFunctionOutput) WithRedaction(env *Environment) *GetFunctionOutput {
+	o.secrets = append(o.secrets, env.secretValues...)
+	return o
+}
@@ -0,0 +1,341 @@
+package fc
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EnvironmentTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestEnvironment(t *testing.T) {
+	suite.Run(t, new(EnvironmentTestSuite))
+}
+
+func (s *EnvironmentTestSuite) SetupTest() {
+	dir, err := ioutil.TempDir("", "fc_env_")
+	s.Require().Nil(err)
+	s.dir = dir
+}
+
+func (s *EnvironmentTestSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+}
+
+func (s *EnvironmentTestSuite) writeFile(name, content string) string {
+	path := filepath.Join(s.dir, name)
+	s.Require().Nil(ioutil.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func (s *EnvironmentTestSuite) TestParseDotEnv() {
+	assert := s.Require()
+
+	env, err := ParseDotEnv([]byte(`
+# comment
+PLAIN=hello world # trailing comment
+export EXPORTED=1
+  INDENTED = spaced
+EMPTY=
+HASH=a#b
+SINGLE='literal $HOME # not a comment'
+DOUBLE="line\nnext \"quoted\" \$5" # comment
+`))
+	assert.Nil(err)
+	assert.Equal(map[string]string{
+		"PLAIN":    "hello world",
+		"EXPORTED": "1",
+		"INDENTED": "spaced",
+		"EMPTY":    "",
+		"HASH":     "a#b",
+		"SINGLE":   "literal $$HOME # not a comment",
+		"DOUBLE":   "line\nnext \"quoted\" $$5",
+	}, env)
+
+	for _, data := range []string{"NOEQUALS", "1BAD=x", "A='open", `A="open`, "A='x' y"} {
+		_, err := ParseDotEnv([]byte(data))
+		assert.NotNil(err, data)
+		assert.Contains(err.Error(), "line 1", data)
+	}
+}
+
+func (s *EnvironmentTestSuite) TestParseEnvJSON() {
+	assert := s.Require()
+
+	env, err := ParseEnvJSON([]byte(`{"A": "x", "PORT": 8080, "RATIO": 0.5, "DEBUG": true, "NONE": null}`))
+	assert.Nil(err)
+	assert.Equal(map[string]string{"A": "x", "PORT": "8080", "RATIO": "0.5", "DEBUG": "true", "NONE": ""}, env)
+
+	_, err = ParseEnvJSON([]byte(`{"A": {"B": "x"}}`))
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "value of A must be a string, number or boolean")
+	_, err = ParseEnvJSON([]byte(`["A"]`))
+	assert.NotNil(err)
+}
+
+func (s *EnvironmentTestSuite) TestParseEnvYAML() {
+	assert := s.Require()
+
+	env, err := ParseEnvYAML([]byte(`---
+# comment
+PLAIN: hello world # comment
+URL: http://host:80/a#b
+SINGLE: 'it''s # here'
+DOUBLE: "tab\there"
+PORT: 8080
+EMPTY:
+NULL_VALUE: ~
+`))
+	assert.Nil(err)
+	assert.Equal(map[string]string{
+		"PLAIN":      "hello world",
+		"URL":        "http://host:80/a#b",
+		"SINGLE":     "it's # here",
+		"DOUBLE":     "tab\there",
+		"PORT":       "8080",
+		"EMPTY":      "",
+		"NULL_VALUE": "",
+	}, env)
+
+	for data, msg := range map[string]string{
+		"A:\n  B: x":  "line 2: indented lines are not supported",
+		"- A":         "line 1: lists are not supported",
+		"A: |\n":      "block scalars are not supported",
+		"A: [1, 2]":   "nested values are not supported",
+		"A=x":         "expected NAME: value",
+		"A: 'x' y":    "after quoted value",
+		"A-B: x":      "invalid environment variable name",
+		`A: "\q"`:     "invalid double-quoted value",
+		`A: "open`:    "unterminated double-quoted value",
+		"A: 'open''s": "unterminated single-quoted value",
+	} {
+		_, err := ParseEnvYAML([]byte(data))
+		assert.NotNil(err, data)
+		assert.Contains(err.Error(), msg, data)
+	}
+}
+
+func (s *EnvironmentTestSuite) TestLoadEnvFile() {
+	assert := s.Require()
+
+	for name, content := range map[string]string{
+		".env":       "A=x",
+		"env.json":   `{"A": "x"}`,
+		"env.YAML":   "A: x",
+		"env.yml":    "A: x",
+		"production": "A=x",
+	} {
+		env, err := LoadEnvFile(s.writeFile(name, content))
+		assert.Nil(err, name)
+		assert.Equal(map[string]string{"A": "x"}, env, name)
+	}
+
+	_, err := LoadEnvFile(s.writeFile("bad.json", "{"))
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "failed to load")
+	_, err = LoadEnvFile(filepath.Join(s.dir, "missing.env"))
+	assert.NotNil(err)
+}
+
+func (s *EnvironmentTestSuite) TestResolve() {
+	assert := s.Require()
+
+	base := s.writeFile(".env", "HOST=db\nPORT=5432\nURL=postgres://${HOST}:$PORT/app\nPRICE='$5'\n")
+	override := s.writeFile("prod.yaml", "HOST: prod-db\nREGION: ${REGION:-cn-hangzhou}\n")
+	lookup := func(name string) (string, bool) {
+		switch name {
+		case "USER":
+			return "$HOME", true
+		case "PATH":
+			return "/usr/bin", true
+		}
+		return "", false
+	}
+
+	env, err := NewEnvironment().
+		WithFile(base).
+		WithFile(override).
+		WithVar("OWNER", "${USER}").
+		WithVar("COST", "$$10 or $").
+		WithVar("PATH", "$PATH:/opt/bin").
+		WithLookup(lookup).
+		Resolve(context.Background())
+	assert.Nil(err)
+	assert.Equal(map[string]string{
+		"HOST":   "prod-db",
+		"PORT":   "5432",
+		"URL":    "postgres://prod-db:5432/app",
+		"PRICE":  "$5",
+		"REGION": "cn-hangzhou",
+		"OWNER":  "$HOME",
+		"COST":   "$10 or $",
+		"PATH":   "/usr/bin:/opt/bin",
+	}, env)
+
+	for vars, msg := range map[string]string{
+		"${MISSING}":       "variable MISSING is not defined",
+		"${A":              "unterminated reference",
+		"${1A}":            "invalid reference ${1A}",
+		"${secret:}":       "secret name is empty",
+		"${secret:db-pwd}": "no SecretResolver is provided",
+	} {
+		_, err := NewEnvironment().WithVar("A", vars).Resolve(context.Background())
+		assert.NotNil(err, vars)
+		assert.Contains(err.Error(), "failed to expand environment variable A", vars)
+		assert.Contains(err.Error(), msg, vars)
+	}
+
+	_, err = NewEnvironment().WithVars(map[string]string{"A": "$B", "B": "${A}"}).Resolve(context.Background())
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "failed to expand environment variable A: A refers to itself through A, B")
+}
+
+func (s *EnvironmentTestSuite) TestSecrets() {
+	assert := s.Require()
+
+	calls := 0
+	resolver := SecretResolverFunc(func(ctx context.Context, name string) (string, error) {
+		calls++
+		if name == "db-password" {
+			return "s3cr3t-env-test", nil
+		}
+		return "", fmt.Errorf("secret not found")
+	})
+	env, err := NewEnvironment().
+		WithVar("DB_PASS", "${secret:db-password}").
+		WithVar("DSN", "user:${secret:db-password}@db").
+		WithSecretResolver(resolver).
+		Resolve(context.Background())
+	assert.Nil(err)
+	assert.Equal(map[string]string{"DB_PASS": "s3cr3t-env-test", "DSN": "user:s3cr3t-env-test@db"}, env)
+	assert.Equal(1, calls)
+
+	_, err = NewEnvironment().WithVar("A", "${secret:other}").WithSecretResolver(resolver).Resolve(context.Background())
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "failed to resolve secret other: secret not found")
+
+	// the secrets resolved by an environment are redacted by it only
+	environment := NewEnvironment().WithVar("DSN", "user:${secret:db-password}@db").WithSecretResolver(resolver)
+	_, err = environment.Resolve(context.Background())
+	assert.Nil(err)
+	redacted := environment.Redact(map[string]string{"DSN": "user:s3cr3t-env-test@db", "DEBUG": "true"})
+	assert.Equal(map[string]string{"DSN": "user:******@db", "DEBUG": "true"}, redacted)
+	vars := map[string]string{"DSN": "user:s3cr3t-env-test@db"}
+	assert.Equal(vars, NewEnvironment().Redact(vars))
+}
+
+func (s *EnvironmentTestSuite) TestRedactEnvironmentVariables() {
+	assert := s.Require()
+
+	assert.Nil(RedactEnvironmentVariables(nil))
+	assert.Equal(map[string]string{
+		"API_TOKEN":     RedactedValue,
+		"db_password":   RedactedValue,
+		"SECRET_EMPTY":  "",
+		"A":             "x ****** y ******",
+		"SHORT":         RedactedValue,
+		"CONTAINS":      "yes, no",
+		"NOT_SENSITIVE": "value",
+	}, RedactEnvironmentVariables(map[string]string{
+		"API_TOKEN":     "plain",
+		"db_password":   "plain",
+		"SECRET_EMPTY":  "",
+		"A":             "x abc-redact-test-longer y abc-redact-test",
+		"SHORT":         "no",
+		"CONTAINS":      "yes, no",
+		"NOT_SENSITIVE": "value",
+	}, "abc-redact-test", "abc-redact-test-longer", "no", ""))
+
+	create := CreateFunctionOutput{}
+	create.EnvironmentVariables = map[string]string{"ACCESS_KEY_ID": "LTAIxxxx"}
+	assert.False(strings.Contains(create.String(), "LTAIxxxx"))
+	update := UpdateFunctionOutput{secrets: []string{"abc-redact-test"}}
+	update.EnvironmentVariables = map[string]string{"A": "abc-redact-test"}
+	assert.False(strings.Contains(update.String(), "abc-redact-test"))
+	get := GetFunctionOutput{}
+	get.EnvironmentVariables = map[string]string{"A": "abc-redact-test"}
+	assert.Contains(get.String(), "abc-redact-test")
+}
+
+func (s *EnvironmentTestSuite) TestValidateEnvironmentVariables() {
+	assert := s.Require()
+
+	assert.Nil(ValidateEnvironmentVariables(nil))
+	assert.Nil(ValidateEnvironmentVariables(map[string]string{"A_1": "x", "_B": ""}))
+	for name, msg := range map[string]string{
+		"FC_REGION":                       "environment variable name FC_REGION is reserved",
+		"fc_custom":                       "is reserved",
+		"ALIBABA_CLOUD_ACCESS_KEY_SECRET": "is reserved",
+		"1A":                              "invalid environment variable name",
+		"A-B":                             "invalid environment variable name",
+	} {
+		err := ValidateEnvironmentVariables(map[string]string{name: "x"})
+		assert.NotNil(err, name)
+		assert.Contains(err.Error(), msg, name)
+	}
+
+	err := ValidateEnvironmentVariables(map[string]string{"A": strings.Repeat("x", MaxEnvironmentVariablesSize)})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "more than the limit of 4096 bytes")
+
+	_, err = NewEnvironment().WithVar("FC_X", "1").Resolve(context.Background())
+	assert.NotNil(err)
+}
+
+func (s *EnvironmentTestSuite) TestWithEnvironment() {
+	assert := s.Require()
+
+	input := NewCreateFunctionInput("svc").WithEnvironment(context.Background(), NewEnvironment().WithVar("A", "1"))
+	assert.Nil(input.Validate())
+	assert.Equal(map[string]string{"A": "1"}, input.EnvironmentVariables)
+
+	input = NewCreateFunctionInput("svc").WithEnvironment(context.Background(), NewEnvironment().WithVar("A", "$B"))
+	assert.NotNil(input.Validate())
+	assert.Nil(input.EnvironmentVariables)
+
+	update := NewUpdateFunctionInput("svc", "fn").WithEnvironment(context.Background(), NewEnvironment().WithVar("FC_A", "1"))
+	assert.NotNil(update.Validate())
+
+	// the resolved secrets are redacted from the output of the function created with the input
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"functionName": "fn", "environmentVariables": {"DSN": "user:s3cr3t-env-test@db"}}`))
+	}))
+	defer server.Close()
+	client, err := NewClient(server.URL, APIVersionV1, "ak", "sk")
+	assert.Nil(err)
+	resolver := SecretResolverFunc(func(ctx context.Context, name string) (string, error) {
+		return "s3cr3t-env-test", nil
+	})
+	input = NewCreateFunctionInput("svc").WithFunctionName("fn").
+		WithEnvironment(context.Background(), NewEnvironment().WithVar("DSN", "user:${secret:db}@db").WithSecretResolver(resolver))
+	output, err := client.CreateFunction(input)
+	assert.Nil(err)
+	assert.Equal("user:s3cr3t-env-test@db", output.EnvironmentVariables["DSN"])
+	assert.Contains(output.String(), "user:******@db")
+
+	// outputs not created from the input are redacted with the environment
+	env := NewEnvironment().WithVar("DSN", "user:${secret:db}@db").WithVar("DATABASE_URL", "${secret:db}").WithSecretResolver(resolver)
+	_, err = env.Resolve(context.Background())
+	assert.Nil(err)
+	get, err := client.GetFunction(NewGetFunctionInput("svc", "fn"))
+	assert.Nil(err)
+	assert.Contains(get.String(), "s3cr3t-env-test")
+	str := get.WithRedaction(env).String()
+	assert.False(strings.Contains(str, "s3cr3t-env-test"))
+	assert.Contains(str, "user:******@db")
+	assert.Equal("user:s3cr3t-env-test@db", get.EnvironmentVariables["DSN"])
+	updated := &UpdateFunctionOutput{}
+	updated.EnvironmentVariables = map[string]string{"DATABASE_URL": "s3cr3t-env-test"}
+	assert.Contains(updated.WithRedaction(env).String(), `"DATABASE_URL": "******"`)
+}
@@ -7,6 +7,7 @@ import (
 
 var (
 	ErrUnknownTriggerType = errors.New("unknown trigger type")
+	ErrNoPreviousVersion  = errors.New("no previous version")
 )
 
 // ServiceError defines error from fc
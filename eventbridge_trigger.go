@@ -1,14 +1,32 @@
 package fc
 
+import (
+	"fmt"
+
+	"github.com/aliyun/fc-go-sdk/eventpattern"
+)
+
+// Event source types supported by event bridge trigger
+const (
+	EventSourceTypeDefault  = "Default"
+	EventSourceTypeMNS      = "MNS"
+	EventSourceTypeRocketMQ = "RocketMQ"
+	EventSourceTypeRabbitMQ = "RabbitMQ"
+	EventSourceTypeKafka    = "Kafka"
+	EventSourceTypeMQTT     = "MQTT"
+	EventSourceTypeDTS      = "DTS"
+	EventSourceTypeHTTP     = "HTTP"
+)
+
 // TriggerConfig defines the event bridge trigger config which maps to FunctionComputeConfiguration.
 type EventBridgeTriggerConfig struct {
 	TriggerEnable          *bool              `json:"triggerEnable"`
 	AsyncInvocationType    *bool              `json:"asyncInvocationType"`
 	EventSourceConfig      *EventSourceConfig `json:"eventSourceConfig"`
 	EventRuleFilterPattern *string            `json:"eventRuleFilterPattern"`
+	RunOptions             *RunOptions        `json:"runOptions,omitempty"`
 }
 
-
 // NewEventBridgeTriggerConfig ...
 func NewEventBridgeTriggerConfig() *EventBridgeTriggerConfig {
 	return &EventBridgeTriggerConfig{}
@@ -34,6 +52,11 @@ func (ebtc *EventBridgeTriggerConfig) WithEventRuleFilterPattern(eventRuleFilter
 	return ebtc
 }
 
+func (ebtc *EventBridgeTriggerConfig) WithRunOptions(runOptions *RunOptions) *EventBridgeTriggerConfig {
+	ebtc.RunOptions = runOptions
+	return ebtc
+}
+
 // EventSourceConfig ...
 type EventSourceConfig struct {
 	EventSourceType       *string                `json:"eventSourceType"`
@@ -63,6 +86,11 @@ type EventSourceParameters struct {
 	SourceRocketMQParameters *SourceRocketMQParameters `json:"sourceRocketMQParameters"`
 
 	SourceRabbitMQParameters *SourceRabbitMQParameters `json:"sourceRabbitMQParameters"`
+
+	SourceKafkaParameters     *SourceKafkaParameters     `json:"sourceKafkaParameters,omitempty"`
+	SourceMQTTParameters      *SourceMQTTParameters      `json:"sourceMQTTParameters,omitempty"`
+	SourceDTSParameters       *SourceDTSParameters       `json:"sourceDTSParameters,omitempty"`
+	SourceHTTPEventParameters *SourceHTTPEventParameters `json:"sourceHTTPEventParameters,omitempty"`
 }
 
 // NewEventSourceParameters ...
@@ -88,6 +116,30 @@ func (esp *EventSourceParameters) WithSourceRabbitMQParameters(sourceRabbitMQPar
 	return esp
 }
 
+// WithSourceKafkaParameters
+func (esp *EventSourceParameters) WithSourceKafkaParameters(sourceKafkaParameters *SourceKafkaParameters) *EventSourceParameters {
+	esp.SourceKafkaParameters = sourceKafkaParameters
+	return esp
+}
+
+// WithSourceMQTTParameters
+func (esp *EventSourceParameters) WithSourceMQTTParameters(sourceMQTTParameters *SourceMQTTParameters) *EventSourceParameters {
+	esp.SourceMQTTParameters = sourceMQTTParameters
+	return esp
+}
+
+// WithSourceDTSParameters
+func (esp *EventSourceParameters) WithSourceDTSParameters(sourceDTSParameters *SourceDTSParameters) *EventSourceParameters {
+	esp.SourceDTSParameters = sourceDTSParameters
+	return esp
+}
+
+// WithSourceHTTPEventParameters
+func (esp *EventSourceParameters) WithSourceHTTPEventParameters(sourceHTTPEventParameters *SourceHTTPEventParameters) *EventSourceParameters {
+	esp.SourceHTTPEventParameters = sourceHTTPEventParameters
+	return esp
+}
+
 // SourceMNSParameters refers to github.com/alibabacloud-go/eventbridge-sdk v1.2.10
 type SourceMNSParameters struct {
 	RegionId       *string `json:"RegionId,omitempty"`
@@ -212,3 +264,348 @@ func (rabbit *SourceRabbitMQParameters) WithQueueName(queueName string) *SourceR
 	rabbit.QueueName = &queueName
 	return rabbit
 }
+
+// SourceKafkaParameters refers to github.com/alibabacloud-go/eventbridge-sdk v1.2.10
+type SourceKafkaParameters struct {
+	RegionId        *string `json:"RegionId,omitempty"`
+	InstanceId      *string `json:"InstanceId,omitempty"`
+	Topic           *string `json:"Topic,omitempty"`
+	ConsumerGroup   *string `json:"ConsumerGroup,omitempty"`
+	OffsetReset     *string `json:"OffsetReset,omitempty"`
+	Network         *string `json:"Network,omitempty"`
+	VpcId           *string `json:"VpcId,omitempty"`
+	VSwitchIds      *string `json:"VSwitchIds,omitempty"`
+	SecurityGroupId *string `json:"SecurityGroupId,omitempty"`
+}
+
+// NewSourceKafkaParameters ...
+func NewSourceKafkaParameters() *SourceKafkaParameters {
+	return &SourceKafkaParameters{}
+}
+
+// WithRegionId ...
+func (kafka *SourceKafkaParameters) WithRegionId(regionId string) *SourceKafkaParameters {
+	kafka.RegionId = &regionId
+	return kafka
+}
+
+// WithInstanceId ...
+func (kafka *SourceKafkaParameters) WithInstanceId(instanceId string) *SourceKafkaParameters {
+	kafka.InstanceId = &instanceId
+	return kafka
+}
+
+// WithTopic ...
+func (kafka *SourceKafkaParameters) WithTopic(topic string) *SourceKafkaParameters {
+	kafka.Topic = &topic
+	return kafka
+}
+
+// WithConsumerGroup ...
+func (kafka *SourceKafkaParameters) WithConsumerGroup(consumerGroup string) *SourceKafkaParameters {
+	kafka.ConsumerGroup = &consumerGroup
+	return kafka
+}
+
+// WithOffsetReset sets where to start consuming, either "latest" or "earliest"
+func (kafka *SourceKafkaParameters) WithOffsetReset(offsetReset string) *SourceKafkaParameters {
+	kafka.OffsetReset = &offsetReset
+	return kafka
+}
+
+// WithNetwork sets the network type, either "Default" or "PublicNetwork"
+func (kafka *SourceKafkaParameters) WithNetwork(network string) *SourceKafkaParameters {
+	kafka.Network = &network
+	return kafka
+}
+
+// WithVpcId ...
+func (kafka *SourceKafkaParameters) WithVpcId(vpcId string) *SourceKafkaParameters {
+	kafka.VpcId = &vpcId
+	return kafka
+}
+
+// WithVSwitchIds ...
+func (kafka *SourceKafkaParameters) WithVSwitchIds(vSwitchIds string) *SourceKafkaParameters {
+	kafka.VSwitchIds = &vSwitchIds
+	return kafka
+}
+
+// WithSecurityGroupId ...
+func (kafka *SourceKafkaParameters) WithSecurityGroupId(securityGroupId string) *SourceKafkaParameters {
+	kafka.SecurityGroupId = &securityGroupId
+	return kafka
+}
+
+// SourceMQTTParameters refers to github.com/alibabacloud-go/eventbridge-sdk v1.2.10
+type SourceMQTTParameters struct {
+	RegionId   *string `json:"RegionId,omitempty"`
+	InstanceId *string `json:"InstanceId,omitempty"`
+	Topic      *string `json:"Topic,omitempty"`
+}
+
+// NewSourceMQTTParameters ...
+func NewSourceMQTTParameters() *SourceMQTTParameters {
+	return &SourceMQTTParameters{}
+}
+
+// WithRegionId ...
+func (mqtt *SourceMQTTParameters) WithRegionId(regionId string) *SourceMQTTParameters {
+	mqtt.RegionId = &regionId
+	return mqtt
+}
+
+// WithInstanceId ...
+func (mqtt *SourceMQTTParameters) WithInstanceId(instanceId string) *SourceMQTTParameters {
+	mqtt.InstanceId = &instanceId
+	return mqtt
+}
+
+// WithTopic ...
+func (mqtt *SourceMQTTParameters) WithTopic(topic string) *SourceMQTTParameters {
+	mqtt.Topic = &topic
+	return mqtt
+}
+
+// SourceDTSParameters refers to github.com/alibabacloud-go/eventbridge-sdk v1.2.10
+type SourceDTSParameters struct {
+	RegionId       *string `json:"RegionId,omitempty"`
+	TaskId         *string `json:"TaskId,omitempty"`
+	BrokerUrl      *string `json:"BrokerUrl,omitempty"`
+	Topic          *string `json:"Topic,omitempty"`
+	Sid            *string `json:"Sid,omitempty"`
+	Username       *string `json:"Username,omitempty"`
+	Password       *string `json:"Password,omitempty"`
+	InitCheckPoint *int64  `json:"InitCheckPoint,omitempty"`
+}
+
+// NewSourceDTSParameters ...
+func NewSourceDTSParameters() *SourceDTSParameters {
+	return &SourceDTSParameters{}
+}
+
+// WithRegionId ...
+func (dts *SourceDTSParameters) WithRegionId(regionId string) *SourceDTSParameters {
+	dts.RegionId = &regionId
+	return dts
+}
+
+// WithTaskId ...
+func (dts *SourceDTSParameters) WithTaskId(taskId string) *SourceDTSParameters {
+	dts.TaskId = &taskId
+	return dts
+}
+
+// WithBrokerUrl ...
+func (dts *SourceDTSParameters) WithBrokerUrl(brokerUrl string) *SourceDTSParameters {
+	dts.BrokerUrl = &brokerUrl
+	return dts
+}
+
+// WithTopic ...
+func (dts *SourceDTSParameters) WithTopic(topic string) *SourceDTSParameters {
+	dts.Topic = &topic
+	return dts
+}
+
+// WithSid ...
+func (dts *SourceDTSParameters) WithSid(sid string) *SourceDTSParameters {
+	dts.Sid = &sid
+	return dts
+}
+
+// WithUsername ...
+func (dts *SourceDTSParameters) WithUsername(username string) *SourceDTSParameters {
+	dts.Username = &username
+	return dts
+}
+
+// WithPassword ...
+func (dts *SourceDTSParameters) WithPassword(password string) *SourceDTSParameters {
+	dts.Password = &password
+	return dts
+}
+
+// WithInitCheckPoint sets the unix timestamp in seconds to start consuming from
+func (dts *SourceDTSParameters) WithInitCheckPoint(initCheckPoint int64) *SourceDTSParameters {
+	dts.InitCheckPoint = &initCheckPoint
+	return dts
+}
+
+// SourceHTTPEventParameters refers to github.com/alibabacloud-go/eventbridge-sdk v1.2.10
+type SourceHTTPEventParameters struct {
+	Type           *string  `json:"Type,omitempty"`
+	Method         []string `json:"Method,omitempty"`
+	SecurityConfig *string  `json:"SecurityConfig,omitempty"`
+	Ip             []string `json:"Ip,omitempty"`
+	Referer        []string `json:"Referer,omitempty"`
+}
+
+// NewSourceHTTPEventParameters ...
+func NewSourceHTTPEventParameters() *SourceHTTPEventParameters {
+	return &SourceHTTPEventParameters{}
+}
+
+// WithType sets the accepted protocol, one of "HTTP", "HTTPS" or "HTTP&HTTPS"
+func (h *SourceHTTPEventParameters) WithType(t string) *SourceHTTPEventParameters {
+	h.Type = &t
+	return h
+}
+
+// WithMethod ...
+func (h *SourceHTTPEventParameters) WithMethod(methods ...string) *SourceHTTPEventParameters {
+	h.Method = make([]string, len(methods))
+	copy(h.Method, methods)
+	return h
+}
+
+// WithSecurityConfig sets the security mode, one of "none", "ip" or "referer"
+func (h *SourceHTTPEventParameters) WithSecurityConfig(securityConfig string) *SourceHTTPEventParameters {
+	h.SecurityConfig = &securityConfig
+	return h
+}
+
+// WithIp ...
+func (h *SourceHTTPEventParameters) WithIp(ips ...string) *SourceHTTPEventParameters {
+	h.Ip = make([]string, len(ips))
+	copy(h.Ip, ips)
+	return h
+}
+
+// WithReferer ...
+func (h *SourceHTTPEventParameters) WithReferer(referers ...string) *SourceHTTPEventParameters {
+	h.Referer = make([]string, len(referers))
+	copy(h.Referer, referers)
+	return h
+}
+
+// RunOptions defines how the event bridge trigger pushes events to function
+type RunOptions struct {
+	Mode            *string          `json:"mode,omitempty"`
+	MaximumTasks    *int64           `json:"maximumTasks,omitempty"`
+	ErrorsTolerance *string          `json:"errorsTolerance,omitempty"`
+	BatchWindow     *BatchWindow     `json:"batchWindow,omitempty"`
+	RetryStrategy   *RetryStrategy   `json:"retryStrategy,omitempty"`
+	DeadLetterQueue *DeadLetterQueue `json:"deadLetterQueue,omitempty"`
+}
+
+// NewRunOptions ...
+func NewRunOptions() *RunOptions {
+	return &RunOptions{}
+}
+
+// WithMode sets the invocation mode, either "event-streaming" or "event-driven"
+func (ro *RunOptions) WithMode(mode string) *RunOptions {
+	ro.Mode = &mode
+	return ro
+}
+
+// WithMaximumTasks sets the concurrency of the event consumer
+func (ro *RunOptions) WithMaximumTasks(maximumTasks int64) *RunOptions {
+	ro.MaximumTasks = &maximumTasks
+	return ro
+}
+
+// WithErrorsTolerance sets the error tolerance, either "ALL" or "NONE"
+func (ro *RunOptions) WithErrorsTolerance(errorsTolerance string) *RunOptions {
+	ro.ErrorsTolerance = &errorsTolerance
+	return ro
+}
+
+// WithBatchWindow ...
+func (ro *RunOptions) WithBatchWindow(batchWindow *BatchWindow) *RunOptions {
+	ro.BatchWindow = batchWindow
+	return ro
+}
+
+// WithRetryStrategy ...
+func (ro *RunOptions) WithRetryStrategy(retryStrategy *RetryStrategy) *RunOptions {
+	ro.RetryStrategy = retryStrategy
+	return ro
+}
+
+// WithDeadLetterQueue ...
+func (ro *RunOptions) WithDeadLetterQueue(deadLetterQueue *DeadLetterQueue) *RunOptions {
+	ro.DeadLetterQueue = deadLetterQueue
+	return ro
+}
+
+// BatchWindow defines the batch push condition, whichever is reached first
+type BatchWindow struct {
+	CountBasedWindow *int64 `json:"CountBasedWindow,omitempty"`
+	TimeBasedWindow  *int64 `json:"TimeBasedWindow,omitempty"`
+}
+
+// NewBatchWindow ...
+func NewBatchWindow() *BatchWindow {
+	return &BatchWindow{}
+}
+
+// WithCountBasedWindow sets the max number of events in a batch
+func (bw *BatchWindow) WithCountBasedWindow(count int64) *BatchWindow {
+	bw.CountBasedWindow = &count
+	return bw
+}
+
+// WithTimeBasedWindow sets the max seconds to wait for a batch
+func (bw *BatchWindow) WithTimeBasedWindow(seconds int64) *BatchWindow {
+	bw.TimeBasedWindow = &seconds
+	return bw
+}
+
+// RetryStrategy defines how failed pushes are retried
+type RetryStrategy struct {
+	PushRetryStrategy *string `json:"PushRetryStrategy,omitempty"`
+}
+
+// NewRetryStrategy ...
+func NewRetryStrategy() *RetryStrategy {
+	return &RetryStrategy{}
+}
+
+// WithPushRetryStrategy sets the retry strategy, either "BACKOFF_RETRY" or "EXPONENTIAL_DECAY_RETRY"
+func (rs *RetryStrategy) WithPushRetryStrategy(strategy string) *RetryStrategy {
+	rs.PushRetryStrategy = &strategy
+	return rs
+}
+
+// DeadLetterQueue defines where the events failed after retries are delivered
+type DeadLetterQueue struct {
+	Arn *string `json:"Arn,omitempty"`
+}
+
+// NewDeadLetterQueue ...
+func NewDeadLetterQueue() *DeadLetterQueue {
+	return &DeadLetterQueue{}
+}
+
+// WithArn ...
+func (dlq *DeadLetterQueue) WithArn(arn string) *DeadLetterQueue {
+	dlq.Arn = &arn
+	return dlq
+}
+
+// Validate checks the event rule filter pattern can be parsed
+func (ebtc *EventBridgeTriggerConfig) Validate() error {
+	if ebtc.EventRuleFilterPattern == nil {
+		return nil
+	}
+	if _, err := eventpattern.Parse(*ebtc.EventRuleFilterPattern); err != nil {
+		return fmt.Errorf("Event rule filter pattern is invalid: %v", err)
+	}
+	return nil
+}
+
+// MatchEvent reports whether the JSON encoded CloudEvent would pass the trigger's EventRuleFilterPattern.
+// A trigger without filter pattern receives every event.
+func (ebtc *EventBridgeTriggerConfig) MatchEvent(event []byte) (bool, error) {
+	pattern := ""
+	if ebtc.EventRuleFilterPattern != nil {
+		pattern = *ebtc.EventRuleFilterPattern
+	}
+	p, err := eventpattern.Parse(pattern)
+	if err != nil {
+		return false, err
+	}
+	return p.Match(event)
+}
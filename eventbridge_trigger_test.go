@@ -0,0 +1,106 @@
+package fc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestEventBridgeTriggerConfigStructs(t *testing.T) {
+	suite.Run(t, new(EventBridgeTriggerConfigTestSuite))
+}
+
+type EventBridgeTriggerConfigTestSuite struct {
+	suite.Suite
+}
+
+func (s *EventBridgeTriggerConfigTestSuite) TestKafkaSource() {
+	assert := s.Require()
+
+	config := NewEventBridgeTriggerConfig().WithTriggerEnable(true).WithAsyncInvocationType(false).
+		WithEventSourceConfig(NewEventSourceConfig().WithEventSourceType(EventSourceTypeKafka).
+			WithEventSourceParameters(NewEventSourceParameters().WithSourceKafkaParameters(
+				NewSourceKafkaParameters().WithRegionId("cn-hangzhou").WithInstanceId("alikafka-1").
+					WithTopic("orders").WithConsumerGroup("fc").WithOffsetReset("latest").WithNetwork("Default"))))
+
+	b, err := json.Marshal(config)
+	assert.Nil(err)
+
+	out := map[string]interface{}{}
+	assert.Nil(json.Unmarshal(b, &out))
+	params := out["eventSourceConfig"].(map[string]interface{})["eventSourceParameters"].(map[string]interface{})
+	kafka := params["sourceKafkaParameters"].(map[string]interface{})
+	assert.Equal("orders", kafka["Topic"])
+	assert.Equal("fc", kafka["ConsumerGroup"])
+	assert.NotContains(kafka, "VpcId")
+	assert.NotContains(params, "sourceMQTTParameters")
+	assert.NotContains(params, "sourceDTSParameters")
+	assert.NotContains(out, "runOptions")
+}
+
+func (s *EventBridgeTriggerConfigTestSuite) TestRunOptions() {
+	assert := s.Require()
+
+	config := NewEventBridgeTriggerConfig().WithRunOptions(NewRunOptions().
+		WithMode("event-streaming").
+		WithMaximumTasks(4).
+		WithErrorsTolerance("ALL").
+		WithBatchWindow(NewBatchWindow().WithCountBasedWindow(100).WithTimeBasedWindow(10)).
+		WithRetryStrategy(NewRetryStrategy().WithPushRetryStrategy("BACKOFF_RETRY")).
+		WithDeadLetterQueue(NewDeadLetterQueue().WithArn("acs:mns:cn-hangzhou:123:/queues/dlq")))
+
+	b, err := json.Marshal(config)
+	assert.Nil(err)
+
+	decoded := NewEventBridgeTriggerConfig()
+	assert.Nil(json.Unmarshal(b, decoded))
+	assert.Equal("event-streaming", *decoded.RunOptions.Mode)
+	assert.Equal(int64(4), *decoded.RunOptions.MaximumTasks)
+	assert.Equal(int64(100), *decoded.RunOptions.BatchWindow.CountBasedWindow)
+	assert.Equal(int64(10), *decoded.RunOptions.BatchWindow.TimeBasedWindow)
+	assert.Equal("BACKOFF_RETRY", *decoded.RunOptions.RetryStrategy.PushRetryStrategy)
+	assert.Equal("acs:mns:cn-hangzhou:123:/queues/dlq", *decoded.RunOptions.DeadLetterQueue.Arn)
+}
+
+func (s *EventBridgeTriggerConfigTestSuite) TestMQTTDTSAndHTTPSources() {
+	assert := s.Require()
+
+	params := NewEventSourceParameters().
+		WithSourceMQTTParameters(NewSourceMQTTParameters().WithRegionId("cn-hangzhou").WithInstanceId("mqtt-1").WithTopic("devices")).
+		WithSourceDTSParameters(NewSourceDTSParameters().WithTaskId("dts-1").WithSid("sid").WithInitCheckPoint(1620962769)).
+		WithSourceHTTPEventParameters(NewSourceHTTPEventParameters().WithType("HTTPS").WithMethod("GET", "POST").
+			WithSecurityConfig("ip").WithIp("10.0.0.0/8"))
+
+	b, err := json.Marshal(params)
+	assert.Nil(err)
+
+	decoded := NewEventSourceParameters()
+	assert.Nil(json.Unmarshal(b, decoded))
+	assert.Equal("devices", *decoded.SourceMQTTParameters.Topic)
+	assert.Equal(int64(1620962769), *decoded.SourceDTSParameters.InitCheckPoint)
+	assert.Equal([]string{"GET", "POST"}, decoded.SourceHTTPEventParameters.Method)
+	assert.Equal([]string{"10.0.0.0/8"}, decoded.SourceHTTPEventParameters.Ip)
+	assert.Nil(decoded.SourceHTTPEventParameters.Referer)
+}
+
+func (s *EventBridgeTriggerConfigTestSuite) TestMatchEvent() {
+	assert := s.Require()
+
+	event := []byte(`{"source": "acs.mns", "type": "mns:Queue:SendMessage", "data": {"messageBody": "hello"}}`)
+
+	ok, err := NewEventBridgeTriggerConfig().MatchEvent(event)
+	assert.Nil(err)
+	assert.True(ok)
+
+	ok, err = NewEventBridgeTriggerConfig().WithEventRuleFilterPattern(`{"source": ["acs.mns"]}`).MatchEvent(event)
+	assert.Nil(err)
+	assert.True(ok)
+
+	ok, err = NewEventBridgeTriggerConfig().WithEventRuleFilterPattern(`{"source": ["acs.oss"]}`).MatchEvent(event)
+	assert.Nil(err)
+	assert.False(ok)
+
+	_, err = NewEventBridgeTriggerConfig().WithEventRuleFilterPattern(`{"source": [{"prefix": 1}]}`).MatchEvent(event)
+	assert.NotNil(err)
+}
@@ -0,0 +1,107 @@
+package eventpattern
+
+import (
+	"net"
+	"strings"
+)
+
+// matcher matches a single, present, non array event value.
+type matcher interface {
+	match(value interface{}) bool
+}
+
+type equalMatcher struct {
+	value interface{}
+}
+
+func (m equalMatcher) match(value interface{}) bool {
+	return m.value == value
+}
+
+type prefixMatcher struct {
+	prefix string
+}
+
+func (m prefixMatcher) match(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && strings.HasPrefix(s, m.prefix)
+}
+
+type suffixMatcher struct {
+	suffix string
+}
+
+func (m suffixMatcher) match(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && strings.HasSuffix(s, m.suffix)
+}
+
+type anythingButMatcher struct {
+	excluded []matcher
+}
+
+func (m anythingButMatcher) match(value interface{}) bool {
+	for _, e := range m.excluded {
+		if e.match(value) {
+			return false
+		}
+	}
+	return true
+}
+
+type numericCondition struct {
+	op    string
+	value float64
+}
+
+type numericMatcher struct {
+	conditions []numericCondition
+}
+
+func (m numericMatcher) match(value interface{}) bool {
+	n, ok := value.(float64)
+	if !ok {
+		return false
+	}
+	for _, c := range m.conditions {
+		var ok bool
+		switch c.op {
+		case "=":
+			ok = n == c.value
+		case "<":
+			ok = n < c.value
+		case "<=":
+			ok = n <= c.value
+		case ">":
+			ok = n > c.value
+		case ">=":
+			ok = n >= c.value
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+type cidrMatcher struct {
+	network *net.IPNet
+}
+
+func (m cidrMatcher) match(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	ip := net.ParseIP(s)
+	return ip != nil && m.network.Contains(ip)
+}
+
+// existsMatcher is handled by matchValue as it depends on field presence.
+type existsMatcher struct {
+	exists bool
+}
+
+func (m existsMatcher) match(value interface{}) bool {
+	return m.exists
+}
@@ -0,0 +1,304 @@
+// Package eventpattern evaluates EventBridge event filter patterns locally.
+//
+// A pattern is a JSON object whose keys mirror the fields of the event. Leaf
+// values are arrays of matchers and the field matches when any matcher in the
+// array matches. Nested objects descend into nested event fields. Besides
+// literal values the following matchers are supported:
+//
+//	{"prefix": "acs."}
+//	{"suffix": ".jpg"}
+//	{"anything-but": "value"} / {"anything-but": ["a", "b"]} / {"anything-but": {"prefix": "x"}}
+//	{"numeric": [">", 0, "<=", 100]}
+//	{"cidr": "10.0.0.0/8"}
+//	{"exists": true}
+//
+// The pattern in EventBridgeTriggerConfig.EventRuleFilterPattern can be
+// checked against sample CloudEvents before the trigger is deployed:
+//
+//	p, err := eventpattern.Parse(`{"source": ["acs.oss"], "data": {"size": [{"numeric": [">", 0]}]}}`)
+//	ok, err := p.Match(eventJSON)
+package eventpattern
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Pattern is a parsed event filter pattern.
+type Pattern struct {
+	root *node
+}
+
+// node is either an object node with child fields or a leaf with matchers.
+type node struct {
+	fields   map[string]*node
+	matchers []matcher
+}
+
+// Parse parses an EventBridge event filter pattern.
+// An empty string is treated as the empty pattern which matches every event.
+func Parse(pattern string) (*Pattern, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return &Pattern{root: &node{fields: map[string]*node{}}}, nil
+	}
+	var raw interface{}
+	if err := json.Unmarshal([]byte(pattern), &raw); err != nil {
+		return nil, fmt.Errorf("invalid event pattern: %v", err)
+	}
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid event pattern: top level must be an object")
+	}
+	root, err := parseObject(obj, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Pattern{root: root}, nil
+}
+
+// MustParse is like Parse but panics if the pattern cannot be parsed.
+func MustParse(pattern string) *Pattern {
+	p, err := Parse(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Match reports whether the JSON encoded event matches the pattern.
+func (p *Pattern) Match(event []byte) (bool, error) {
+	var raw interface{}
+	if err := json.Unmarshal(event, &raw); err != nil {
+		return false, fmt.Errorf("invalid event: %v", err)
+	}
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return false, fmt.Errorf("invalid event: top level must be an object")
+	}
+	return p.MatchMap(obj), nil
+}
+
+// MatchMap reports whether the decoded event matches the pattern.
+func (p *Pattern) MatchMap(event map[string]interface{}) bool {
+	return p.root.matchObject(event)
+}
+
+func parseObject(obj map[string]interface{}, path string) (*node, error) {
+	n := &node{fields: make(map[string]*node, len(obj))}
+	for key, value := range obj {
+		childPath := joinPath(path, key)
+		switch v := value.(type) {
+		case map[string]interface{}:
+			child, err := parseObject(v, childPath)
+			if err != nil {
+				return nil, err
+			}
+			n.fields[key] = child
+		case []interface{}:
+			matchers, err := parseMatchers(v, childPath)
+			if err != nil {
+				return nil, err
+			}
+			n.fields[key] = &node{matchers: matchers}
+		default:
+			// A bare value is shorthand for a single element array.
+			matchers, err := parseMatchers([]interface{}{v}, childPath)
+			if err != nil {
+				return nil, err
+			}
+			n.fields[key] = &node{matchers: matchers}
+		}
+	}
+	return n, nil
+}
+
+func parseMatchers(values []interface{}, path string) ([]matcher, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("invalid event pattern at %q: empty matcher list", path)
+	}
+	matchers := make([]matcher, 0, len(values))
+	for _, value := range values {
+		m, err := parseMatcher(value, path)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+func parseMatcher(value interface{}, path string) (matcher, error) {
+	switch v := value.(type) {
+	case nil, string, float64, bool:
+		return equalMatcher{value: v}, nil
+	case map[string]interface{}:
+		if len(v) != 1 {
+			return nil, fmt.Errorf("invalid event pattern at %q: matcher must have exactly one key, got %s", path, keys(v))
+		}
+		for op, arg := range v {
+			switch op {
+			case "prefix":
+				s, ok := arg.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid event pattern at %q: prefix expects a string", path)
+				}
+				return prefixMatcher{prefix: s}, nil
+			case "suffix":
+				s, ok := arg.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid event pattern at %q: suffix expects a string", path)
+				}
+				return suffixMatcher{suffix: s}, nil
+			case "anything-but":
+				return parseAnythingBut(arg, path)
+			case "numeric":
+				return parseNumeric(arg, path)
+			case "cidr":
+				s, ok := arg.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid event pattern at %q: cidr expects a string", path)
+				}
+				_, ipNet, err := net.ParseCIDR(s)
+				if err != nil {
+					return nil, fmt.Errorf("invalid event pattern at %q: %v", path, err)
+				}
+				return cidrMatcher{network: ipNet}, nil
+			case "exists":
+				b, ok := arg.(bool)
+				if !ok {
+					return nil, fmt.Errorf("invalid event pattern at %q: exists expects a boolean", path)
+				}
+				return existsMatcher{exists: b}, nil
+			default:
+				return nil, fmt.Errorf("invalid event pattern at %q: unknown matcher %q", path, op)
+			}
+		}
+	}
+	return nil, fmt.Errorf("invalid event pattern at %q: unsupported matcher %v", path, value)
+}
+
+func parseAnythingBut(arg interface{}, path string) (matcher, error) {
+	switch v := arg.(type) {
+	case string, float64, bool:
+		return anythingButMatcher{excluded: []matcher{equalMatcher{value: v}}}, nil
+	case []interface{}:
+		if len(v) == 0 {
+			return nil, fmt.Errorf("invalid event pattern at %q: anything-but expects a non-empty list", path)
+		}
+		excluded := make([]matcher, 0, len(v))
+		for _, e := range v {
+			switch e.(type) {
+			case string, float64, bool:
+				excluded = append(excluded, equalMatcher{value: e})
+			default:
+				return nil, fmt.Errorf("invalid event pattern at %q: anything-but list only supports literal values", path)
+			}
+		}
+		return anythingButMatcher{excluded: excluded}, nil
+	case map[string]interface{}:
+		m, err := parseMatcher(v, path)
+		if err != nil {
+			return nil, err
+		}
+		switch m.(type) {
+		case prefixMatcher, suffixMatcher:
+			return anythingButMatcher{excluded: []matcher{m}}, nil
+		}
+		return nil, fmt.Errorf("invalid event pattern at %q: anything-but only supports prefix and suffix", path)
+	}
+	return nil, fmt.Errorf("invalid event pattern at %q: unsupported anything-but value %v", path, arg)
+}
+
+func parseNumeric(arg interface{}, path string) (matcher, error) {
+	list, ok := arg.([]interface{})
+	if !ok || len(list) == 0 || len(list)%2 != 0 || len(list) > 4 {
+		return nil, fmt.Errorf("invalid event pattern at %q: numeric expects one or two operator/value pairs", path)
+	}
+	m := numericMatcher{}
+	for i := 0; i < len(list); i += 2 {
+		op, ok := list[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid event pattern at %q: numeric operator must be a string", path)
+		}
+		switch op {
+		case "=", "<", "<=", ">", ">=":
+		default:
+			return nil, fmt.Errorf("invalid event pattern at %q: unknown numeric operator %q", path, op)
+		}
+		value, ok := list[i+1].(float64)
+		if !ok {
+			return nil, fmt.Errorf("invalid event pattern at %q: numeric operand must be a number", path)
+		}
+		m.conditions = append(m.conditions, numericCondition{op: op, value: value})
+	}
+	return m, nil
+}
+
+func (n *node) matchObject(obj map[string]interface{}) bool {
+	for key, child := range n.fields {
+		value, present := obj[key]
+		if !child.match(value, present) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *node) match(value interface{}, present bool) bool {
+	if n.matchers == nil {
+		if !present {
+			// A missing object only satisfies nested {"exists": false} matchers.
+			return n.matchObject(map[string]interface{}{})
+		}
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		return n.matchObject(obj)
+	}
+	for _, m := range n.matchers {
+		if matchValue(m, value, present) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchValue matches a single matcher against the value. Arrays in the event
+// match if any of their elements match.
+func matchValue(m matcher, value interface{}, present bool) bool {
+	if e, ok := m.(existsMatcher); ok {
+		return e.exists == present
+	}
+	if !present {
+		return false
+	}
+	if list, ok := value.([]interface{}); ok {
+		for _, item := range list {
+			if m.match(item) {
+				return true
+			}
+		}
+		return false
+	}
+	return m.match(value)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func keys(m map[string]interface{}) string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return "[" + strings.Join(out, ", ") + "]"
+}
@@ -0,0 +1,134 @@
+package eventpattern
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const ossEvent = `{
+	"id": "45ef4dewdwe1-7c35-447a-bd93-fab****",
+	"source": "acs.oss",
+	"specversion": "1.0",
+	"type": "oss:ObjectCreated:PostObject",
+	"subject": "acs:oss:cn-hangzhou:1234567:xls-papk/game_apk/123.jpg",
+	"aliyunaccountid": "1234567",
+	"data": {
+		"region": "cn-hangzhou",
+		"sourceIp": "10.12.3.4",
+		"tags": ["hot", "image"],
+		"oss": {
+			"bucket": {"name": "xls-papk"},
+			"object": {"key": "game_apk/123.jpg", "size": 5120}
+		}
+	}
+}`
+
+func TestPattern(t *testing.T) {
+	suite.Run(t, new(PatternTestSuite))
+}
+
+type PatternTestSuite struct {
+	suite.Suite
+}
+
+func (s *PatternTestSuite) match(pattern string) bool {
+	assert := s.Require()
+	p, err := Parse(pattern)
+	assert.Nil(err)
+	ok, err := p.Match([]byte(ossEvent))
+	assert.Nil(err)
+	return ok
+}
+
+func (s *PatternTestSuite) TestEmptyPattern() {
+	assert := s.Require()
+	assert.True(s.match(""))
+	assert.True(s.match("{}"))
+}
+
+func (s *PatternTestSuite) TestExactMatch() {
+	assert := s.Require()
+	assert.True(s.match(`{"source": ["acs.oss"]}`))
+	assert.True(s.match(`{"source": ["acs.mns", "acs.oss"]}`))
+	assert.True(s.match(`{"source": "acs.oss"}`))
+	assert.False(s.match(`{"source": ["acs.mns"]}`))
+	assert.False(s.match(`{"missing": ["acs.oss"]}`))
+	assert.True(s.match(`{"data": {"oss": {"object": {"size": [5120]}}}}`))
+	assert.True(s.match(`{"data": {"tags": ["image"]}}`))
+}
+
+func (s *PatternTestSuite) TestPrefixAndSuffix() {
+	assert := s.Require()
+	assert.True(s.match(`{"type": [{"prefix": "oss:ObjectCreated:"}]}`))
+	assert.False(s.match(`{"type": [{"prefix": "oss:ObjectRemoved:"}]}`))
+	assert.True(s.match(`{"subject": [{"suffix": ".jpg"}, {"suffix": ".png"}]}`))
+	assert.False(s.match(`{"subject": [{"suffix": ".png"}]}`))
+	assert.False(s.match(`{"data": {"oss": {"object": {"size": [{"prefix": "5"}]}}}}`))
+}
+
+func (s *PatternTestSuite) TestAnythingBut() {
+	assert := s.Require()
+	assert.True(s.match(`{"source": [{"anything-but": "acs.mns"}]}`))
+	assert.False(s.match(`{"source": [{"anything-but": ["acs.mns", "acs.oss"]}]}`))
+	assert.False(s.match(`{"type": [{"anything-but": {"prefix": "oss:"}}]}`))
+	assert.True(s.match(`{"subject": [{"anything-but": {"suffix": ".png"}}]}`))
+	assert.False(s.match(`{"data": {"oss": {"object": {"size": [{"anything-but": 5120}]}}}}`))
+}
+
+func (s *PatternTestSuite) TestNumeric() {
+	assert := s.Require()
+	assert.True(s.match(`{"data": {"oss": {"object": {"size": [{"numeric": [">", 0]}]}}}}`))
+	assert.True(s.match(`{"data": {"oss": {"object": {"size": [{"numeric": [">=", 5120, "<", 10240]}]}}}}`))
+	assert.False(s.match(`{"data": {"oss": {"object": {"size": [{"numeric": ["<", 1024]}]}}}}`))
+	assert.True(s.match(`{"data": {"oss": {"object": {"size": [{"numeric": ["=", 5120]}]}}}}`))
+	assert.False(s.match(`{"source": [{"numeric": [">", 0]}]}`))
+}
+
+func (s *PatternTestSuite) TestCIDR() {
+	assert := s.Require()
+	assert.True(s.match(`{"data": {"sourceIp": [{"cidr": "10.0.0.0/8"}]}}`))
+	assert.False(s.match(`{"data": {"sourceIp": [{"cidr": "192.168.0.0/16"}]}}`))
+}
+
+func (s *PatternTestSuite) TestExists() {
+	assert := s.Require()
+	assert.True(s.match(`{"data": {"region": [{"exists": true}]}}`))
+	assert.False(s.match(`{"data": {"zone": [{"exists": true}]}}`))
+	assert.True(s.match(`{"data": {"zone": [{"exists": false}]}}`))
+	assert.False(s.match(`{"data": {"region": [{"exists": false}]}}`))
+	assert.True(s.match(`{"extra": {"zone": [{"exists": false}]}}`))
+}
+
+func (s *PatternTestSuite) TestMultipleFieldsAreAnded() {
+	assert := s.Require()
+	assert.True(s.match(`{"source": ["acs.oss"], "type": [{"prefix": "oss:ObjectCreated:"}]}`))
+	assert.False(s.match(`{"source": ["acs.oss"], "type": [{"prefix": "oss:ObjectRemoved:"}]}`))
+}
+
+func (s *PatternTestSuite) TestInvalidPatterns() {
+	assert := s.Require()
+	for _, pattern := range []string{
+		`[]`,
+		`{"source": }`,
+		`{"source": []}`,
+		`{"source": [{"prefix": 1}]}`,
+		`{"source": [{"unknown": "x"}]}`,
+		`{"source": [{"prefix": "a", "suffix": "b"}]}`,
+		`{"size": [{"numeric": [">"]}]}`,
+		`{"size": [{"numeric": ["!=", 1]}]}`,
+		`{"size": [{"numeric": [">", "1"]}]}`,
+		`{"ip": [{"cidr": "10.0.0.0/33"}]}`,
+		`{"ip": [{"exists": "yes"}]}`,
+		`{"source": [{"anything-but": {"numeric": [">", 1]}}]}`,
+	} {
+		_, err := Parse(pattern)
+		assert.NotNil(err, pattern)
+	}
+}
+
+func (s *PatternTestSuite) TestInvalidEvent() {
+	assert := s.Require()
+	_, err := MustParse(`{}`).Match([]byte(`[1]`))
+	assert.NotNil(err)
+}
@@ -0,0 +1,88 @@
+package events
+
+import "time"
+
+// CDN event names
+const (
+	CDNEventCachedObjectsRefreshed = "CachedObjectsRefreshed"
+	CDNEventCachedObjectsPushed    = "CachedObjectsPushed"
+	CDNEventCachedObjectsBlocked   = "CachedObjectsBlocked"
+	CDNEventLogFileCreated         = "LogFileCreated"
+	CDNEventCdnDomainStarted       = "CdnDomainStarted"
+	CDNEventCdnDomainStopped       = "CdnDomainStopped"
+	CDNEventCdnDomainAdded         = "CdnDomainAdded"
+	CDNEventCdnDomainRemoved       = "CdnDomainRemoved"
+)
+
+// CDNEvent is the event a cdn_events trigger delivers.
+type CDNEvent struct {
+	Events []CDNEventRecord `json:"events"`
+}
+
+// CDNEventRecord ...
+type CDNEventRecord struct {
+	EventName      string            `json:"eventName"`
+	EventVersion   string            `json:"eventVersion"`
+	EventSource    string            `json:"eventSource"`
+	Region         string            `json:"region"`
+	EventTime      time.Time         `json:"eventTime"`
+	TraceID        string            `json:"traceId"`
+	Resource       CDNResource       `json:"resource"`
+	EventParameter CDNEventParameter `json:"eventParameter"`
+	UserIdentity   CDNUserIdentity   `json:"userIdentity"`
+}
+
+// CDNResource ...
+type CDNResource struct {
+	Domain string `json:"domain"`
+}
+
+// CDNEventParameter holds the parameters of all cdn event types; which are set depends on the event name.
+type CDNEventParameter struct {
+	Domain string `json:"domain,omitempty"`
+	Status string `json:"status,omitempty"`
+
+	// CachedObjects* events
+	ObjectPath   []string `json:"objectPath,omitempty"`
+	ObjectType   string   `json:"objectType,omitempty"`
+	TaskID       int64    `json:"taskId,omitempty"`
+	CreatedTime  int64    `json:"createdTime,omitempty"`
+	CompleteTime int64    `json:"completeTime,omitempty"`
+
+	// LogFileCreated events
+	FilePath  string `json:"filePath,omitempty"`
+	FileSize  int64  `json:"fileSize,omitempty"`
+	StartTime int64  `json:"startTime,omitempty"`
+	EndTime   int64  `json:"endTime,omitempty"`
+}
+
+// CDNUserIdentity ...
+type CDNUserIdentity struct {
+	AliUID string `json:"aliUid"`
+}
+
+// DecodeCDNEvent decodes the payload of a cdn_events trigger invocation.
+func DecodeCDNEvent(payload []byte) (*CDNEvent, error) {
+	event := &CDNEvent{}
+	if err := decode(payload, event, "cdn"); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// NewCDNEvent creates a cdn event with a single record for the domain.
+func NewCDNEvent(eventName, accountID, domain string, parameter CDNEventParameter) *CDNEvent {
+	if parameter.Domain == "" {
+		parameter.Domain = domain
+	}
+	return &CDNEvent{Events: []CDNEventRecord{{
+		EventName:      eventName,
+		EventVersion:   "1.0.0",
+		EventSource:    "cdn",
+		Region:         "cn-hangzhou",
+		EventTime:      time.Now().Truncate(time.Second),
+		Resource:       CDNResource{Domain: domain},
+		EventParameter: parameter,
+		UserIdentity:   CDNUserIdentity{AliUID: accountID},
+	}}}
+}
@@ -0,0 +1,88 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CloudEvent is the CloudEvents 1.0 envelope an eventbridge trigger delivers,
+// with the aliyun extension attributes.
+type CloudEvent struct {
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	SpecVersion     string          `json:"specversion"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            string          `json:"time,omitempty"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	DataSchema      string          `json:"dataschema,omitempty"`
+	Data            json.RawMessage `json:"data,omitempty"`
+
+	AliyunAccountID         string `json:"aliyunaccountid,omitempty"`
+	AliyunOriginalAccountID string `json:"aliyunoriginalaccountid,omitempty"`
+	AliyunPublishTime       string `json:"aliyunpublishtime,omitempty"`
+	AliyunEventBusName      string `json:"aliyuneventbusname,omitempty"`
+	AliyunRegionID          string `json:"aliyunregionid,omitempty"`
+	AliyunPublishAddr       string `json:"aliyunpublishaddr,omitempty"`
+}
+
+// EventTime parses the time attribute.
+func (e *CloudEvent) EventTime() (time.Time, error) {
+	return time.Parse(time.RFC3339Nano, e.Time)
+}
+
+// DecodeData decodes the data attribute into v.
+func (e *CloudEvent) DecodeData(v interface{}) error {
+	return decode(e.Data, v, "cloud event data")
+}
+
+// DecodeCloudEvent decodes the payload of an eventbridge trigger invocation.
+func DecodeCloudEvent(payload []byte) (*CloudEvent, error) {
+	event := &CloudEvent{}
+	if err := decode(payload, event, "cloud"); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// DecodeCloudEvents decodes the payload of an eventbridge trigger invocation which may be
+// a single event or, when the trigger has a batch window, an array of events.
+func DecodeCloudEvents(payload []byte) ([]*CloudEvent, error) {
+	trimmed := bytes.TrimSpace(payload)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var events []*CloudEvent
+		if err := decode(trimmed, &events, "cloud"); err != nil {
+			return nil, err
+		}
+		return events, nil
+	}
+	event, err := DecodeCloudEvent(trimmed)
+	if err != nil {
+		return nil, err
+	}
+	return []*CloudEvent{event}, nil
+}
+
+// NewCloudEvent creates a cloud event with JSON data, e.g.
+//
+//	events.NewCloudEvent("acs.oss", "oss:ObjectCreated:PutObject", map[string]interface{}{"region": "cn-hangzhou"})
+func NewCloudEvent(source, eventType string, data interface{}) (*CloudEvent, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cloud event data: %v", err)
+	}
+	now := time.Now().UTC()
+	return &CloudEvent{
+		ID:                 strconv.FormatInt(now.UnixNano(), 36),
+		Source:             source,
+		SpecVersion:        "1.0",
+		Type:               eventType,
+		Time:               now.Format(time.RFC3339Nano),
+		DataContentType:    "application/json;charset=utf-8",
+		Data:               raw,
+		AliyunEventBusName: "default",
+	}, nil
+}
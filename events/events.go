@@ -0,0 +1,27 @@
+// Package events defines the events functions receive from their triggers,
+// with decoders for handlers and constructors for generating test events.
+//
+//	func handler(payload []byte) error {
+//		event, err := events.DecodeOSSEvent(payload)
+//		if err != nil {
+//			return err
+//		}
+//		for _, record := range event.Events {
+//			fmt.Println(record.OSSEvent(), record.OSS.Bucket.Name, record.OSS.Object.Key)
+//		}
+//		return nil
+//	}
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// decode unmarshals the payload into v, naming the event type on failure.
+func decode(payload []byte, v interface{}, name string) error {
+	if err := json.Unmarshal(payload, v); err != nil {
+		return fmt.Errorf("invalid %s event: %v", name, err)
+	}
+	return nil
+}
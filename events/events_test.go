@@ -0,0 +1,163 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventsTestSuite struct {
+	suite.Suite
+}
+
+func TestEvents(t *testing.T) {
+	suite.Run(t, new(EventsTestSuite))
+}
+
+func (s *EventsTestSuite) TestDecodeOSSEvent() {
+	assert := s.Require()
+
+	payload := `{"events":[{"eventName":"ObjectCreated:PutObject","eventSource":"acs:oss","eventTime":"2017-04-21T12:46:37.000Z","eventVersion":"1.0",
+		"oss":{"bucket":{"arn":"acs:oss:cn-shanghai:123456789:testbucket","name":"testbucket","ownerIdentity":"123456789","virtualBucket":""},
+		"object":{"deltaSize":122539,"eTag":"688A7BF4F233DC9C88A80BF985AB7329","key":"image/a.jpg","size":122539},"ossSchemaVersion":"1.0","ruleId":"9adac8e2"},
+		"region":"cn-shanghai","requestParameters":{"sourceIPAddress":"140.205.128.221"},"responseElements":{"requestId":"58F9FF2D3DF792092E12044C"},"userIdentity":{"principalId":"123456789"}}]}`
+	event, err := DecodeOSSEvent([]byte(payload))
+	assert.Nil(err)
+	assert.Len(event.Events, 1)
+	record := event.Events[0]
+	assert.Equal(fc.OSSEventObjectCreatedPutObject, record.OSSEvent())
+	assert.Equal("testbucket", record.OSS.Bucket.Name)
+	assert.Equal("image/a.jpg", record.OSS.Object.Key)
+	assert.Equal(int64(122539), record.OSS.Object.Size)
+	assert.Equal("58F9FF2D3DF792092E12044C", record.ResponseElements.RequestID)
+	assert.Equal(time.Date(2017, 4, 21, 12, 46, 37, 0, time.UTC), record.EventTime)
+
+	_, err = DecodeOSSEvent([]byte("not json"))
+	assert.NotNil(err)
+}
+
+func (s *EventsTestSuite) TestNewOSSEventRoundTrip() {
+	assert := s.Require()
+
+	event := NewOSSEvent(fc.OSSEventObjectRemovedDeleteObject, "cn-hangzhou", "123", "bucket", "a/b.txt", 10)
+	payload, err := json.Marshal(event)
+	assert.Nil(err)
+	decoded, err := DecodeOSSEvent(payload)
+	assert.Nil(err)
+	assert.Equal(event, decoded)
+	assert.Equal("ObjectRemoved:DeleteObject", decoded.Events[0].EventName)
+	assert.Equal(fc.OSSEventObjectRemovedDeleteObject, decoded.Events[0].OSSEvent())
+	assert.Equal("acs:oss:cn-hangzhou:123:bucket", decoded.Events[0].OSS.Bucket.Arn)
+}
+
+func (s *EventsTestSuite) TestTimerEvent() {
+	assert := s.Require()
+
+	event, err := DecodeTimerEvent([]byte(`{"triggerTime":"2018-02-09T05:49:00Z","triggerName":"timer-trigger","payload":"awesome-fc"}`))
+	assert.Nil(err)
+	assert.Equal("timer-trigger", event.TriggerName)
+	assert.Equal("awesome-fc", event.Payload)
+	assert.Equal(time.Date(2018, 2, 9, 5, 49, 0, 0, time.UTC), event.TriggerTime)
+
+	created := NewTimerEvent("t", "p", time.Date(2020, 1, 1, 8, 0, 0, 500, time.FixedZone("CST", 8*3600)))
+	payload, err := json.Marshal(created)
+	assert.Nil(err)
+	assert.Contains(string(payload), `"triggerTime":"2020-01-01T00:00:00Z"`)
+}
+
+func (s *EventsTestSuite) TestLogEvent() {
+	assert := s.Require()
+
+	payload := `{"parameter":{"key":"value"},"source":{"endpoint":"http://cn-shanghai-intranet.log.aliyuncs.com","projectName":"fc-test-project",
+		"logstoreName":"fc-test-logstore","shardId":1,"beginCursor":"MTUy","endCursor":"MTUz"},"jobName":"1f7043ce","taskId":"c2691505","cursorTime":1529486425}`
+	event, err := DecodeLogEvent([]byte(payload))
+	assert.Nil(err)
+	assert.Equal("fc-test-project", event.Source.ProjectName)
+	assert.Equal("fc-test-logstore", event.Source.LogstoreName)
+	assert.Equal(1, event.Source.ShardID)
+	assert.Equal(int64(1529486425), event.CursorTime)
+
+	parameter := map[string]string{}
+	assert.Nil(event.DecodeParameter(&parameter))
+	assert.Equal("value", parameter["key"])
+
+	created, err := NewLogEvent("endpoint", "project", "logstore", 0, nil)
+	assert.Nil(err)
+	assert.Equal("{}", string(created.Parameter))
+}
+
+func (s *EventsTestSuite) TestMNSTopicMessage() {
+	assert := s.Require()
+
+	payload := `{"TopicOwner":"1186202104331798","Message":"mytest","Subscriber":"1186202104331798","PublishTime":1550216480040,
+		"SubscriptionName":"test-fc-subscibe","MessageMD5":"BA4BA9B48AC81F0F9C66F6C909C39DBB","TopicName":"test-topic","MessageId":"2F5B3C08"}`
+	message, err := DecodeMNSTopicMessage([]byte(payload), fc.NotifyContentFormatJSON)
+	assert.Nil(err)
+	assert.Equal("mytest", message.Message)
+	assert.Equal("test-topic", message.TopicName)
+	assert.Equal(int64(1550216480040), message.PublishedAt().UnixNano()/int64(time.Millisecond))
+
+	message, err = DecodeMNSTopicMessage([]byte(payload), fc.NotifyContentFormatStream)
+	assert.Nil(err)
+	assert.Equal(payload, message.Message)
+	assert.Empty(message.TopicName)
+
+	created := NewMNSTopicMessage("123", "topic", "sub", "mytest")
+	assert.Equal("A599D36C4C7A71DDCC1BC7259A15AC3A", created.MessageMD5)
+}
+
+func (s *EventsTestSuite) TestCDNEvent() {
+	assert := s.Require()
+
+	payload := `{"events":[{"eventName":"CachedObjectsRefreshed","eventVersion":"1.0.0","eventSource":"cdn","region":"cn-hangzhou",
+		"eventTime":"2018-03-16T14:19:55+08:00","traceId":"cd2f5fb","resource":{"domain":"example.com"},
+		"eventParameter":{"objectPath":["/2018/03/16/13/33b430c57e7.mp4"],"createdTime":1521180957,"domain":"example.com","completeTime":1521181097,"objectType":"File","taskId":2089687230},
+		"userIdentity":{"aliUid":"1234"}}]}`
+	event, err := DecodeCDNEvent([]byte(payload))
+	assert.Nil(err)
+	record := event.Events[0]
+	assert.Equal(CDNEventCachedObjectsRefreshed, record.EventName)
+	assert.Equal("example.com", record.Resource.Domain)
+	assert.Equal([]string{"/2018/03/16/13/33b430c57e7.mp4"}, record.EventParameter.ObjectPath)
+	assert.Equal(int64(2089687230), record.EventParameter.TaskID)
+	assert.Equal("1234", record.UserIdentity.AliUID)
+
+	created := NewCDNEvent(CDNEventLogFileCreated, "1234", "example.com", CDNEventParameter{FilePath: "/log.gz", FileSize: 10})
+	assert.Equal("example.com", created.Events[0].EventParameter.Domain)
+	assert.Equal("/log.gz", created.Events[0].EventParameter.FilePath)
+}
+
+func (s *EventsTestSuite) TestCloudEvent() {
+	assert := s.Require()
+
+	payload := `{"datacontenttype":"application/json;charset=utf-8","aliyunaccountid":"123","data":{"key":"value"},"subject":"acs:mns:cn-hangzhou:123:queues/q",
+		"source":"my.source","type":"mns:Queue:SendMessage","specversion":"1.0","aliyuneventbusname":"default","id":"abc","time":"2021-04-08T06:28:17.093Z","aliyunregionid":"cn-hangzhou"}`
+	event, err := DecodeCloudEvent([]byte(payload))
+	assert.Nil(err)
+	assert.Equal("abc", event.ID)
+	assert.Equal("mns:Queue:SendMessage", event.Type)
+	assert.Equal("default", event.AliyunEventBusName)
+	t, err := event.EventTime()
+	assert.Nil(err)
+	assert.Equal(2021, t.Year())
+	data := map[string]string{}
+	assert.Nil(event.DecodeData(&data))
+	assert.Equal("value", data["key"])
+
+	batch, err := DecodeCloudEvents([]byte(" [" + payload + "," + payload + "]"))
+	assert.Nil(err)
+	assert.Len(batch, 2)
+	single, err := DecodeCloudEvents([]byte(payload))
+	assert.Nil(err)
+	assert.Len(single, 1)
+
+	created, err := NewCloudEvent("acs.oss", "oss:ObjectCreated:PutObject", map[string]int{"size": 1})
+	assert.Nil(err)
+	assert.Equal("1.0", created.SpecVersion)
+	assert.JSONEq(`{"size":1}`, string(created.Data))
+	_, err = NewCloudEvent("s", "t", make(chan int))
+	assert.NotNil(err)
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// LogEvent is the event a log trigger delivers. It points at the range of a shard
+// to be consumed with the log service SDK.
+type LogEvent struct {
+	Parameter  json.RawMessage `json:"parameter"`
+	Source     LogSource       `json:"source"`
+	JobName    string          `json:"jobName"`
+	TaskID     string          `json:"taskId"`
+	CursorTime int64           `json:"cursorTime"`
+}
+
+// LogSource ...
+type LogSource struct {
+	Endpoint     string `json:"endpoint"`
+	ProjectName  string `json:"projectName"`
+	LogstoreName string `json:"logstoreName"`
+	ShardID      int    `json:"shardId"`
+	BeginCursor  string `json:"beginCursor"`
+	EndCursor    string `json:"endCursor"`
+}
+
+// DecodeParameter decodes the function parameter configured on the log trigger into v.
+func (e *LogEvent) DecodeParameter(v interface{}) error {
+	if len(e.Parameter) == 0 {
+		return nil
+	}
+	return decode(e.Parameter, v, "log parameter")
+}
+
+// DecodeLogEvent decodes the payload of a log trigger invocation.
+func DecodeLogEvent(payload []byte) (*LogEvent, error) {
+	event := &LogEvent{}
+	if err := decode(payload, event, "log"); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// NewLogEvent creates a log event for a shard. The parameter is marshaled as the function parameter.
+func NewLogEvent(endpoint, project, logstore string, shardID int, parameter interface{}) (*LogEvent, error) {
+	if parameter == nil {
+		parameter = map[string]interface{}{}
+	}
+	raw, err := json.Marshal(parameter)
+	if err != nil {
+		return nil, err
+	}
+	return &LogEvent{
+		Parameter: raw,
+		Source: LogSource{
+			Endpoint:     endpoint,
+			ProjectName:  project,
+			LogstoreName: logstore,
+			ShardID:      shardID,
+		},
+		CursorTime: time.Now().Unix(),
+	}, nil
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// MNSTopicMessage is the message a mns topic trigger delivers.
+// With the STREAM content format only Message is populated.
+type MNSTopicMessage struct {
+	TopicOwner       string `json:"TopicOwner"`
+	TopicName        string `json:"TopicName"`
+	Subscriber       string `json:"Subscriber"`
+	SubscriptionName string `json:"SubscriptionName"`
+	MessageID        string `json:"MessageId"`
+	MessageMD5       string `json:"MessageMD5"`
+	MessageTag       string `json:"MessageTag,omitempty"`
+	Message          string `json:"Message"`
+	PublishTime      int64  `json:"PublishTime"`
+}
+
+// PublishedAt returns the publish time of the message.
+func (m *MNSTopicMessage) PublishedAt() time.Time {
+	return time.Unix(0, m.PublishTime*int64(time.Millisecond))
+}
+
+// DecodeMNSTopicMessage decodes the payload of a mns topic trigger invocation
+// according to the trigger's notify content format, fc.NotifyContentFormatJSON or fc.NotifyContentFormatStream.
+func DecodeMNSTopicMessage(payload []byte, notifyContentFormat string) (*MNSTopicMessage, error) {
+	if notifyContentFormat == fc.NotifyContentFormatStream {
+		return &MNSTopicMessage{Message: string(payload)}, nil
+	}
+	message := &MNSTopicMessage{}
+	if err := decode(payload, message, "mns topic"); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// NewMNSTopicMessage creates a JSON formatted mns topic message.
+func NewMNSTopicMessage(accountID, topic, subscription, message string) *MNSTopicMessage {
+	sum := md5.Sum([]byte(message))
+	return &MNSTopicMessage{
+		TopicOwner:       accountID,
+		TopicName:        topic,
+		Subscriber:       accountID,
+		SubscriptionName: subscription,
+		MessageMD5:       strings.ToUpper(hex.EncodeToString(sum[:])),
+		Message:          message,
+		PublishTime:      time.Now().UnixNano() / int64(time.Millisecond),
+	}
+}
@@ -0,0 +1,107 @@
+package events
+
+import (
+	"strings"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// OSSEvent is the event an oss trigger delivers. One notification may carry several records.
+type OSSEvent struct {
+	Events []OSSEventRecord `json:"events"`
+}
+
+// OSSEventRecord describes a single object operation.
+type OSSEventRecord struct {
+	EventName         string               `json:"eventName"`
+	EventSource       string               `json:"eventSource"`
+	EventTime         time.Time            `json:"eventTime"`
+	EventVersion      string               `json:"eventVersion"`
+	OSS               OSSEntity            `json:"oss"`
+	Region            string               `json:"region"`
+	RequestParameters OSSRequestParameters `json:"requestParameters"`
+	ResponseElements  OSSResponseElements  `json:"responseElements"`
+	UserIdentity      OSSUserIdentity      `json:"userIdentity"`
+}
+
+// OSSEvent returns the event name in the form used by oss trigger configs, e.g. "oss:ObjectCreated:PutObject".
+func (r OSSEventRecord) OSSEvent() fc.OSSEvent {
+	if strings.HasPrefix(r.EventName, "oss:") {
+		return fc.OSSEvent(r.EventName)
+	}
+	return fc.OSSEvent("oss:" + r.EventName)
+}
+
+// OSSEntity describes the bucket and object of the record.
+type OSSEntity struct {
+	Bucket           OSSBucket `json:"bucket"`
+	Object           OSSObject `json:"object"`
+	OSSSchemaVersion string    `json:"ossSchemaVersion"`
+	RuleID           string    `json:"ruleId"`
+}
+
+// OSSBucket ...
+type OSSBucket struct {
+	Arn           string `json:"arn"`
+	Name          string `json:"name"`
+	OwnerIdentity string `json:"ownerIdentity"`
+	VirtualBucket string `json:"virtualBucket"`
+}
+
+// OSSObject ...
+type OSSObject struct {
+	DeltaSize int64  `json:"deltaSize"`
+	ETag      string `json:"eTag"`
+	Key       string `json:"key"`
+	Size      int64  `json:"size"`
+}
+
+// OSSRequestParameters ...
+type OSSRequestParameters struct {
+	SourceIPAddress string `json:"sourceIPAddress"`
+}
+
+// OSSResponseElements ...
+type OSSResponseElements struct {
+	RequestID string `json:"requestId"`
+}
+
+// OSSUserIdentity ...
+type OSSUserIdentity struct {
+	PrincipalID string `json:"principalId"`
+}
+
+// DecodeOSSEvent decodes the payload of an oss trigger invocation.
+func DecodeOSSEvent(payload []byte) (*OSSEvent, error) {
+	event := &OSSEvent{}
+	if err := decode(payload, event, "oss"); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// NewOSSEvent creates an oss event with a single record, as delivered for an operation on the object.
+func NewOSSEvent(eventName fc.OSSEvent, region, accountID, bucket, key string, size int64) *OSSEvent {
+	return &OSSEvent{Events: []OSSEventRecord{{
+		EventName:    strings.TrimPrefix(string(eventName), "oss:"),
+		EventSource:  "acs:oss",
+		EventTime:    time.Now().UTC().Truncate(time.Millisecond),
+		EventVersion: "1.0",
+		OSS: OSSEntity{
+			Bucket: OSSBucket{
+				Arn:           "acs:oss:" + region + ":" + accountID + ":" + bucket,
+				Name:          bucket,
+				OwnerIdentity: accountID,
+			},
+			Object: OSSObject{
+				DeltaSize: size,
+				Key:       key,
+				Size:      size,
+			},
+			OSSSchemaVersion: "1.0",
+		},
+		Region:       region,
+		UserIdentity: OSSUserIdentity{PrincipalID: accountID},
+	}}}
+}
@@ -0,0 +1,28 @@
+package events
+
+import "time"
+
+// TimerEvent is the event a timer trigger delivers.
+type TimerEvent struct {
+	TriggerTime time.Time `json:"triggerTime"`
+	TriggerName string    `json:"triggerName"`
+	Payload     string    `json:"payload"`
+}
+
+// DecodeTimerEvent decodes the payload of a timer trigger invocation.
+func DecodeTimerEvent(payload []byte) (*TimerEvent, error) {
+	event := &TimerEvent{}
+	if err := decode(payload, event, "timer"); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// NewTimerEvent creates a timer event fired at the given time.
+func NewTimerEvent(triggerName, payload string, triggerTime time.Time) *TimerEvent {
+	return &TimerEvent{
+		TriggerTime: triggerTime.UTC().Truncate(time.Second),
+		TriggerName: triggerName,
+		Payload:     payload,
+	}
+}
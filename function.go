@@ -23,7 +23,8 @@ type Code struct {
 	OSSObjectName *string `json:"ossObjectName"`
 	ZipFile       *string `json:"zipFile"`
 
-	err error `json:"-"`
+	zipOptions ZipOptions `json:"-"`
+	err        error      `json:"-"`
 }
 
 func NewCode() *Code {
@@ -46,9 +47,27 @@ func (c *Code) WithZipFile(zipFile []byte) *Code {
 	return c
 }
 
+// WithZipOptions sets how WithDir and WithFiles package the code; it must be called before them.
+func (c *Code) WithZipOptions(opts ZipOptions) *Code {
+	c.zipOptions = opts
+	return c
+}
+
+// WithIncludes limits the files WithDir and WithFiles package to those matching the gitignore style patterns.
+func (c *Code) WithIncludes(patterns ...string) *Code {
+	c.zipOptions.Includes = append(c.zipOptions.Includes, patterns...)
+	return c
+}
+
+// WithExcludes leaves the files matching the gitignore style patterns out of WithDir and WithFiles.
+func (c *Code) WithExcludes(patterns ...string) *Code {
+	c.zipOptions.Excludes = append(c.zipOptions.Excludes, patterns...)
+	return c
+}
+
 func (c *Code) WithDir(dir string) *Code {
 	zipped := &bytes.Buffer{}
-	err := ZipDir(dir, zipped)
+	err := ZipDirWithOptions(dir, zipped, c.zipOptions)
 	if err != nil {
 		c.err = err
 		return c
@@ -60,7 +79,7 @@ func (c *Code) WithDir(dir string) *Code {
 }
 
 func (c *Code) WithFiles(files ...string) *Code {
-	zipFile, err := TmpZip(files)
+	zipFile, err := TmpZipWithOptions(files, c.zipOptions)
 	if err != nil {
 		c.err = err
 		return c
@@ -76,6 +95,18 @@ func (c *Code) WithFiles(files ...string) *Code {
 	return c
 }
 
+// ContentHash returns the hex encoded SHA-256 of the zip file
+func (c *Code) ContentHash() (string, error) {
+	if c.ZipFile == nil {
+		return "", fmt.Errorf("Zip file is required but not provided")
+	}
+	data, err := base64.StdEncoding.DecodeString(*c.ZipFile)
+	if err != nil {
+		return "", err
+	}
+	return ZipContentHash(data), nil
+}
+
 // CustomContainerConfig defines the code docker image
 type CustomContainerConfig struct {
 	Image            *string `json:"image"`
@@ -139,7 +170,8 @@ type FunctionCreateObject struct {
 	InstanceType          *string                `json:"instanceType"`
 	Layers                []string               `json:"layers"`
 
-	err error `json:"-"`
+	err     error    `json:"-"`
+	secrets []string `json:"-"`
 }
 
 func NewCreateFunctionInput(serviceName string) *CreateFunctionInput {
@@ -269,6 +301,8 @@ func (i *CreateFunctionInput) Validate() error {
 type CreateFunctionOutput struct {
 	Header http.Header
 	functionMetadata
+
+	secrets []string
 }
 
 func (o CreateFunctionOutput) GetRequestID() string {
@@ -280,6 +314,7 @@ func (o CreateFunctionOutput) GetEtag() string {
 }
 
 func (o CreateFunctionOutput) String() string {
+	o.EnvironmentVariables = RedactEnvironmentVariables(o.EnvironmentVariables, o.secrets...)
 	b, err := json.MarshalIndent(o, "", printIndent)
 	if err != nil {
 		return ""
@@ -304,7 +339,8 @@ type FunctionUpdateObject struct {
 	InstanceType          *string                `json:"instanceType"`
 	Layers                []string               `json:"layers"`
 
-	err error `json:"-"`
+	err     error    `json:"-"`
+	secrets []string `json:"-"`
 }
 
 type UpdateFunctionInput struct {
@@ -441,9 +477,12 @@ func (i *UpdateFunctionInput) Validate() error {
 type UpdateFunctionOutput struct {
 	Header http.Header
 	functionMetadata
+
+	secrets []string
 }
 
 func (o UpdateFunctionOutput) String() string {
+	o.EnvironmentVariables = RedactEnvironmentVariables(o.EnvironmentVariables, o.secrets...)
 	b, err := json.MarshalIndent(o, "", printIndent)
 	if err != nil {
 		return ""
@@ -512,6 +551,8 @@ func (i *GetFunctionInput) Validate() error {
 type GetFunctionOutput struct {
 	Header http.Header
 	functionMetadata
+
+	secrets []string
 }
 
 func (o GetFunctionOutput) GetEtag() string {
@@ -523,6 +564,7 @@ func (o GetFunctionOutput) GetRequestID() string {
 }
 
 func (o GetFunctionOutput) String() string {
+	o.EnvironmentVariables = RedactEnvironmentVariables(o.EnvironmentVariables, o.secrets...)
 	b, err := json.MarshalIndent(o, "", printIndent)
 	if err != nil {
 		return ""
@@ -905,3 +947,19 @@ func (o InvokeFunctionOutput) GetLogResult() (string, error) {
 	}
 	return string(bytes), nil
 }
+
+// GetInvocationLog returns the LogResult of the invocation parsed into records and the billing summary
+func (o InvokeFunctionOutput) GetInvocationLog() (*InvocationLog, error) {
+	logResult, err := o.GetLogResult()
+	if err != nil {
+		return nil, err
+	}
+	log, err := ParseLogResult(logResult)
+	if err != nil {
+		return nil, err
+	}
+	if log.RequestID == "" {
+		log.RequestID = o.GetRequestID()
+	}
+	return log, nil
+}
@@ -0,0 +1,106 @@
+package fc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// DefaultGoBuildArch is the architecture Go functions are built for.
+	DefaultGoBuildArch = "amd64"
+
+	// DefaultGoBuildBinary is the name of the built binary in the code package.
+	DefaultGoBuildBinary = "main"
+)
+
+// GoBuildOptions defines how a Go package is built for the custom runtime.
+type GoBuildOptions struct {
+	// Dir is the directory the go command runs in, e.g. the module root. Defaults to the current directory.
+	Dir string
+	// GOARCH defaults to DefaultGoBuildArch.
+	GOARCH string
+	// BinaryName defaults to DefaultGoBuildBinary.
+	BinaryName string
+	// CAPort is the function's CAPort the bootstrap makes the binary listen on. Defaults to DefaultCustomRuntimePort.
+	CAPort int32
+	// Tags are the build tags.
+	Tags []string
+	// Ldflags are passed to the linker after the flags stripping the binary.
+	Ldflags string
+	// Env is added to the environment of the go command.
+	Env []string
+	// GoCommand is the go command to run. Defaults to "go".
+	GoCommand string
+}
+
+// GoBootstrap returns the custom runtime bootstrap script running the binary with FC_SERVER_PORT set
+// to the port.
+func GoBootstrap(binaryName string, port int32) string {
+	return fmt.Sprintf("#!/bin/sh\nexport FC_SERVER_PORT=%d\nexec \"$(dirname \"$0\")/%s\" \"$@\"\n", port, binaryName)
+}
+
+// GoBuild cross-compiles the Go package for linux with CGO disabled and a stripped binary into
+// outputDir, next to a bootstrap script running it.
+func GoBuild(pkgPath, outputDir string, opts GoBuildOptions) error {
+	if pkgPath == "" {
+		return fmt.Errorf("Package path is required but not provided")
+	}
+	if opts.GOARCH == "" {
+		opts.GOARCH = DefaultGoBuildArch
+	}
+	if opts.BinaryName == "" {
+		opts.BinaryName = DefaultGoBuildBinary
+	}
+	if opts.CAPort == 0 {
+		opts.CAPort = DefaultCustomRuntimePort
+	}
+	if opts.GoCommand == "" {
+		opts.GoCommand = "go"
+	}
+	if opts.BinaryName == "bootstrap" || strings.ContainsAny(opts.BinaryName, `/\"$`) {
+		return fmt.Errorf("invalid binary name %q", opts.BinaryName)
+	}
+	outputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return err
+	}
+
+	ldflags := "-s -w"
+	if opts.Ldflags != "" {
+		ldflags += " " + opts.Ldflags
+	}
+	args := []string{"build", "-trimpath", "-ldflags", ldflags, "-o", filepath.Join(outputDir, opts.BinaryName)}
+	if len(opts.Tags) > 0 {
+		args = append(args, "-tags", strings.Join(opts.Tags, ","))
+	}
+	args = append(args, pkgPath)
+	cmd := exec.Command(opts.GoCommand, args...)
+	cmd.Dir = opts.Dir
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0", "GOOS=linux", "GOARCH="+opts.GOARCH)
+	cmd.Env = append(cmd.Env, opts.Env...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to build %s: %v\n%s", pkgPath, err, output)
+	}
+
+	bootstrap := GoBootstrap(opts.BinaryName, opts.CAPort)
+	return ioutil.WriteFile(filepath.Join(outputDir, "bootstrap"), []byte(bootstrap), 0755)
+}
+
+// WithGoBuild builds the Go package with GoBuild and packages the result like WithDir.
+func (c *Code) WithGoBuild(pkgPath string, opts GoBuildOptions) *Code {
+	dir, err := ioutil.TempDir("", "fc_go_build_")
+	if err != nil {
+		c.err = err
+		return c
+	}
+	defer os.RemoveAll(dir)
+	if err := GoBuild(pkgPath, dir, opts); err != nil {
+		c.err = err
+		return c
+	}
+	return c.WithDir(dir)
+}
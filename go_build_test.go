@@ -0,0 +1,92 @@
+package fc
+
+import (
+	"archive/zip"
+	"bytes"
+	"debug/elf"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type GoBuildTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestGoBuild(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+	suite.Run(t, new(GoBuildTestSuite))
+}
+
+func (s *GoBuildTestSuite) SetupTest() {
+	assert := s.Require()
+
+	dir, err := ioutil.TempDir("", "fc_go_build_test_")
+	assert.Nil(err)
+	s.dir = dir
+	assert.Nil(os.MkdirAll(filepath.Join(dir, "cmd", "hello"), 0755))
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/hello\n\ngo 1.16\n"), 0644))
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "cmd", "hello", "main.go"), []byte("package main\n\nfunc main() { println(\"hello\") }\n"), 0644))
+}
+
+func (s *GoBuildTestSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+}
+
+func (s *GoBuildTestSuite) TestGoBootstrap() {
+	s.Require().Equal("#!/bin/sh\nexport FC_SERVER_PORT=8080\nexec \"$(dirname \"$0\")/main\" \"$@\"\n", GoBootstrap("main", 8080))
+}
+
+func (s *GoBuildTestSuite) TestWithGoBuild() {
+	assert := s.Require()
+
+	code := NewCode().WithZipOptions(ZipOptions{Deterministic: true}).
+		WithGoBuild("./cmd/hello", GoBuildOptions{Dir: s.dir, CAPort: 8080})
+	assert.Nil(code.err)
+	data, err := base64.StdEncoding.DecodeString(*code.ZipFile)
+	assert.Nil(err)
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.Nil(err)
+
+	files := map[string]*zip.File{}
+	for _, f := range reader.File {
+		files[f.Name] = f
+	}
+	assert.Len(files, 2)
+	assert.Equal(os.FileMode(0755), files["bootstrap"].Mode())
+	assert.Equal(os.FileMode(0755), files["main"].Mode())
+
+	r, err := files["bootstrap"].Open()
+	assert.Nil(err)
+	bootstrap, err := ioutil.ReadAll(r)
+	assert.Nil(err)
+	assert.Equal(GoBootstrap("main", 8080), string(bootstrap))
+
+	r, err = files["main"].Open()
+	assert.Nil(err)
+	binary, err := ioutil.ReadAll(r)
+	assert.Nil(err)
+	exe, err := elf.NewFile(bytes.NewReader(binary))
+	assert.Nil(err)
+	assert.Equal(elf.EM_X86_64, exe.Machine)
+	assert.Nil(exe.Section(".symtab"))
+}
+
+func (s *GoBuildTestSuite) TestBuildFailure() {
+	assert := s.Require()
+
+	code := NewCode().WithGoBuild("./cmd/missing", GoBuildOptions{Dir: s.dir})
+	assert.NotNil(code.err)
+	assert.Contains(code.err.Error(), "failed to build ./cmd/missing")
+
+	assert.NotNil(GoBuild("", s.dir, GoBuildOptions{}))
+	assert.NotNil(GoBuild("./cmd/hello", s.dir, GoBuildOptions{BinaryName: "bootstrap"}))
+}
@@ -1,5 +1,10 @@
 package fc
 
+import (
+	"fmt"
+	"net/http"
+)
+
 const (
 	// AuthAnonymous defines http trigger without authorized
 	AuthAnonymous = "anonymous"
@@ -36,3 +41,35 @@ func (t *HTTPTriggerConfig) WithAuthType(authType string) *HTTPTriggerConfig {
 	t.AuthType = &authType
 	return t
 }
+
+// Validate checks the auth type and methods
+func (t *HTTPTriggerConfig) Validate() error {
+	authType := ""
+	if t.AuthType != nil {
+		authType = *t.AuthType
+	}
+	return validateHTTPTrigger(authType, t.Methods)
+}
+
+// validateHTTPTrigger checks the auth type, if set, and the http methods
+func validateHTTPTrigger(authType string, methods []string) error {
+	switch authType {
+	case "", AuthAnonymous, AuthFunction:
+	default:
+		return fmt.Errorf("Auth type %q is invalid, must be %s or %s", authType, AuthAnonymous, AuthFunction)
+	}
+	seen := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		switch m {
+		case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete,
+			http.MethodHead, http.MethodPatch, http.MethodOptions:
+		default:
+			return fmt.Errorf("HTTP method %q is invalid", m)
+		}
+		if seen[m] {
+			return fmt.Errorf("HTTP method %q is duplicated", m)
+		}
+		seen[m] = true
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package fc
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFileName is the file in a code directory listing, in gitignore syntax, the files not to package.
+const IgnoreFileName = ".fcignore"
+
+// IgnoreMatcher matches slash separated relative paths against gitignore style patterns.
+// Blank lines and lines starting with # are skipped, a leading ! negates the pattern, a trailing /
+// matches only directories, a pattern containing a / is relative to the root and ** matches any
+// number of directories. The last matching pattern wins, and nothing inside a matched directory can
+// be re-included.
+type IgnoreMatcher struct {
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// NewIgnoreMatcher parses the patterns, one per line as in a gitignore file.
+func NewIgnoreMatcher(patterns ...string) (*IgnoreMatcher, error) {
+	m := &IgnoreMatcher{}
+	for _, line := range patterns {
+		p, ok, err := parseIgnorePattern(line)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+	return m, nil
+}
+
+// LoadIgnoreFile parses the IgnoreFileName file in dir. A missing file matches nothing.
+func LoadIgnoreFile(dir string) (*IgnoreMatcher, error) {
+	lines, err := readIgnoreFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewIgnoreMatcher(lines...)
+}
+
+func readIgnoreFile(dir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(dir, IgnoreFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// Match reports whether the path, or any directory containing it, is matched.
+func (m *IgnoreMatcher) Match(path string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	path = strings.Trim(filepath.ToSlash(path), "/")
+	if path == "" || path == "." {
+		return false
+	}
+	for i := strings.Index(path, "/"); i >= 0; i = nextSlash(path, i) {
+		if m.matchOne(path[:i], true) {
+			return true
+		}
+	}
+	return m.matchOne(path, isDir)
+}
+
+func nextSlash(path string, i int) int {
+	j := strings.Index(path[i+1:], "/")
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
+
+func (m *IgnoreMatcher) matchOne(path string, isDir bool) bool {
+	matched := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(path) {
+			matched = !p.negate
+		}
+	}
+	return matched
+}
+
+func parseIgnorePattern(line string) (ignorePattern, bool, error) {
+	p := ignorePattern{}
+	line = strings.TrimSuffix(line, "\r")
+	// trailing spaces are ignored unless escaped
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return p, false, nil
+	}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return p, false, nil
+	}
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	expr := globToRegexp(line)
+	if anchored {
+		expr = "^" + expr + "$"
+	} else {
+		expr = "^(?:.*/)?" + expr + "$"
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return p, false, fmt.Errorf("invalid ignore pattern %q: %v", line, err)
+	}
+	p.re = re
+	return p, true, nil
+}
+
+// globToRegexp translates a gitignore glob into a regular expression without anchors.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' && (i == 0 || glob[i-1] == '/') {
+				switch {
+				case i+2 == len(glob):
+					b.WriteString(".*")
+					i++
+					continue
+				case glob[i+2] == '/':
+					b.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+			}
+			b.WriteString("[^/]*")
+			for i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.Index(glob[i+1:], "]")
+			if end == 0 && i+2 < len(glob) {
+				// a ] right after [ is part of the class
+				if next := strings.Index(glob[i+2:], "]"); next >= 0 {
+					end = next + 1
+				} else {
+					end = -1
+				}
+			}
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	return b.String()
+}
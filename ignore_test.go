@@ -0,0 +1,174 @@
+package fc
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type IgnoreTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestIgnore(t *testing.T) {
+	suite.Run(t, new(IgnoreTestSuite))
+}
+
+func (s *IgnoreTestSuite) SetupTest() {
+	assert := s.Require()
+
+	dir, err := ioutil.TempDir("", "fc_ignore_")
+	assert.Nil(err)
+	s.dir = dir
+	for _, name := range []string{
+		"main.py", "secret.env", "keep.env",
+		".git/config", "test/fixture.json", "lib/util.py", "lib/util_test.py",
+		"lib/build/out.txt", "docs/build/index.html",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(ioutil.WriteFile(path, []byte(name), 0644))
+	}
+	ignore := "# local files\n.git/\n/test\n*.env\n!keep.env\n**/*_test.py\nlib/build/\n"
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, IgnoreFileName), []byte(ignore), 0644))
+}
+
+func (s *IgnoreTestSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+}
+
+func (s *IgnoreTestSuite) TestMatcher() {
+	assert := s.Require()
+
+	m, err := NewIgnoreMatcher("# comment", "", "*.log", "!important.log", "build/", "/root.txt",
+		"a/**/z", "docs/**", "file[0-9].txt", `\#hash`, "trailing   ")
+	assert.Nil(err)
+
+	assert.True(m.Match("x.log", false))
+	assert.True(m.Match("deep/dir/x.log", false))
+	assert.False(m.Match("important.log", false))
+	assert.True(m.Match("build", true))
+	assert.False(m.Match("build", false))
+	assert.True(m.Match("src/build/out.o", false))
+	assert.True(m.Match("root.txt", false))
+	assert.False(m.Match("sub/root.txt", false))
+	assert.True(m.Match("a/z", false))
+	assert.True(m.Match("a/b/c/z", false))
+	assert.False(m.Match("docs", true))
+	assert.True(m.Match("docs/readme.md", false))
+	assert.True(m.Match("file1.txt", false))
+	assert.False(m.Match("fileA.txt", false))
+	assert.True(m.Match("#hash", false))
+	assert.True(m.Match("trailing", false))
+	assert.False(m.Match("main.go", false))
+
+	// nothing inside an ignored directory can be re-included
+	m, err = NewIgnoreMatcher("vendor/", "!vendor/keep.go")
+	assert.Nil(err)
+	assert.True(m.Match("vendor/keep.go", false))
+
+	var nilMatcher *IgnoreMatcher
+	assert.False(nilMatcher.Match("x", false))
+}
+
+func (s *IgnoreTestSuite) TestLoadIgnoreFile() {
+	assert := s.Require()
+
+	m, err := LoadIgnoreFile(s.dir)
+	assert.Nil(err)
+	assert.True(m.Match("secret.env", false))
+
+	m, err = LoadIgnoreFile(filepath.Join(s.dir, "lib"))
+	assert.Nil(err)
+	assert.False(m.Match("secret.env", false))
+}
+
+func (s *IgnoreTestSuite) TestListPackageFiles() {
+	assert := s.Require()
+
+	files, err := ListPackageFiles(s.dir, ZipOptions{})
+	assert.Nil(err)
+	assert.Equal([]string{
+		IgnoreFileName, "docs/", "docs/build/", "docs/build/index.html",
+		"keep.env", "lib/", "lib/util.py", "main.py",
+	}, files)
+
+	files, err = ListPackageFiles(s.dir, ZipOptions{DisableIgnoreFile: true, Excludes: []string{".*", "docs/"}})
+	assert.Nil(err)
+	assert.Equal([]string{
+		"keep.env", "lib/", "lib/build/", "lib/build/out.txt", "lib/util.py", "lib/util_test.py",
+		"main.py", "secret.env", "test/", "test/fixture.json",
+	}, files)
+
+	files, err = ListPackageFiles(s.dir, ZipOptions{Includes: []string{"*.py", "docs/"}})
+	assert.Nil(err)
+	assert.Equal([]string{"docs/", "docs/build/", "docs/build/index.html", "lib/util.py", "main.py"}, files)
+}
+
+func zipNames(data []byte) []string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		panic(err)
+	}
+	names := []string{}
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *IgnoreTestSuite) TestZipDirHonorsIgnoreFile() {
+	assert := s.Require()
+
+	expected, err := ListPackageFiles(s.dir, ZipOptions{})
+	assert.Nil(err)
+
+	// ZipDir keeps its entry for the root directory
+	buf := &bytes.Buffer{}
+	assert.Nil(ZipDir(s.dir, buf))
+	assert.Equal(append([]string{"./"}, expected...), zipNames(buf.Bytes()))
+
+	buf.Reset()
+	assert.Nil(ZipDirWithOptions(s.dir, buf, ZipOptions{Deterministic: true}))
+	assert.Equal(expected, zipNames(buf.Bytes()))
+
+	tmp, err := TempZipDir(s.dir)
+	assert.Nil(err)
+	defer os.Remove(tmp)
+	data, err := ioutil.ReadFile(tmp)
+	assert.Nil(err)
+	assert.Equal(expected, zipNames(data))
+}
+
+func (s *IgnoreTestSuite) TestWithFilesHonorsIgnoreFile() {
+	assert := s.Require()
+
+	for _, opts := range []ZipOptions{{}, {Deterministic: true}} {
+		code := NewCode().WithZipOptions(opts).WithExcludes("*.html").
+			WithFiles(s.dir, filepath.Join(s.dir, "lib", "util_test.py"))
+		assert.Nil(code.err)
+		data, err := base64.StdEncoding.DecodeString(*code.ZipFile)
+		assert.Nil(err)
+		base := filepath.Base(s.dir) + "/"
+		assert.Equal([]string{
+			base, base + IgnoreFileName, base + "docs/", base + "docs/build/",
+			base + "keep.env", base + "lib/", base + "lib/util.py", base + "main.py",
+			"util_test.py",
+		}, zipNames(data))
+	}
+
+	code := NewCode().WithZipOptions(ZipOptions{Deterministic: true}).WithIncludes("lib/").WithDir(s.dir)
+	assert.Nil(code.err)
+	data, err := base64.StdEncoding.DecodeString(*code.ZipFile)
+	assert.Nil(err)
+	assert.Equal([]string{"lib/", "lib/util.py"}, zipNames(data))
+}
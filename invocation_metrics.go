@@ -0,0 +1,281 @@
+package fc
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HTTPHeaderInvocationDuration is the header key for the function execution time in milliseconds
+	HTTPHeaderInvocationDuration = "X-Fc-Invocation-Duration"
+
+	// HTTPHeaderMaxMemoryUsage is the header key for the max memory used by the invocation in MB
+	HTTPHeaderMaxMemoryUsage = "X-Fc-Max-Memory-Usage"
+
+	// HTTPHeaderInstanceID is the header key for the ID of the instance serving the invocation
+	HTTPHeaderInstanceID = "X-Fc-Instance-Id"
+)
+
+// InvocationMetrics is the performance metadata of one invocation. ColdStart is set by
+// ColdStartTracker.GetInvocationMetrics.
+type InvocationMetrics struct {
+	Duration         time.Duration
+	MaxMemoryUsageMB float64
+	InstanceID       string
+	ColdStart        bool
+}
+
+// GetInvocationDuration returns the function execution time, false when the header is absent
+func (o InvokeFunctionOutput) GetInvocationDuration() (time.Duration, bool) {
+	return headerMilliseconds(o.Header.Get(HTTPHeaderInvocationDuration))
+}
+
+// GetMaxMemoryUsage returns the max memory used by the invocation in MB, false when the header is absent
+func (o InvokeFunctionOutput) GetMaxMemoryUsage() (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(o.Header.Get(HTTPHeaderMaxMemoryUsage)), 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// GetInstanceID returns the ID of the instance serving the invocation
+func (o InvokeFunctionOutput) GetInstanceID() string {
+	return o.Header.Get(HTTPHeaderInstanceID)
+}
+
+// GetInvocationMetrics returns all performance metadata of the invocation
+func (o InvokeFunctionOutput) GetInvocationMetrics() InvocationMetrics {
+	duration, _ := o.GetInvocationDuration()
+	memory, _ := o.GetMaxMemoryUsage()
+	return InvocationMetrics{
+		Duration:         duration,
+		MaxMemoryUsageMB: memory,
+		InstanceID:       o.GetInstanceID(),
+	}
+}
+
+// ColdStartTracker detects cold starts from the X-Fc-Instance-Id header. Function Compute does not
+// document a cold start header, but an instance serves its first invocation right after it starts,
+// so the first invocation seen from an instance is counted as its cold start. Instances started
+// before tracking began are counted on their first invocation too. It is safe for concurrent use.
+type ColdStartTracker struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+func NewColdStartTracker() *ColdStartTracker {
+	return &ColdStartTracker{seen: map[string]struct{}{}}
+}
+
+// IsColdStart reports whether the output is the first seen from its instance, false when the
+// instance ID header is absent.
+func (t *ColdStartTracker) IsColdStart(output *InvokeFunctionOutput) bool {
+	return t.seeInstance(output.GetInstanceID())
+}
+
+// GetInvocationMetrics returns the performance metadata of the invocation with ColdStart set by
+// IsColdStart.
+func (t *ColdStartTracker) GetInvocationMetrics(output *InvokeFunctionOutput) InvocationMetrics {
+	m := output.GetInvocationMetrics()
+	m.ColdStart = t.seeInstance(m.InstanceID)
+	return m
+}
+
+func (t *ColdStartTracker) seeInstance(id string) bool {
+	if id == "" {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.seen[id]; ok {
+		return false
+	}
+	t.seen[id] = struct{}{}
+	return true
+}
+
+func headerMilliseconds(v string) (time.Duration, bool) {
+	ms, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Duration(ms * float64(time.Millisecond)), true
+}
+
+// DefaultLatencyBuckets are the upper bounds used when a histogram is created without buckets
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond,
+	100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond,
+	time.Second, 2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// HistogramBucket counts the invocations with a duration up to UpperBound;
+// the last bucket has an infinite upper bound.
+type HistogramBucket struct {
+	UpperBound time.Duration
+	Count      int64
+}
+
+// LatencyHistogram aggregates invocation metrics across many invocations in fixed buckets, so its
+// size does not grow with the number of invocations. It is safe for concurrent use.
+type LatencyHistogram struct {
+	mu         sync.Mutex
+	bounds     []time.Duration
+	counts     []int64
+	count      int64
+	sum        time.Duration
+	min, max   time.Duration
+	maxMemory  float64
+	instances  map[string]struct{}
+	coldStarts int64
+	tracker    *ColdStartTracker
+}
+
+// NewLatencyHistogram returns a histogram with the given bucket upper bounds, or DefaultLatencyBuckets.
+func NewLatencyHistogram(bounds ...time.Duration) *LatencyHistogram {
+	if len(bounds) == 0 {
+		bounds = DefaultLatencyBuckets
+	}
+	sorted := append([]time.Duration(nil), bounds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return &LatencyHistogram{
+		bounds:    sorted,
+		counts:    make([]int64, len(sorted)+1),
+		instances: map[string]struct{}{},
+		tracker:   NewColdStartTracker(),
+	}
+}
+
+// Record adds the metrics of an invocation output, counting a cold start the first time an instance
+// is seen as ColdStartTracker does.
+func (h *LatencyHistogram) Record(output *InvokeFunctionOutput) {
+	h.Add(h.tracker.GetInvocationMetrics(output))
+}
+
+// Add adds the metrics of an invocation.
+func (h *LatencyHistogram) Add(m InvocationMetrics) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i := sort.Search(len(h.bounds), func(i int) bool { return m.Duration <= h.bounds[i] })
+	h.counts[i]++
+	if h.count == 0 || m.Duration < h.min {
+		h.min = m.Duration
+	}
+	if h.count == 0 || m.Duration > h.max {
+		h.max = m.Duration
+	}
+	h.count++
+	h.sum += m.Duration
+	if m.MaxMemoryUsageMB > h.maxMemory {
+		h.maxMemory = m.MaxMemoryUsageMB
+	}
+	if m.InstanceID != "" {
+		h.instances[m.InstanceID] = struct{}{}
+	}
+	if m.ColdStart {
+		h.coldStarts++
+	}
+}
+
+// Count returns the number of invocations recorded.
+func (h *LatencyHistogram) Count() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// Instances returns the number of distinct instances that served invocations.
+func (h *LatencyHistogram) Instances() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.instances)
+}
+
+// ColdStarts returns the number of invocations recorded as cold starts.
+func (h *LatencyHistogram) ColdStarts() int64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.coldStarts
+}
+
+// MaxMemoryUsageMB returns the highest max memory usage recorded.
+func (h *LatencyHistogram) MaxMemoryUsageMB() float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.maxMemory
+}
+
+// Buckets returns the count of each bucket.
+func (h *LatencyHistogram) Buckets() []HistogramBucket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	buckets := make([]HistogramBucket, len(h.counts))
+	for i, count := range h.counts {
+		bound := time.Duration(math.MaxInt64)
+		if i < len(h.bounds) {
+			bound = h.bounds[i]
+		}
+		buckets[i] = HistogramBucket{UpperBound: bound, Count: count}
+	}
+	return buckets
+}
+
+// Mean returns the mean duration.
+func (h *LatencyHistogram) Mean() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.count)
+}
+
+// Percentile estimates the duration below which p percent (0-100) of the invocations fall, using
+// nearest rank. It returns the upper bound of the bucket holding the rank, limited to the range of
+// the recorded durations.
+func (h *LatencyHistogram) Percentile(p float64) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.count == 0 {
+		return 0
+	}
+	if p <= 0 {
+		return h.min
+	}
+	rank := int64(math.Ceil(p / 100 * float64(h.count)))
+	var seen int64
+	for i, count := range h.counts {
+		seen += count
+		if seen < rank || count == 0 {
+			continue
+		}
+		if i == len(h.bounds) || h.bounds[i] > h.max {
+			return h.max
+		}
+		if h.bounds[i] < h.min {
+			return h.min
+		}
+		return h.bounds[i]
+	}
+	return h.max
+}
+
+func (h *LatencyHistogram) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "count=%d instances=%d cold_starts=%d mean=%s p50=%s p90=%s p99=%s max_memory=%.2fMB\n",
+		h.Count(), h.Instances(), h.ColdStarts(), h.Mean(), h.Percentile(50), h.Percentile(90), h.Percentile(99), h.MaxMemoryUsageMB())
+	for _, bucket := range h.Buckets() {
+		bound := "+Inf"
+		if bucket.UpperBound != time.Duration(math.MaxInt64) {
+			bound = bucket.UpperBound.String()
+		}
+		fmt.Fprintf(&b, "le=%s %d\n", bound, bucket.Count)
+	}
+	return b.String()
+}
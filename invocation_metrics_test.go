@@ -0,0 +1,106 @@
+package fc
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type InvocationMetricsTestSuite struct {
+	suite.Suite
+}
+
+func TestInvocationMetrics(t *testing.T) {
+	suite.Run(t, new(InvocationMetricsTestSuite))
+}
+
+func (s *InvocationMetricsTestSuite) TestAccessors() {
+	assert := s.Require()
+
+	header := http.Header{}
+	header.Set(HTTPHeaderInvocationDuration, "12.5")
+	header.Set(HTTPHeaderMaxMemoryUsage, "31.2")
+	header.Set(HTTPHeaderInstanceID, "c-1")
+	output := InvokeFunctionOutput{Header: header}
+
+	assert.Equal(InvocationMetrics{
+		Duration:         12500 * time.Microsecond,
+		MaxMemoryUsageMB: 31.2,
+		InstanceID:       "c-1",
+	}, output.GetInvocationMetrics())
+
+	empty := InvokeFunctionOutput{Header: http.Header{}}
+	_, ok := empty.GetInvocationDuration()
+	assert.False(ok)
+	_, ok = empty.GetMaxMemoryUsage()
+	assert.False(ok)
+}
+
+func (s *InvocationMetricsTestSuite) TestColdStartTracker() {
+	assert := s.Require()
+
+	output := func(instanceID string) *InvokeFunctionOutput {
+		header := http.Header{}
+		header.Set(HTTPHeaderInstanceID, instanceID)
+		return &InvokeFunctionOutput{Header: header}
+	}
+	tracker := NewColdStartTracker()
+	assert.True(tracker.IsColdStart(output("c-1")))
+	assert.False(tracker.IsColdStart(output("c-1")))
+	assert.Equal(InvocationMetrics{InstanceID: "c-2", ColdStart: true}, tracker.GetInvocationMetrics(output("c-2")))
+	assert.False(tracker.GetInvocationMetrics(output("c-2")).ColdStart)
+	assert.False(tracker.IsColdStart(output("")))
+	assert.False(tracker.IsColdStart(&InvokeFunctionOutput{Header: http.Header{}}))
+}
+
+func (s *InvocationMetricsTestSuite) TestLatencyHistogram() {
+	assert := s.Require()
+
+	h := NewLatencyHistogram(100*time.Millisecond, 10*time.Millisecond)
+	for i := 1; i <= 10; i++ {
+		h.Add(InvocationMetrics{Duration: time.Duration(i*20) * time.Millisecond, InstanceID: "c-1", MaxMemoryUsageMB: float64(i)})
+	}
+	header := http.Header{}
+	header.Set(HTTPHeaderInvocationDuration, "5")
+	header.Set(HTTPHeaderInstanceID, "c-2")
+	h.Record(&InvokeFunctionOutput{Header: header})
+
+	assert.Equal(int64(11), h.Count())
+	assert.Equal(2, h.Instances())
+	assert.Equal(int64(1), h.ColdStarts())
+	assert.Equal(10.0, h.MaxMemoryUsageMB())
+	assert.Equal([]HistogramBucket{
+		{UpperBound: 10 * time.Millisecond, Count: 1},
+		{UpperBound: 100 * time.Millisecond, Count: 5},
+		{UpperBound: time.Duration(math.MaxInt64), Count: 5},
+	}, h.Buckets())
+	assert.Equal(5*time.Millisecond, h.Percentile(0))
+	assert.Equal(10*time.Millisecond, h.Percentile(9))
+	assert.Equal(100*time.Millisecond, h.Percentile(50))
+	assert.Equal(200*time.Millisecond, h.Percentile(60))
+	assert.Equal(200*time.Millisecond, h.Percentile(100))
+	assert.Equal(time.Duration(1105)*time.Millisecond/11, h.Mean())
+	assert.True(strings.Contains(h.String(), "le=+Inf 5"))
+
+	assert.Equal(time.Duration(0), NewLatencyHistogram().Percentile(99))
+
+	// Record counts the first invocation of each instance, Add the metrics marked as cold starts
+	cold := NewLatencyHistogram()
+	cold.Record(&InvokeFunctionOutput{Header: header})
+	cold.Record(&InvokeFunctionOutput{Header: header})
+	cold.Add(InvocationMetrics{InstanceID: "c-3", ColdStart: true})
+	cold.Add(InvocationMetrics{InstanceID: "c-4"})
+	assert.Equal(int64(2), cold.ColdStarts())
+	assert.Contains(cold.String(), "cold_starts=2")
+
+	// estimates stay within the recorded range
+	narrow := NewLatencyHistogram(time.Second)
+	narrow.Add(InvocationMetrics{Duration: 30 * time.Millisecond})
+	narrow.Add(InvocationMetrics{Duration: 40 * time.Millisecond})
+	assert.Equal(40*time.Millisecond, narrow.Percentile(50))
+	assert.Equal(30*time.Millisecond, narrow.Percentile(0))
+}
@@ -0,0 +1,277 @@
+package layer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// DefaultMaxUnzippedSize is the largest unzipped size of a layer.
+const DefaultMaxUnzippedSize int64 = 500 << 20
+
+// Client is the subset of fc.Client the builder uses.
+type Client interface {
+	PublishLayerVersion(input *fc.PublishLayerVersionInput) (*fc.PublishLayerVersionOutput, error)
+}
+
+// Source is a directory whose content is placed under Prefix in the layer.
+type Source struct {
+	Dir    string
+	Prefix string
+
+	// byRuntime places the content in the directory of the compatible runtimes.
+	byRuntime bool
+}
+
+// Builder lays out directories in a layer and publishes it.
+type Builder struct {
+	Client            Client
+	LayerName         string
+	Description       string
+	CompatibleRuntime []string
+	Sources           []Source
+	ZipOptions        fc.ZipOptions
+	MaxUnzippedSize   int64
+	Uploader          *fc.CodeUploader
+}
+
+// New returns a builder for a version of the layer.
+func New(client Client, layerName string) *Builder {
+	return &Builder{Client: client, LayerName: layerName, MaxUnzippedSize: DefaultMaxUnzippedSize}
+}
+
+func (b *Builder) WithDescription(description string) *Builder {
+	b.Description = description
+	return b
+}
+
+func (b *Builder) WithCompatibleRuntime(runtimes ...string) *Builder {
+	b.CompatibleRuntime = append(b.CompatibleRuntime, runtimes...)
+	return b
+}
+
+// WithDir places the content of the directory where the compatible runtimes load dependencies from,
+// e.g. python/ for Python runtimes. All compatible runtimes must use the same directory.
+func (b *Builder) WithDir(dir string) *Builder {
+	b.Sources = append(b.Sources, Source{Dir: dir, byRuntime: true})
+	return b
+}
+
+// WithDirAt places the content of the directory under the prefix, e.g. PrefixLib for shared
+// libraries. An empty prefix places it at the layer root.
+func (b *Builder) WithDirAt(dir, prefix string) *Builder {
+	b.Sources = append(b.Sources, Source{Dir: dir, Prefix: strings.Trim(prefix, "/")})
+	return b
+}
+
+// WithZipOptions sets how the directories are packaged, including ignore patterns.
+func (b *Builder) WithZipOptions(opts fc.ZipOptions) *Builder {
+	b.ZipOptions = opts
+	return b
+}
+
+// WithMaxUnzippedSize sets the largest unzipped size of the layer.
+func (b *Builder) WithMaxUnzippedSize(size int64) *Builder {
+	b.MaxUnzippedSize = size
+	return b
+}
+
+// WithUploader publishes the layer through the uploader, so packages too large to be sent inline
+// are uploaded to the temporary bucket.
+func (b *Builder) WithUploader(uploader *fc.CodeUploader) *Builder {
+	b.Uploader = uploader
+	return b
+}
+
+// Validate ...
+func (b *Builder) Validate() error {
+	if b.LayerName == "" {
+		return fmt.Errorf("Layer name is required but not provided")
+	}
+	if len(b.CompatibleRuntime) == 0 {
+		return fmt.Errorf("Compatible runtime is required but not provided")
+	}
+	if len(b.Sources) == 0 {
+		return fmt.Errorf("Source directory is required but not provided")
+	}
+	for _, s := range b.Sources {
+		if _, err := b.prefix(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *Builder) prefix(s Source) (string, error) {
+	if !s.byRuntime {
+		return s.Prefix, nil
+	}
+	prefix := Prefix(b.CompatibleRuntime[0])
+	for _, r := range b.CompatibleRuntime[1:] {
+		if Prefix(r) != prefix {
+			return "", fmt.Errorf("compatible runtimes %v use different layer directories, use WithDirAt", b.CompatibleRuntime)
+		}
+	}
+	return prefix, nil
+}
+
+type layerEntry struct {
+	name string
+	file *zip.File
+}
+
+// Build returns the zip file of the layer.
+func (b *Builder) Build() ([]byte, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+
+	modified := time.Now()
+	if b.ZipOptions.Deterministic {
+		modified = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	entries := []layerEntry{}
+	names := map[string]bool{}
+	var size uint64
+	for _, s := range b.Sources {
+		prefix, _ := b.prefix(s)
+		if prefix != "" {
+			prefix += "/"
+		}
+		buf := &bytes.Buffer{}
+		if err := fc.ZipDirWithOptions(s.Dir, buf, b.ZipOptions); err != nil {
+			return nil, err
+		}
+		reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			return nil, err
+		}
+		// parent directories of the prefix
+		for i := strings.Index(prefix, "/"); i >= 0; i = nextSlash(prefix, i) {
+			if name := prefix[:i+1]; !names[name] {
+				names[name] = true
+				entries = append(entries, layerEntry{name: name})
+			}
+		}
+		for _, f := range reader.File {
+			if f.Name == "./" {
+				continue
+			}
+			name := prefix + f.Name
+			if names[name] {
+				if strings.HasSuffix(name, "/") {
+					continue
+				}
+				return nil, fmt.Errorf("file %s is in more than one source directory", name)
+			}
+			names[name] = true
+			size += f.UncompressedSize64
+			entries = append(entries, layerEntry{name: name, file: f})
+		}
+	}
+	if b.MaxUnzippedSize > 0 && size > uint64(b.MaxUnzippedSize) {
+		return nil, fmt.Errorf("layer %s is %d bytes unzipped, more than the limit of %d bytes", b.LayerName, size, b.MaxUnzippedSize)
+	}
+	if b.ZipOptions.Deterministic {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	}
+
+	out := &bytes.Buffer{}
+	archive := zip.NewWriter(out)
+	for _, e := range entries {
+		if err := writeEntry(archive, e, modified); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func nextSlash(s string, i int) int {
+	j := strings.Index(s[i+1:], "/")
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
+
+func writeEntry(archive *zip.Writer, e layerEntry, modified time.Time) error {
+	if e.file == nil {
+		header := &zip.FileHeader{Name: e.name, Modified: modified, Flags: 1 << 11}
+		header.SetMode(os.ModeDir | 0755)
+		_, err := archive.CreateHeader(header)
+		return err
+	}
+	header := e.file.FileHeader
+	header.Name = e.name
+	// the writer adds its own timestamp field
+	header.Extra = nil
+	w, err := archive.CreateHeader(&header)
+	if err != nil {
+		return err
+	}
+	if strings.HasSuffix(e.name, "/") {
+		return nil
+	}
+	r, err := e.file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	_, err = io.Copy(w, r)
+	return err
+}
+
+// Publish builds the layer and publishes it as a new version.
+func (b *Builder) Publish(ctx context.Context) (*fc.PublishLayerVersionOutput, error) {
+	if b.Client == nil {
+		return nil, fmt.Errorf("Client is required but not provided")
+	}
+	data, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	code, err := b.code(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return b.Client.PublishLayerVersion(fc.NewPublishLayerVersionInput().
+		WithLayerName(b.LayerName).
+		WithDescription(b.Description).
+		WithCompatibleRuntime(b.CompatibleRuntime).
+		WithCode(code))
+}
+
+func (b *Builder) code(ctx context.Context, data []byte) (*fc.Code, error) {
+	if b.Uploader == nil {
+		if int64(base64.StdEncoding.EncodedLen(len(data))) > fc.DefaultInlineCodeSizeLimit {
+			return nil, fmt.Errorf("layer %s is %d bytes, too large to publish inline, use WithUploader", b.LayerName, len(data))
+		}
+		return fc.NewCode().WithZipFile(data), nil
+	}
+	f, err := ioutil.TempFile("", "fc_layer_")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b.Uploader.UploadCode(ctx, f.Name())
+}
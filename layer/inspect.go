@@ -0,0 +1,78 @@
+package layer
+
+import (
+	"context"
+	"io/fs"
+	"strings"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// InspectClient is the subset of fc.Client Inspect uses.
+type InspectClient interface {
+	GetLayerVersionByArn(input *fc.GetLayerVersionByArnInput) (*fc.GetLayerVersionOutput, error)
+	DownloadLayerVersionByArnFS(ctx context.Context, arn string) (fs.FS, error)
+}
+
+// File is a file or directory in a layer.
+type File struct {
+	Path string      `json:"path"`
+	Size int64       `json:"size"`
+	Mode fs.FileMode `json:"mode"`
+}
+
+// Contents describes a layer version and the files in it.
+type Contents struct {
+	Layer fc.Layer `json:"layer"`
+	Files []File   `json:"files"`
+}
+
+// Runtimes returns the runtime directories, e.g. "python", the layer has content in.
+func (c *Contents) Runtimes() []string {
+	runtimes := []string{}
+	seen := map[string]bool{}
+	for _, f := range c.Files {
+		for _, p := range runtimePrefixes {
+			if strings.HasPrefix(f.Path+"/", p.prefix+"/") && !seen[p.runtime] {
+				seen[p.runtime] = true
+				runtimes = append(runtimes, p.runtime)
+			}
+		}
+	}
+	return runtimes
+}
+
+// Inspect downloads the layer version with the ARN and lists its files in lexical order.
+func Inspect(ctx context.Context, client InspectClient, arn string) (*Contents, error) {
+	layer, err := client.GetLayerVersionByArn(fc.NewGetLayerVersionByArnInput(arn))
+	if err != nil {
+		return nil, err
+	}
+	fsys, err := client.DownloadLayerVersionByArnFS(ctx, arn)
+	if err != nil {
+		return nil, err
+	}
+	contents := &Contents{Layer: layer.Layer, Files: []File{}}
+	err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == "." {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		file := File{Path: path, Mode: info.Mode()}
+		if !d.IsDir() {
+			file.Size = info.Size()
+		}
+		contents.Files = append(contents.Files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
@@ -0,0 +1,44 @@
+// Package layer builds, publishes and inspects layers.
+//
+// A layer is unpacked into /opt of the instance, and each runtime loads dependencies from its own
+// directory there: Python from python/, Node.js from nodejs/node_modules/, Java from java/lib/ and
+// PHP from php/. Shared libraries go in lib/ and executables in bin/, which are on LD_LIBRARY_PATH
+// and PATH for every runtime.
+//
+//	output, err := layer.New(client, "requests").
+//		WithCompatibleRuntime("python3.9", "python3.10").
+//		WithDir("./site-packages").
+//		Publish(ctx)
+package layer
+
+import (
+	"strings"
+)
+
+// Directories in a layer for every runtime
+const (
+	PrefixLib = "lib"
+	PrefixBin = "bin"
+)
+
+// runtimePrefixes maps runtime name prefixes to the directory the runtime loads dependencies from.
+var runtimePrefixes = []struct {
+	runtime string
+	prefix  string
+}{
+	{"python", "python"},
+	{"nodejs", "nodejs/node_modules"},
+	{"java", "java/lib"},
+	{"php", "php"},
+}
+
+// Prefix returns the directory in a layer the runtime loads dependencies from, or "" for runtimes,
+// such as custom runtimes and "Any", which read the layer root.
+func Prefix(runtime string) string {
+	for _, p := range runtimePrefixes {
+		if strings.HasPrefix(runtime, p.runtime) {
+			return p.prefix
+		}
+	}
+	return ""
+}
@@ -0,0 +1,176 @@
+package layer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	fc "github.com/aliyun/fc-go-sdk"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeClient struct {
+	published *fc.PublishLayerVersionInput
+	archive   []byte
+}
+
+func (c *fakeClient) PublishLayerVersion(input *fc.PublishLayerVersionInput) (*fc.PublishLayerVersionOutput, error) {
+	c.published = input
+	output := &fc.PublishLayerVersionOutput{}
+	output.LayerName = input.LayerName
+	output.Version = 2
+	output.Arn = "acs:fc:cn-hangzhou:123:layers/" + input.LayerName + "/versions/2"
+	return output, nil
+}
+
+func (c *fakeClient) GetLayerVersionByArn(input *fc.GetLayerVersionByArnInput) (*fc.GetLayerVersionOutput, error) {
+	output := &fc.GetLayerVersionOutput{}
+	output.Arn = input.Arn
+	output.CompatibleRuntime = []string{"python3.9"}
+	return output, nil
+}
+
+func (c *fakeClient) DownloadLayerVersionByArnFS(ctx context.Context, arn string) (fs.FS, error) {
+	return zip.NewReader(bytes.NewReader(c.archive), int64(len(c.archive)))
+}
+
+type LayerTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestLayer(t *testing.T) {
+	suite.Run(t, new(LayerTestSuite))
+}
+
+func (s *LayerTestSuite) SetupTest() {
+	assert := s.Require()
+
+	dir, err := ioutil.TempDir("", "fc_layer_test_")
+	assert.Nil(err)
+	s.dir = dir
+	for _, name := range []string{"deps/requests/__init__.py", "deps/tests/test_x.py", "so/libfoo.so"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.Nil(os.MkdirAll(filepath.Dir(path), 0755))
+		assert.Nil(ioutil.WriteFile(path, []byte(name), 0644))
+	}
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "deps", fc.IgnoreFileName), []byte("tests/\n"), 0644))
+}
+
+func (s *LayerTestSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+}
+
+func zipNames(data []byte) []string {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		panic(err)
+	}
+	names := []string{}
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func (s *LayerTestSuite) TestPrefix() {
+	assert := s.Require()
+
+	assert.Equal("python", Prefix("python3.9"))
+	assert.Equal("nodejs/node_modules", Prefix("nodejs14"))
+	assert.Equal("java/lib", Prefix("java11"))
+	assert.Equal("php", Prefix("php7.2"))
+	assert.Equal("", Prefix("custom"))
+	assert.Equal("", Prefix(fc.AnyRunTime))
+}
+
+func (s *LayerTestSuite) TestBuild() {
+	assert := s.Require()
+
+	b := New(nil, "requests").
+		WithCompatibleRuntime("python3.9", "python3.10").
+		WithDir(filepath.Join(s.dir, "deps")).
+		WithDirAt(filepath.Join(s.dir, "so"), "/lib/").
+		WithZipOptions(fc.ZipOptions{Deterministic: true})
+	data, err := b.Build()
+	assert.Nil(err)
+	assert.Equal([]string{
+		"lib/", "lib/libfoo.so", "python/", "python/" + fc.IgnoreFileName,
+		"python/requests/", "python/requests/__init__.py",
+	}, zipNames(data))
+
+	again, err := b.Build()
+	assert.Nil(err)
+	assert.Equal(data, again)
+
+	data, err = New(nil, "mods").WithCompatibleRuntime("nodejs14").WithDir(filepath.Join(s.dir, "so")).Build()
+	assert.Nil(err)
+	assert.Equal([]string{"nodejs/", "nodejs/node_modules/", "nodejs/node_modules/libfoo.so"}, zipNames(data))
+}
+
+func (s *LayerTestSuite) TestValidate() {
+	assert := s.Require()
+
+	_, err := New(nil, "x").WithCompatibleRuntime("python3.9", "nodejs14").WithDir(s.dir).Build()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "different layer directories")
+
+	_, err = New(nil, "x").WithDir(s.dir).Build()
+	assert.NotNil(err)
+	_, err = New(nil, "x").WithCompatibleRuntime("custom").Build()
+	assert.NotNil(err)
+	_, err = New(nil, "").WithCompatibleRuntime("custom").WithDir(s.dir).Build()
+	assert.NotNil(err)
+
+	_, err = New(nil, "x").WithCompatibleRuntime("custom").WithDir(s.dir).WithMaxUnzippedSize(10).Build()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "more than the limit")
+
+	_, err = New(nil, "x").WithCompatibleRuntime("custom").
+		WithDirAt(filepath.Join(s.dir, "so"), "lib").WithDirAt(filepath.Join(s.dir, "so"), "lib").Build()
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "more than one source directory")
+}
+
+func (s *LayerTestSuite) TestPublishAndInspect() {
+	assert := s.Require()
+
+	client := &fakeClient{}
+	output, err := New(client, "requests").
+		WithDescription("requests for python").
+		WithCompatibleRuntime("python3.9").
+		WithDir(filepath.Join(s.dir, "deps")).
+		WithDirAt(filepath.Join(s.dir, "so"), PrefixLib).
+		Publish(context.Background())
+	assert.Nil(err)
+	assert.Equal(int32(2), output.Version)
+	assert.Equal("requests", client.published.LayerName)
+	assert.Equal("requests for python", client.published.Description)
+	assert.Equal([]string{"python3.9"}, client.published.CompatibleRuntime)
+	client.archive, err = base64.StdEncoding.DecodeString(*client.published.Code.ZipFile)
+	assert.Nil(err)
+
+	contents, err := Inspect(context.Background(), client, output.Arn)
+	assert.Nil(err)
+	assert.Equal(output.Arn, contents.Layer.Arn)
+	paths := []string{}
+	for _, f := range contents.Files {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal([]string{
+		"lib", "lib/libfoo.so", "python", "python/" + fc.IgnoreFileName,
+		"python/requests", "python/requests/__init__.py",
+	}, paths)
+	assert.True(contents.Files[0].Mode.IsDir())
+	assert.Equal(int64(len("so/libfoo.so")), contents.Files[1].Size)
+	assert.Equal([]string{"python"}, contents.Runtimes())
+
+	_, err = New(nil, "x").WithCompatibleRuntime("custom").WithDir(s.dir).Publish(context.Background())
+	assert.NotNil(err)
+}
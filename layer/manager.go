@@ -0,0 +1,345 @@
+package layer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	fc "github.com/aliyun/fc-go-sdk"
+	"github.com/aliyun/fc-go-sdk/arn"
+)
+
+// ManagerClient is the subset of fc.Client the manager uses.
+type ManagerClient interface {
+	ListLayers(input *fc.ListLayersInput) (*fc.ListLayersOutput, error)
+	ListLayerVersions(input *fc.ListLayerVersionsInput) (*fc.ListLayerVersionsOutput, error)
+	ListServices(input *fc.ListServicesInput) (*fc.ListServicesOutput, error)
+	ListServiceVersions(input *fc.ListServiceVersionsInput) (*fc.ListServiceVersionsOutput, error)
+	ListFunctions(input *fc.ListFunctionsInput) (*fc.ListFunctionsOutput, error)
+	DeleteLayerVersion(input *fc.DeleteLayerVersionInput) (*fc.DeleteLayerVersionOutput, error)
+}
+
+// Actions taken on a layer version
+const (
+	ActionKeep    = "keep"
+	ActionDelete  = "delete"
+	ActionDeleted = "deleted"
+	ActionFailed  = "failed"
+)
+
+// latestQualifier names the unpublished configuration of a function in usage reports.
+const latestQualifier = "LATEST"
+
+// Manager finds the layer versions no function uses, in LATEST or any published version of
+// any service, and deletes them.
+//
+//	report, err := layer.NewManager(client).WithKeepLast(3).WithDryRun().Run()
+type Manager struct {
+	Client      ManagerClient
+	LayerPrefix string
+	KeepLast    int
+	DryRun      bool
+}
+
+// NewManager returns a manager for the layers of the account.
+func NewManager(client ManagerClient) *Manager {
+	return &Manager{Client: client}
+}
+
+// WithLayerPrefix only manages the layers whose name starts with the prefix.
+func (m *Manager) WithLayerPrefix(prefix string) *Manager {
+	m.LayerPrefix = prefix
+	return m
+}
+
+// WithKeepLast keeps the newest n versions of every layer regardless of usage.
+func (m *Manager) WithKeepLast(n int) *Manager {
+	m.KeepLast = n
+	return m
+}
+
+// WithDryRun reports the versions that would be deleted without deleting them.
+func (m *Manager) WithDryRun() *Manager {
+	m.DryRun = true
+	return m
+}
+
+// Validate ...
+func (m *Manager) Validate() error {
+	if m.Client == nil {
+		return fmt.Errorf("Client is required but not provided")
+	}
+	if m.KeepLast < 0 {
+		return fmt.Errorf("invalid keep last %d", m.KeepLast)
+	}
+	return nil
+}
+
+// Report lists every layer version with the action taken and why.
+type Report struct {
+	DryRun bool                 `json:"dryRun"`
+	Layers []LayerVersionReport `json:"layers"`
+}
+
+// LayerVersionReport ...
+type LayerVersionReport struct {
+	LayerName  string   `json:"layerName"`
+	Version    int32    `json:"version"`
+	Arn        string   `json:"arn"`
+	CreateTime string   `json:"createTime"`
+	Action     string   `json:"action"`
+	UsedBy     []string `json:"usedBy,omitempty"`
+	Reasons    []string `json:"reasons,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// Unused returns the versions no function uses, whether kept or not.
+func (r *Report) Unused() []LayerVersionReport {
+	var unused []LayerVersionReport
+	for _, l := range r.Layers {
+		if len(l.UsedBy) == 0 {
+			unused = append(unused, l)
+		}
+	}
+	return unused
+}
+
+// Deleted returns the ARNs of versions deleted, or to be deleted on a dry run.
+func (r *Report) Deleted() []string {
+	var arns []string
+	for _, l := range r.Layers {
+		if l.Action == ActionDelete || l.Action == ActionDeleted {
+			arns = append(arns, l.Arn)
+		}
+	}
+	return arns
+}
+
+func (r *Report) String() string {
+	b, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// Run plans the pruning and, unless dry running, deletes the unused layer versions.
+// Deletion failures are recorded on the report and do not stop the run.
+func (m *Manager) Run() (*Report, error) {
+	report, err := m.Plan()
+	if err != nil || m.DryRun {
+		return report, err
+	}
+	for i, l := range report.Layers {
+		if l.Action != ActionDelete {
+			continue
+		}
+		if _, err := m.Client.DeleteLayerVersion(fc.NewDeleteLayerVersionInput(l.LayerName, l.Version)); err != nil {
+			report.Layers[i].Action = ActionFailed
+			report.Layers[i].Error = err.Error()
+			continue
+		}
+		report.Layers[i].Action = ActionDeleted
+	}
+	return report, nil
+}
+
+// Plan decides the action for every layer version without deleting anything.
+func (m *Manager) Plan() (*Report, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	usage, err := m.Usage()
+	if err != nil {
+		return nil, err
+	}
+	layers, err := m.listLayers()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{DryRun: m.DryRun, Layers: []LayerVersionReport{}}
+	for _, name := range layers {
+		versions, err := m.listLayerVersions(name)
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range versions {
+			usedBy := usage[layerKey(v.LayerName, v.Version)]
+			var reasons []string
+			if len(usedBy) > 0 {
+				reasons = append(reasons, fmt.Sprintf("used by %d functions", len(usedBy)))
+			}
+			if i < m.KeepLast {
+				reasons = append(reasons, fmt.Sprintf("among the last %d versions", m.KeepLast))
+			}
+			action := ActionDelete
+			if len(reasons) > 0 {
+				action = ActionKeep
+			}
+			report.Layers = append(report.Layers, LayerVersionReport{
+				LayerName:  v.LayerName,
+				Version:    v.Version,
+				Arn:        v.Arn,
+				CreateTime: v.CreateTime,
+				Action:     action,
+				UsedBy:     usedBy,
+				Reasons:    reasons,
+			})
+		}
+	}
+	return report, nil
+}
+
+// Usage maps layer versions, keyed by "name#version", to the functions using them, named
+// "service.qualifier/function" with qualifier LATEST or a version ID.
+func (m *Manager) Usage() (map[string][]string, error) {
+	services, err := m.listServices()
+	if err != nil {
+		return nil, err
+	}
+	usage := map[string][]string{}
+	for _, service := range services {
+		versions, err := m.listServiceVersions(service)
+		if err != nil {
+			return nil, err
+		}
+		for _, qualifier := range append([]string{latestQualifier}, versions...) {
+			if err := m.addFunctionUsage(usage, service, qualifier); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, users := range usage {
+		sort.Strings(users)
+	}
+	return usage, nil
+}
+
+// layerKey identifies a layer version as "name#version".
+func layerKey(name string, version int32) string {
+	return name + "#" + strconv.Itoa(int(version))
+}
+
+// layerKeyFromArn returns the layerKey of a layer version ARN, or the ARN if it does not parse.
+func layerKeyFromArn(s string) string {
+	a, err := arn.Parse(s)
+	if err != nil {
+		return s
+	}
+	name, version, err := a.Layer()
+	if err != nil {
+		return s
+	}
+	return layerKey(name, version)
+}
+
+func (m *Manager) addFunctionUsage(usage map[string][]string, service, qualifier string) error {
+	input := fc.NewListFunctionsInput(service).WithLimit(100)
+	if qualifier != latestQualifier {
+		input.WithQualifier(qualifier)
+	}
+	for {
+		output, err := m.Client.ListFunctions(input)
+		if err != nil {
+			return fmt.Errorf("failed to list functions of %s.%s: %v", service, qualifier, err)
+		}
+		for _, f := range output.Functions {
+			if f.FunctionName == nil {
+				continue
+			}
+			user := service + "." + qualifier + "/" + *f.FunctionName
+			for _, l := range f.Layers {
+				key := layerKeyFromArn(l)
+				usage[key] = append(usage[key], user)
+			}
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			return nil
+		}
+		input.WithNextToken(*output.NextToken)
+	}
+}
+
+func (m *Manager) listServices() ([]string, error) {
+	var services []string
+	input := fc.NewListServicesInput().WithLimit(100)
+	for {
+		output, err := m.Client.ListServices(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list services: %v", err)
+		}
+		for _, s := range output.Services {
+			if s.ServiceName != nil {
+				services = append(services, *s.ServiceName)
+			}
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			return services, nil
+		}
+		input.WithNextToken(*output.NextToken)
+	}
+}
+
+func (m *Manager) listServiceVersions(service string) ([]string, error) {
+	var versions []string
+	input := fc.NewListServiceVersionsInput(service).WithLimit(100)
+	for {
+		output, err := m.Client.ListServiceVersions(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions of %s: %v", service, err)
+		}
+		for _, v := range output.Versions {
+			if v.VersionID != nil {
+				versions = append(versions, *v.VersionID)
+			}
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			return versions, nil
+		}
+		input.WithNextToken(*output.NextToken)
+	}
+}
+
+func (m *Manager) listLayers() ([]string, error) {
+	var layers []string
+	input := fc.NewListLayersInput().WithLimit(100)
+	if m.LayerPrefix != "" {
+		input.WithPrefix(m.LayerPrefix)
+	}
+	for {
+		output, err := m.Client.ListLayers(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list layers: %v", err)
+		}
+		for _, l := range output.Layers {
+			layers = append(layers, l.LayerName)
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			return layers, nil
+		}
+		input.WithNextToken(*output.NextToken)
+	}
+}
+
+// listLayerVersions returns all versions of the layer, newest first.
+func (m *Manager) listLayerVersions(name string) ([]fc.Layer, error) {
+	var versions []fc.Layer
+	input := fc.NewListLayerVersionsInput(name, 1).WithLimit(100)
+	for {
+		output, err := m.Client.ListLayerVersions(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions of layer %s: %v", name, err)
+		}
+		for _, l := range output.Layers {
+			versions = append(versions, *l)
+		}
+		if output.NextVersion == nil || *output.NextVersion <= input.StartVersion {
+			break
+		}
+		input.StartVersion = *output.NextVersion
+	}
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].Version > versions[j].Version })
+	return versions, nil
+}
@@ -0,0 +1,158 @@
+package layer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	fc "github.com/aliyun/fc-go-sdk"
+	"github.com/stretchr/testify/suite"
+)
+
+func unmarshal(s string, v interface{}) {
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		panic(err)
+	}
+}
+
+func layerArn(name string, version int) string {
+	return fmt.Sprintf("acs:fc:cn-hangzhou:123:layers/%s/versions/%d", name, version)
+}
+
+// fakeManagerClient has layer "deps" with versions 1 to 5 and "tools" with versions 1 and 2,
+// paging layer versions two at a time.
+type fakeManagerClient struct {
+	deleted []string
+	failing string
+}
+
+func (c *fakeManagerClient) ListLayers(input *fc.ListLayersInput) (*fc.ListLayersOutput, error) {
+	output := &fc.ListLayersOutput{}
+	if input.Prefix != nil && *input.Prefix == "d" {
+		unmarshal(`{"layers":[{"layerName":"deps","version":5}]}`, output)
+	} else if input.NextToken == nil {
+		unmarshal(`{"layers":[{"layerName":"deps","version":5}],"nextToken":"t"}`, output)
+	} else {
+		unmarshal(`{"layers":[{"layerName":"tools","version":2}]}`, output)
+	}
+	return output, nil
+}
+
+func (c *fakeManagerClient) ListLayerVersions(input *fc.ListLayerVersionsInput) (*fc.ListLayerVersionsOutput, error) {
+	latest := map[string]int32{"deps": 5, "tools": 2}[input.LayerName]
+	output := &fc.ListLayerVersionsOutput{}
+	for v := input.StartVersion; v < input.StartVersion+2 && v <= latest; v++ {
+		output.Layers = append(output.Layers, &fc.Layer{LayerName: input.LayerName, Version: v, Arn: layerArn(input.LayerName, int(v))})
+	}
+	if next := input.StartVersion + 2; next <= latest {
+		output.NextVersion = &next
+	}
+	return output, nil
+}
+
+func (c *fakeManagerClient) ListServices(input *fc.ListServicesInput) (*fc.ListServicesOutput, error) {
+	output := &fc.ListServicesOutput{}
+	unmarshal(`{"services":[{"serviceName":"a"},{"serviceName":"b"}]}`, output)
+	return output, nil
+}
+
+func (c *fakeManagerClient) ListServiceVersions(input *fc.ListServiceVersionsInput) (*fc.ListServiceVersionsOutput, error) {
+	output := &fc.ListServiceVersionsOutput{}
+	if *input.ServiceName == "a" {
+		unmarshal(`{"versions":[{"versionId":"1"}]}`, output)
+	}
+	return output, nil
+}
+
+func (c *fakeManagerClient) ListFunctions(input *fc.ListFunctionsInput) (*fc.ListFunctionsOutput, error) {
+	qualifier := ""
+	if input.Qualifier != nil {
+		qualifier = *input.Qualifier
+	}
+	output := &fc.ListFunctionsOutput{}
+	switch *input.ServiceName + "." + qualifier {
+	case "a.":
+		if input.NextToken == nil {
+			unmarshal(`{"functions":[{"functionName":"f","layers":["`+layerArn("deps", 4)+`"]}],"nextToken":"t"}`, output)
+		} else {
+			unmarshal(`{"functions":[{"functionName":"g","layers":["`+layerArn("deps", 4)+`","`+layerArn("tools", 1)+`"]}]}`, output)
+		}
+	case "a.1":
+		unmarshal(`{"functions":[{"functionName":"f","layers":["`+layerArn("deps", 2)+`","acs:fc:cn-hangzhou:official:layers/Python3-Pandas/versions/1"]}]}`, output)
+	case "b.":
+		unmarshal(`{"functions":[{"functionName":"h"}]}`, output)
+	}
+	return output, nil
+}
+
+func (c *fakeManagerClient) DeleteLayerVersion(input *fc.DeleteLayerVersionInput) (*fc.DeleteLayerVersionOutput, error) {
+	arn := layerArn(input.LayerName, int(input.Version))
+	if arn == c.failing {
+		return nil, errors.New("boom")
+	}
+	c.deleted = append(c.deleted, arn)
+	return &fc.DeleteLayerVersionOutput{}, nil
+}
+
+type ManagerTestSuite struct {
+	suite.Suite
+}
+
+func TestManager(t *testing.T) {
+	suite.Run(t, new(ManagerTestSuite))
+}
+
+func (s *ManagerTestSuite) TestUsage() {
+	assert := s.Require()
+
+	usage, err := NewManager(&fakeManagerClient{}).Usage()
+	assert.Nil(err)
+	assert.Equal(map[string][]string{
+		"deps#4":           {"a.LATEST/f", "a.LATEST/g"},
+		"tools#1":          {"a.LATEST/g"},
+		"deps#2":           {"a.1/f"},
+		"Python3-Pandas#1": {"a.1/f"},
+	}, usage)
+}
+
+func (s *ManagerTestSuite) TestPlan() {
+	assert := s.Require()
+
+	client := &fakeManagerClient{}
+	report, err := NewManager(client).WithKeepLast(1).WithDryRun().Run()
+	assert.Nil(err)
+	assert.True(report.DryRun)
+	actions := map[string]string{}
+	for _, l := range report.Layers {
+		actions[fmt.Sprintf("%s#%d", l.LayerName, l.Version)] = l.Action
+	}
+	assert.Equal(map[string]string{
+		"deps#5": ActionKeep, "deps#4": ActionKeep, "deps#3": ActionDelete, "deps#2": ActionKeep, "deps#1": ActionDelete,
+		"tools#2": ActionKeep, "tools#1": ActionKeep,
+	}, actions)
+	assert.Equal([]string{layerArn("deps", 3), layerArn("deps", 1)}, report.Deleted())
+	assert.Len(report.Unused(), 4)
+	assert.Equal([]string{"among the last 1 versions"}, report.Layers[0].Reasons)
+	assert.Equal([]string{"a.LATEST/f", "a.LATEST/g"}, report.Layers[1].UsedBy)
+	assert.Empty(client.deleted)
+}
+
+func (s *ManagerTestSuite) TestRun() {
+	assert := s.Require()
+
+	client := &fakeManagerClient{failing: layerArn("deps", 1)}
+	report, err := NewManager(client).WithLayerPrefix("d").Run()
+	assert.Nil(err)
+	assert.Equal([]string{layerArn("deps", 5), layerArn("deps", 3)}, client.deleted)
+	byArn := map[string]LayerVersionReport{}
+	for _, l := range report.Layers {
+		byArn[l.Arn] = l
+	}
+	assert.Equal(ActionDeleted, byArn[layerArn("deps", 5)].Action)
+	assert.Equal(ActionFailed, byArn[layerArn("deps", 1)].Action)
+	assert.Equal("boom", byArn[layerArn("deps", 1)].Error)
+
+	assert.NotNil(NewManager(nil).Validate())
+	assert.NotNil(NewManager(client).WithKeepLast(-1).Validate())
+}
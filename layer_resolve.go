@@ -0,0 +1,211 @@
+package fc
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// MaxFunctionLayers is the largest number of layers a function can use.
+const MaxFunctionLayers = 5
+
+// ParseLayerRef parses a layer reference "name@version" or "name:version", where version is a
+// number or "latest". A bare name refers to the latest version, returned as version 0.
+func ParseLayerRef(ref string) (name string, version int32, err error) {
+	name, v := ref, ""
+	if i := strings.LastIndexAny(ref, "@:"); i >= 0 {
+		name, v = ref[:i], ref[i+1:]
+	}
+	if name == "" {
+		return "", 0, fmt.Errorf("invalid layer reference %q: layer name is empty", ref)
+	}
+	if v == "" || strings.EqualFold(v, "latest") {
+		return name, 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || n <= 0 {
+		return "", 0, fmt.Errorf("invalid layer reference %q: version must be a positive number or latest", ref)
+	}
+	return name, int32(n), nil
+}
+
+// LayerResolver resolves layer references to layer versions and checks they can be used together
+// by a function with a runtime.
+type LayerResolver struct {
+	client          *Client
+	maxUnzippedSize int64
+}
+
+func NewLayerResolver(client *Client) *LayerResolver {
+	return &LayerResolver{client: client}
+}
+
+// WithMaxUnzippedSize fails resolution when the layers are larger unzipped than size in total.
+// The check downloads every layer; 0, the default, disables it.
+func (r *LayerResolver) WithMaxUnzippedSize(size int64) *LayerResolver {
+	r.maxUnzippedSize = size
+	return r
+}
+
+// Resolve returns the layer version for each reference, an ARN or a reference parsed by
+// ParseLayerRef, failing if a layer is not compatible with the runtime. An empty runtime skips
+// the compatibility check.
+func (r *LayerResolver) Resolve(ctx context.Context, runtime string, refs ...string) ([]*Layer, error) {
+	if r.client == nil {
+		return nil, fmt.Errorf("Client is required but not provided")
+	}
+	if len(refs) > MaxFunctionLayers {
+		return nil, fmt.Errorf("a function can use at most %d layers, got %d", MaxFunctionLayers, len(refs))
+	}
+	layers := make([]*Layer, 0, len(refs))
+	for _, ref := range refs {
+		layer, err := r.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		if runtime != "" && !IsLayerCompatible(layer, runtime) {
+			return nil, fmt.Errorf("layer %s version %d is not compatible with runtime %s, compatible runtimes are %v",
+				layer.LayerName, layer.Version, runtime, layer.CompatibleRuntime)
+		}
+		layers = append(layers, layer)
+	}
+	if r.maxUnzippedSize > 0 {
+		if err := r.checkUnzippedSize(ctx, layers); err != nil {
+			return nil, err
+		}
+	}
+	return layers, nil
+}
+
+// ResolveArns resolves the references like Resolve and returns the ARNs of the layer versions.
+func (r *LayerResolver) ResolveArns(ctx context.Context, runtime string, refs ...string) ([]string, error) {
+	layers, err := r.Resolve(ctx, runtime, refs...)
+	if err != nil {
+		return nil, err
+	}
+	arns := make([]string, 0, len(layers))
+	for _, l := range layers {
+		arns = append(arns, l.Arn)
+	}
+	return arns, nil
+}
+
+// IsLayerCompatible reports whether a function with the runtime can use the layer.
+func IsLayerCompatible(layer *Layer, runtime string) bool {
+	for _, r := range layer.CompatibleRuntime {
+		if r == runtime || r == AnyRunTime {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *LayerResolver) resolve(ref string) (*Layer, error) {
+	if strings.HasPrefix(ref, "acs:") {
+		output, err := r.client.GetLayerVersionByArn(NewGetLayerVersionByArnInput(ref))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get layer %s: %v", ref, err)
+		}
+		return &output.Layer, nil
+	}
+	name, version, err := ParseLayerRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return r.latest(name)
+	}
+	output, err := r.client.GetLayerVersion(NewGetLayerVersionInput(name, version))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get layer %s version %d: %v", name, version, err)
+	}
+	return &output.Layer, nil
+}
+
+// latest returns the newest version of the layer.
+func (r *LayerResolver) latest(name string) (*Layer, error) {
+	var latest *Layer
+	input := NewListLayerVersionsInput(name, 1).WithLimit(100)
+	for {
+		output, err := r.client.ListLayerVersions(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions of layer %s: %v", name, err)
+		}
+		for _, l := range output.Layers {
+			if latest == nil || l.Version > latest.Version {
+				latest = l
+			}
+		}
+		if output.NextVersion == nil || *output.NextVersion <= input.StartVersion {
+			break
+		}
+		input.StartVersion = *output.NextVersion
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("layer %s has no versions", name)
+	}
+	return latest, nil
+}
+
+func (r *LayerResolver) checkUnzippedSize(ctx context.Context, layers []*Layer) error {
+	var total int64
+	for _, l := range layers {
+		fsys, err := r.client.DownloadLayerVersionByArnFS(ctx, l.Arn)
+		if err != nil {
+			return fmt.Errorf("failed to download layer %s version %d: %v", l.LayerName, l.Version, err)
+		}
+		err = fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			total += info.Size()
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if total > r.maxUnzippedSize {
+		return fmt.Errorf("layers are %d bytes unzipped, more than the limit of %d bytes", total, r.maxUnzippedSize)
+	}
+	return nil
+}
+
+// WithLayerRefs resolves the layer references with the resolver, checking them against the
+// runtime, which must be set first, and uses the layer versions. Resolution errors are returned
+// by Validate.
+func (i *CreateFunctionInput) WithLayerRefs(ctx context.Context, resolver *LayerResolver, refs ...string) *CreateFunctionInput {
+	if i.Runtime == nil {
+		i.err = fmt.Errorf("Runtime is required to check layer compatibility but not provided")
+		return i
+	}
+	arns, err := resolver.ResolveArns(ctx, *i.Runtime, refs...)
+	if err != nil {
+		i.err = err
+		return i
+	}
+	i.Layers = arns
+	return i
+}
+
+// WithLayerRefs resolves the layer references like CreateFunctionInput.WithLayerRefs. Without a
+// runtime set on the input the compatibility check is skipped.
+func (i *UpdateFunctionInput) WithLayerRefs(ctx context.Context, resolver *LayerResolver, refs ...string) *UpdateFunctionInput {
+	runtime := ""
+	if i.Runtime != nil {
+		runtime = *i.Runtime
+	}
+	arns, err := resolver.ResolveArns(ctx, runtime, refs...)
+	if err != nil {
+		i.err = err
+		return i
+	}
+	i.Layers = arns
+	return i
+}
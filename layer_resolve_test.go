@@ -0,0 +1,147 @@
+package fc
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LayerResolveTestSuite struct {
+	suite.Suite
+	server   *httptest.Server
+	client   *Client
+	zipBytes []byte
+}
+
+func TestLayerResolve(t *testing.T) {
+	suite.Run(t, new(LayerResolveTestSuite))
+}
+
+func (s *LayerResolveTestSuite) layerJSON(name string, version int, runtimes string) string {
+	return fmt.Sprintf(`{"layerName":"%s","version":%d,"arn":"acs:fc:cn-hangzhou:123:layers/%s/versions/%d",
+		"compatibleRuntime":[%s],"codeChecksum":"%s","code":{"location":"%s/code.zip"}}`,
+		name, version, name, version, runtimes, CodeChecksum(s.zipBytes), s.server.URL)
+}
+
+func (s *LayerResolveTestSuite) SetupTest() {
+	assert := s.Require()
+
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	f, _ := w.Create("python/big.py")
+	f.Write(bytes.Repeat([]byte("x"), 1000))
+	w.Close()
+	s.zipBytes = buf.Bytes()
+
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/2016-08-15")
+		switch {
+		case path == "/code.zip":
+			w.Write(s.zipBytes)
+		case path == "/layers/py/versions/2":
+			w.Write([]byte(s.layerJSON("py", 2, `"python3.9","python3.10"`)))
+		case path == "/layers/py/versions":
+			// versions 1 to 3, two per page
+			if r.URL.Query().Get("startVersion") == "1" {
+				w.Write([]byte(`{"layers":[` + s.layerJSON("py", 1, `"python3.9"`) + `,` + s.layerJSON("py", 2, `"python3.9"`) + `],"nextVersion":3}`))
+			} else {
+				w.Write([]byte(`{"layers":[` + s.layerJSON("py", 3, `"python3.9"`) + `]}`))
+			}
+		case path == "/layers/node/versions/1":
+			w.Write([]byte(s.layerJSON("node", 1, `"nodejs14"`)))
+		case strings.HasPrefix(path, "/layerarn/"):
+			w.Write([]byte(s.layerJSON("any", 7, `"Any"`)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"ErrorCode":"LayerNotFound","ErrorMessage":"not found"}`))
+		}
+	}))
+	var err error
+	s.client, err = NewClient(s.server.URL, APIVersionV1, "ak", "sk")
+	assert.Nil(err)
+}
+
+func (s *LayerResolveTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *LayerResolveTestSuite) TestParseLayerRef() {
+	assert := s.Require()
+
+	for ref, expected := range map[string]int32{"py": 0, "py:latest": 0, "py@LATEST": 0, "py@3": 3, "py:12": 12} {
+		name, version, err := ParseLayerRef(ref)
+		assert.Nil(err, ref)
+		assert.Equal("py", name, ref)
+		assert.Equal(expected, version, ref)
+	}
+	for _, ref := range []string{"", "@3", "py@0", "py@x", "py:-1"} {
+		_, _, err := ParseLayerRef(ref)
+		assert.NotNil(err, ref)
+	}
+}
+
+func (s *LayerResolveTestSuite) TestResolve() {
+	assert := s.Require()
+
+	resolver := NewLayerResolver(s.client)
+	arns, err := resolver.ResolveArns(context.Background(), "python3.9", "py:latest", "py@2", "acs:fc:cn-hangzhou:123:layers/any/versions/7")
+	assert.Nil(err)
+	assert.Equal([]string{
+		"acs:fc:cn-hangzhou:123:layers/py/versions/3",
+		"acs:fc:cn-hangzhou:123:layers/py/versions/2",
+		"acs:fc:cn-hangzhou:123:layers/any/versions/7",
+	}, arns)
+
+	_, err = resolver.Resolve(context.Background(), "python3.9", "node@1")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "layer node version 1 is not compatible with runtime python3.9, compatible runtimes are [nodejs14]")
+
+	_, err = resolver.Resolve(context.Background(), "python3.9", "missing@1")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "failed to get layer missing version 1")
+
+	_, err = resolver.Resolve(context.Background(), "python3.9", "a", "b", "c", "d", "e", "f")
+	assert.NotNil(err)
+
+	// no runtime, no compatibility check
+	_, err = resolver.Resolve(context.Background(), "", "node@1")
+	assert.Nil(err)
+}
+
+func (s *LayerResolveTestSuite) TestMaxUnzippedSize() {
+	assert := s.Require()
+
+	_, err := NewLayerResolver(s.client).WithMaxUnzippedSize(2000).Resolve(context.Background(), "python3.9", "py@2", "py@latest")
+	assert.Nil(err)
+	_, err = NewLayerResolver(s.client).WithMaxUnzippedSize(1999).Resolve(context.Background(), "python3.9", "py@2", "py@latest")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "layers are 2000 bytes unzipped")
+}
+
+func (s *LayerResolveTestSuite) TestWithLayerRefs() {
+	assert := s.Require()
+
+	resolver := NewLayerResolver(s.client)
+	input := NewCreateFunctionInput("svc").WithRuntime("python3.10").WithLayerRefs(context.Background(), resolver, "py@2")
+	assert.Nil(input.Validate())
+	assert.Equal([]string{"acs:fc:cn-hangzhou:123:layers/py/versions/2"}, input.Layers)
+
+	input = NewCreateFunctionInput("svc").WithRuntime("python3.10").WithLayerRefs(context.Background(), resolver, "py")
+	assert.NotNil(input.Validate())
+	assert.Contains(input.Validate().Error(), "not compatible with runtime python3.10")
+
+	input = NewCreateFunctionInput("svc").WithLayerRefs(context.Background(), resolver, "node:1")
+	assert.NotNil(input.Validate())
+	assert.Contains(input.Validate().Error(), "Runtime is required")
+
+	update := NewUpdateFunctionInput("svc", "fn").WithRuntime("nodejs14").WithLayerRefs(context.Background(), resolver, "node:1")
+	assert.Nil(update.Validate())
+	assert.Equal([]string{"acs:fc:cn-hangzhou:123:layers/node/versions/1"}, update.Layers)
+}
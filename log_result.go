@@ -0,0 +1,121 @@
+package fc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InvocationLog is the parsed log result of an invocation made with log type Tail.
+type InvocationLog struct {
+	RequestID string
+	Records   []LogRecord
+	Billing   *BillingSummary
+}
+
+// LogRecord is a line logged by the function. Lines without a timestamp prefix are kept in Message
+// with zero Time; continuation lines of a multi-line message are appended to the previous record.
+type LogRecord struct {
+	Time      time.Time
+	RequestID string
+	Level     string
+	Message   string
+}
+
+// BillingSummary is the resource usage reported at the end of an invocation.
+type BillingSummary struct {
+	Duration        time.Duration
+	BilledDuration  time.Duration
+	MemorySizeMB    int
+	MaxMemoryUsedMB float64
+}
+
+var (
+	logStartPattern   = regexp.MustCompile(`^FC (?:Invoke|Initialize) Start RequestId: ([^\s,]+)`)
+	logEndPattern     = regexp.MustCompile(`^FC (?:Invoke|Initialize) End RequestId: ([^\s,]+)`)
+	logRecordPattern  = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)\s+(\S+)\s+(?:\[(\w+)\]\s?)?(.*)$`)
+	logBillingPattern = regexp.MustCompile(`Duration: ([\d.]+) ms, Billed Duration: ([\d.]+) ms, Memory Size: (\d+) MB, Max Memory Used: ([\d.]+) MB`)
+)
+
+// ParseLogResult parses the decoded log result of an invocation.
+func ParseLogResult(log string) (*InvocationLog, error) {
+	result := &InvocationLog{}
+	started := false
+	for _, line := range strings.Split(strings.ReplaceAll(log, "\r\n", "\n"), "\n") {
+		if m := logBillingPattern.FindStringSubmatch(line); m != nil {
+			billing, err := parseBillingSummary(m)
+			if err != nil {
+				return nil, err
+			}
+			result.Billing = billing
+		}
+		if m := logStartPattern.FindStringSubmatch(line); m != nil {
+			result.RequestID = m[1]
+			started = true
+			continue
+		}
+		if m := logEndPattern.FindStringSubmatch(line); m != nil {
+			if result.RequestID == "" {
+				result.RequestID = m[1]
+			}
+			started = false
+			continue
+		}
+		if logBillingPattern.MatchString(line) || (!started && strings.TrimSpace(line) == "") {
+			continue
+		}
+		if m := logRecordPattern.FindStringSubmatch(line); m != nil {
+			t, err := parseLogTime(m[1])
+			if err == nil {
+				result.Records = append(result.Records, LogRecord{Time: t, RequestID: m[2], Level: m[3], Message: m[4]})
+				continue
+			}
+		}
+		if n := len(result.Records); n > 0 && !result.Records[n-1].Time.IsZero() {
+			result.Records[n-1].Message += "\n" + line
+			continue
+		}
+		result.Records = append(result.Records, LogRecord{RequestID: result.RequestID, Message: line})
+	}
+	// drop the trailing empty line of a log ending with a newline
+	if n := len(result.Records); n > 0 && result.Records[n-1].Time.IsZero() && result.Records[n-1].Message == "" {
+		result.Records = result.Records[:n-1]
+	}
+	return result, nil
+}
+
+func parseLogTime(s string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700", "2006-01-02T15:04:05.999999999"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid log time %s", s)
+}
+
+func parseBillingSummary(m []string) (*BillingSummary, error) {
+	duration, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration %s: %v", m[1], err)
+	}
+	billed, err := strconv.ParseFloat(m[2], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid billed duration %s: %v", m[2], err)
+	}
+	memorySize, err := strconv.Atoi(m[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory size %s: %v", m[3], err)
+	}
+	maxMemoryUsed, err := strconv.ParseFloat(m[4], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max memory used %s: %v", m[4], err)
+	}
+	return &BillingSummary{
+		Duration:        time.Duration(duration * float64(time.Millisecond)),
+		BilledDuration:  time.Duration(billed * float64(time.Millisecond)),
+		MemorySizeMB:    memorySize,
+		MaxMemoryUsedMB: maxMemoryUsed,
+	}, nil
+}
@@ -0,0 +1,76 @@
+package fc
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type LogResultTestSuite struct {
+	suite.Suite
+}
+
+func TestLogResult(t *testing.T) {
+	suite.Run(t, new(LogResultTestSuite))
+}
+
+func (s *LogResultTestSuite) TestParseLogResult() {
+	assert := s.Require()
+
+	log := "FC Invoke Start RequestId: 1ef6b6ff-7f7b\n" +
+		"2019-07-11T07:34:54.226Z 1ef6b6ff-7f7b [INFO] hello world\n" +
+		"Traceback:\n  line 1\n" +
+		"2019-07-11T07:34:54.300Z 1ef6b6ff-7f7b [ERROR] failed\n" +
+		"FC Invoke End RequestId: 1ef6b6ff-7f7b\n" +
+		"\nDuration: 2.06 ms, Billed Duration: 100 ms, Memory Size: 128 MB, Max Memory Used: 9.80 MB\n"
+	result, err := ParseLogResult(log)
+	assert.Nil(err)
+	assert.Equal("1ef6b6ff-7f7b", result.RequestID)
+	assert.Len(result.Records, 2)
+	assert.Equal(LogRecord{
+		Time:      time.Date(2019, 7, 11, 7, 34, 54, 226000000, time.UTC),
+		RequestID: "1ef6b6ff-7f7b",
+		Level:     "INFO",
+		Message:   "hello world\nTraceback:\n  line 1",
+	}, result.Records[0])
+	assert.Equal("ERROR", result.Records[1].Level)
+	assert.Equal(&BillingSummary{
+		Duration:        2060 * time.Microsecond,
+		BilledDuration:  100 * time.Millisecond,
+		MemorySizeMB:    128,
+		MaxMemoryUsedMB: 9.8,
+	}, result.Billing)
+}
+
+func (s *LogResultTestSuite) TestParseLogResultInlineBilling() {
+	assert := s.Require()
+
+	log := "FC Invoke Start RequestId: abc\nplain line\nFC Invoke End RequestId: abc, Duration: 60.24 ms, Billed Duration: 61 ms, Memory Size: 512 MB, Max Memory Used: 14.32 MB"
+	result, err := ParseLogResult(log)
+	assert.Nil(err)
+	assert.Equal("abc", result.RequestID)
+	assert.Equal([]LogRecord{{RequestID: "abc", Message: "plain line"}}, result.Records)
+	assert.Equal(61*time.Millisecond, result.Billing.BilledDuration)
+	assert.Equal(512, result.Billing.MemorySizeMB)
+}
+
+func (s *LogResultTestSuite) TestGetInvocationLog() {
+	assert := s.Require()
+
+	header := http.Header{}
+	header.Set(HTTPHeaderRequestID, "req")
+	header.Set(HTTPHeaderInvocationLogResult, base64.StdEncoding.EncodeToString([]byte("hello\n")))
+	output := InvokeFunctionOutput{Header: header, Payload: []byte("ok")}
+	log, err := output.GetInvocationLog()
+	assert.Nil(err)
+	assert.Equal("req", log.RequestID)
+	assert.Equal([]LogRecord{{Message: "hello"}}, log.Records)
+	assert.Nil(log.Billing)
+
+	header.Set(HTTPHeaderInvocationLogResult, "!!")
+	_, err = output.GetInvocationLog()
+	assert.NotNil(err)
+}
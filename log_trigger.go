@@ -1,5 +1,15 @@
 package fc
 
+import "fmt"
+
+// Ranges of log trigger job config accepted by log service
+const (
+	MinLogTriggerInterval = 3
+	MaxLogTriggerInterval = 600
+	MinLogMaxRetryTime    = 0
+	MaxLogMaxRetryTime    = 100
+)
+
 // LogTriggerConfig ..
 type LogTriggerConfig struct {
 	SourceConfig      *SourceConfig          `json:"sourceConfig"`
@@ -38,6 +48,14 @@ func (ltc *LogTriggerConfig) WithEnable(enable bool) *LogTriggerConfig {
 	return ltc
 }
 
+// Validate checks the job config is in the ranges accepted by log service
+func (ltc *LogTriggerConfig) Validate() error {
+	if ltc.JobConfig != nil {
+		return ltc.JobConfig.Validate()
+	}
+	return nil
+}
+
 // SourceConfig ..
 type SourceConfig struct {
 	Logstore *string `json:"logstore"`
@@ -72,6 +90,19 @@ func (jc *JobConfig) WithTriggerInterval(interval int) *JobConfig {
 	return jc
 }
 
+// Validate checks the trigger interval and max retry time
+func (jc *JobConfig) Validate() error {
+	if jc.TriggerInterval != nil && (*jc.TriggerInterval < MinLogTriggerInterval || *jc.TriggerInterval > MaxLogTriggerInterval) {
+		return fmt.Errorf("Trigger interval %d is invalid, must be in [%d, %d] seconds",
+			*jc.TriggerInterval, MinLogTriggerInterval, MaxLogTriggerInterval)
+	}
+	if jc.MaxRetryTime != nil && (*jc.MaxRetryTime < MinLogMaxRetryTime || *jc.MaxRetryTime > MaxLogMaxRetryTime) {
+		return fmt.Errorf("Max retry time %d is invalid, must be in [%d, %d]",
+			*jc.MaxRetryTime, MinLogMaxRetryTime, MaxLogMaxRetryTime)
+	}
+	return nil
+}
+
 // LogConfig ..
 type JobLogConfig struct {
 	Project  *string `json:"project"`
@@ -1,5 +1,22 @@
 package fc
 
+import "fmt"
+
+// Notify strategies of mns topic trigger
+const (
+	NotifyStrategyBackoffRetry          = "BACKOFF_RETRY"
+	NotifyStrategyExponentialDecayRetry = "EXPONENTIAL_DECAY_RETRY"
+)
+
+// Notify content formats of mns topic trigger
+const (
+	NotifyContentFormatStream = "STREAM"
+	NotifyContentFormatJSON   = "JSON"
+)
+
+// maxFilterTagLength is the max length of a mns message tag
+const maxFilterTagLength = 16
+
 // MnsTopicTriggerConfig ..
 type MnsTopicTriggerConfig struct {
 	FilterTag           *string `json:"filterTag"`
@@ -26,3 +43,27 @@ func (mtc *MnsTopicTriggerConfig) WithNotifyStrategy(notifyStrategy string) *Mns
 	mtc.NotifyStrategy = &notifyStrategy
 	return mtc
 }
+
+// Validate checks the notify strategy, content format and filter tag
+func (mtc *MnsTopicTriggerConfig) Validate() error {
+	if mtc.NotifyStrategy != nil {
+		switch *mtc.NotifyStrategy {
+		case NotifyStrategyBackoffRetry, NotifyStrategyExponentialDecayRetry:
+		default:
+			return fmt.Errorf("Notify strategy %q is invalid, must be %s or %s",
+				*mtc.NotifyStrategy, NotifyStrategyBackoffRetry, NotifyStrategyExponentialDecayRetry)
+		}
+	}
+	if mtc.NotifyContentFormat != nil {
+		switch *mtc.NotifyContentFormat {
+		case NotifyContentFormatStream, NotifyContentFormatJSON:
+		default:
+			return fmt.Errorf("Notify content format %q is invalid, must be %s or %s",
+				*mtc.NotifyContentFormat, NotifyContentFormatStream, NotifyContentFormatJSON)
+		}
+	}
+	if mtc.FilterTag != nil && len(*mtc.FilterTag) > maxFilterTagLength {
+		return fmt.Errorf("Filter tag %q is invalid, must be at most %d characters", *mtc.FilterTag, maxFilterTagLength)
+	}
+	return nil
+}
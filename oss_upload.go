@@ -0,0 +1,276 @@
+package fc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ossHeaderPrefix        = "x-oss-"
+	ossHeaderSecurityToken = "x-oss-security-token"
+)
+
+// ossClient is the minimal OSS client needed to upload code with temporary bucket credentials.
+type ossClient struct {
+	// endpoint is the scheme and host; objects are addressed by virtual host unless pathStyle is set.
+	endpoint   string
+	pathStyle  bool
+	bucket     string
+	creds      Credentials
+	httpClient *http.Client
+}
+
+type ossInitiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type ossCompleteMultipartUpload struct {
+	XMLName xml.Name  `xml:"CompleteMultipartUpload"`
+	Parts   []ossPart `xml:"Part"`
+}
+
+type ossPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// OSSError is an error response of OSS when uploading code, e.g. AccessDenied. Unlike
+// ServiceError it comes from OSS, not Function Compute.
+type OSSError struct {
+	XMLName    xml.Name `xml:"Error" json:"-"`
+	HTTPStatus int      `xml:"-" json:"HttpStatus"`
+	Code       string   `xml:"Code" json:"Code"`
+	Message    string   `xml:"Message" json:"Message"`
+	RequestID  string   `xml:"RequestId" json:"RequestId"`
+	HostID     string   `xml:"HostId" json:"HostId"`
+}
+
+func (e *OSSError) Error() string {
+	return fmt.Sprintf("oss error: status %d, code %s, message %s, request ID %s, host ID %s",
+		e.HTTPStatus, e.Code, e.Message, e.RequestID, e.HostID)
+}
+
+// ossSignature returns the OSS V1 signature of a request.
+func ossSignature(secret, method string, header http.Header, resource string) string {
+	var ossHeaders []string
+	for k, v := range header {
+		if k = strings.ToLower(k); strings.HasPrefix(k, ossHeaderPrefix) && len(v) > 0 {
+			ossHeaders = append(ossHeaders, k+":"+strings.TrimSpace(v[0]))
+		}
+	}
+	sort.Strings(ossHeaders)
+	var b strings.Builder
+	b.WriteString(method + "\n")
+	b.WriteString(header.Get("Content-MD5") + "\n")
+	b.WriteString(header.Get("Content-Type") + "\n")
+	b.WriteString(header.Get("Date") + "\n")
+	for _, h := range ossHeaders {
+		b.WriteString(h + "\n")
+	}
+	b.WriteString(resource)
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(b.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ossResource returns the canonicalized resource of an object with its sub-resources.
+func ossResource(bucket, object string, query url.Values) string {
+	resource := "/" + bucket + "/" + object
+	if len(query) == 0 {
+		return resource
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if v := query.Get(k); v != "" {
+			params = append(params, k+"="+v)
+		} else {
+			params = append(params, k)
+		}
+	}
+	return resource + "?" + strings.Join(params, "&")
+}
+
+func (o *ossClient) objectURL(object string, query url.Values) string {
+	segments := strings.Split(object, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	path := "/" + strings.Join(segments, "/")
+	endpoint := o.endpoint
+	if o.pathStyle {
+		path = "/" + o.bucket + path
+	} else {
+		u, err := url.Parse(o.endpoint)
+		if err == nil {
+			u.Host = o.bucket + "." + u.Host
+			endpoint = u.String()
+		}
+	}
+	u := strings.TrimRight(endpoint, "/") + path
+	if len(query) > 0 {
+		u += "?" + ossQueryString(query)
+	}
+	return u
+}
+
+// ossQueryString encodes the query sorted by key, sending sub-resources without a value, e.g.
+// "uploads", bare.
+func ossQueryString(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	params := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if v := query.Get(k); v != "" {
+			params = append(params, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		} else {
+			params = append(params, url.QueryEscape(k))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func (o *ossClient) do(ctx context.Context, method, object string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, o.objectURL(object, query), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	if o.creds.SecurityToken != "" {
+		req.Header.Set(ossHeaderSecurityToken, o.creds.SecurityToken)
+	}
+	signature := ossSignature(o.creds.AccessKeySecret, method, req.Header, ossResource(o.bucket, object, query))
+	req.Header.Set("Authorization", "OSS "+o.creds.AccessKeyID+":"+signature)
+	req.ContentLength = int64(len(body))
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer resp.Body.Close()
+		data, _ := ioutil.ReadAll(resp.Body)
+		ossErr := &OSSError{}
+		xml.Unmarshal(data, ossErr)
+		ossErr.HTTPStatus = resp.StatusCode
+		return nil, ossErr
+	}
+	return resp, nil
+}
+
+func (o *ossClient) putObject(ctx context.Context, object string, data []byte) error {
+	header := http.Header{"Content-Type": []string{"application/zip"}}
+	resp, err := o.do(ctx, http.MethodPut, object, nil, header, data)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (o *ossClient) initiateMultipartUpload(ctx context.Context, object string) (string, error) {
+	header := http.Header{"Content-Type": []string{"application/zip"}}
+	resp, err := o.do(ctx, http.MethodPost, object, url.Values{"uploads": {""}}, header, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	result := ossInitiateMultipartUploadResult{}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+	if result.UploadID == "" {
+		return "", fmt.Errorf("Upload ID is required but not provided")
+	}
+	return result.UploadID, nil
+}
+
+func (o *ossClient) uploadPart(ctx context.Context, object, uploadID string, number int, data []byte) (ossPart, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+	resp, err := o.do(ctx, http.MethodPut, object, query, nil, data)
+	if err != nil {
+		return ossPart{}, err
+	}
+	resp.Body.Close()
+	return ossPart{PartNumber: number, ETag: resp.Header.Get("ETag")}, nil
+}
+
+func (o *ossClient) completeMultipartUpload(ctx context.Context, object, uploadID string, parts []ossPart) error {
+	body, err := xml.Marshal(ossCompleteMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+	header := http.Header{"Content-Type": []string{"application/xml"}}
+	resp, err := o.do(ctx, http.MethodPost, object, url.Values{"uploadId": {uploadID}}, header, body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (o *ossClient) abortMultipartUpload(ctx context.Context, object, uploadID string) error {
+	resp, err := o.do(ctx, http.MethodDelete, object, url.Values{"uploadId": {uploadID}}, nil, nil)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// multipartUpload uploads the reader in parts of partSize, aborting the upload on failure. An empty
+// reader is uploaded as one empty part, as OSS rejects completing an upload without parts.
+func (o *ossClient) multipartUpload(ctx context.Context, object string, r io.Reader, partSize int64) error {
+	uploadID, err := o.initiateMultipartUpload(ctx, object)
+	if err != nil {
+		return err
+	}
+	parts := []ossPart{}
+	buf := make([]byte, partSize)
+	for number := 1; ; number++ {
+		n, err := io.ReadFull(r, buf)
+		if err == io.EOF && number > 1 {
+			break
+		}
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			o.abortMultipartUpload(ctx, object, uploadID)
+			return err
+		}
+		part, err := o.uploadPart(ctx, object, uploadID, number, buf[:n])
+		if err != nil {
+			o.abortMultipartUpload(ctx, object, uploadID)
+			return err
+		}
+		parts = append(parts, part)
+		if int64(n) < partSize {
+			break
+		}
+	}
+	if err := o.completeMultipartUpload(ctx, object, uploadID, parts); err != nil {
+		o.abortMultipartUpload(ctx, object, uploadID)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package rollout
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// Invoker is the subset of fc.Client the error rate probe uses.
+type Invoker interface {
+	InvokeFunction(input *fc.InvokeFunctionInput) (*fc.InvokeFunctionOutput, error)
+}
+
+// ErrorRateProbe returns a check invoking the function of the new version requests times with the
+// payload and failing when the rate of failed invocations exceeds maxErrorRate.
+func ErrorRateProbe(client Invoker, functionName string, payload []byte, requests int, maxErrorRate float64) Check {
+	return func(ctx context.Context, step StepContext) error {
+		failed := 0
+		var lastErr string
+		for i := 0; i < requests; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			input := fc.NewInvokeFunctionInput(step.ServiceName, functionName).WithQualifier(step.VersionID)
+			if payload != nil {
+				input.WithPayload(payload)
+			}
+			output, err := client.InvokeFunction(input)
+			switch {
+			case err != nil:
+				failed++
+				lastErr = err.Error()
+			case output.GetErrorType() != "":
+				failed++
+				lastErr = output.GetErrorType() + ": " + string(output.Payload)
+			}
+		}
+		if requests > 0 && float64(failed)/float64(requests) > maxErrorRate {
+			return fmt.Errorf("%d of %d invocations of %s failed, last error: %s", failed, requests, functionName, lastErr)
+		}
+		return nil
+	}
+}
+
+// HTTPHealthCheck returns a check sending GET requests to the url built for the step, failing on a
+// transport error or a status of 400 or above.
+func HTTPHealthCheck(client *http.Client, url func(step StepContext) string) Check {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(ctx context.Context, step StepContext) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url(step), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("health check %s returned %d", req.URL, resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,245 @@
+// Package rollout publishes a new service version and shifts an alias to it in weighted canary steps,
+// rolling back to the previous version when a check fails.
+//
+//	r := rollout.New(client, "service", "prod").
+//		WithStep(0.1, time.Minute).
+//		WithStep(0.5, time.Minute).
+//		WithCheck(rollout.ErrorRateProbe(client, "function", nil, 20, 0.05))
+//	result, err := r.Run(ctx)
+package rollout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// Client is the subset of fc.Client the rollout uses.
+type Client interface {
+	PublishServiceVersion(input *fc.PublishServiceVersionInput) (*fc.PublishServiceVersionOutput, error)
+	GetAlias(input *fc.GetAliasInput) (*fc.GetAliasOutput, error)
+	UpdateAlias(input *fc.UpdateAliasInput) (*fc.UpdateAliasOutput, error)
+}
+
+// Step routes Weight (0 to 1) of the alias traffic to the new version, then waits Bake before the checks run.
+type Step struct {
+	Weight float64
+	Bake   time.Duration
+}
+
+// StepContext is passed to checks.
+type StepContext struct {
+	ServiceName       string
+	AliasName         string
+	PreviousVersionID string
+	VersionID         string
+	Step              Step
+}
+
+// Check returns an error when the new version is unhealthy.
+type Check func(ctx context.Context, step StepContext) error
+
+// Rollout moves an alias to a newly published version.
+type Rollout struct {
+	Client      Client
+	ServiceName string
+	AliasName   string
+	Description string
+	Steps       []Step
+	Checks      []Check
+	OnStep      func(step StepContext)
+}
+
+// New returns a rollout of the alias of the service.
+func New(client Client, serviceName, aliasName string) *Rollout {
+	return &Rollout{Client: client, ServiceName: serviceName, AliasName: aliasName}
+}
+
+// WithDescription sets the description of the published version.
+func (r *Rollout) WithDescription(description string) *Rollout {
+	r.Description = description
+	return r
+}
+
+func (r *Rollout) WithStep(weight float64, bake time.Duration) *Rollout {
+	r.Steps = append(r.Steps, Step{Weight: weight, Bake: bake})
+	return r
+}
+
+func (r *Rollout) WithCheck(check Check) *Rollout {
+	r.Checks = append(r.Checks, check)
+	return r
+}
+
+// WithStepHandler sets a callback invoked when a step's weight has been applied.
+func (r *Rollout) WithStepHandler(handler func(step StepContext)) *Rollout {
+	r.OnStep = handler
+	return r
+}
+
+// Validate ...
+func (r *Rollout) Validate() error {
+	if r.Client == nil {
+		return fmt.Errorf("Client is required but not provided")
+	}
+	if r.ServiceName == "" {
+		return fmt.Errorf("Service name is required but not provided")
+	}
+	if r.AliasName == "" {
+		return fmt.Errorf("Alias name is required but not provided")
+	}
+	previous := 0.0
+	for _, s := range r.Steps {
+		if s.Weight <= previous || s.Weight >= 1 {
+			return fmt.Errorf("invalid step weight %v, weights must increase within (0, 1)", s.Weight)
+		}
+		previous = s.Weight
+	}
+	return nil
+}
+
+// Result describes the outcome of a rollout.
+type Result struct {
+	ServiceName       string
+	AliasName         string
+	PreviousVersionID string
+	VersionID         string
+	StepsCompleted    int
+	Completed         bool
+	RolledBack        bool
+}
+
+// Run publishes a version, applies each step and its checks, and finally points the alias at the
+// new version, keeping the weights the alias routes to other versions. When a check fails the
+// alias is restored to the previous version and weights and the check's error is returned along
+// with the result.
+func (r *Rollout) Run(ctx context.Context) (*Result, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	alias, err := r.Client.GetAlias(fc.NewGetAliasInput(r.ServiceName, r.AliasName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alias %s: %v", r.AliasName, err)
+	}
+	if alias.VersionID == nil {
+		return nil, fmt.Errorf("alias %s has no version", r.AliasName)
+	}
+	result := &Result{ServiceName: r.ServiceName, AliasName: r.AliasName, PreviousVersionID: *alias.VersionID}
+	// the weights of other versions the alias already routes to are kept throughout
+	weights := alias.AdditionalVersionWeight
+	other := 0.0
+	for _, w := range weights {
+		other += w
+	}
+	if n := len(r.Steps); n > 0 && other+r.Steps[n-1].Weight > 1 {
+		return nil, fmt.Errorf("alias %s routes %v to other versions, step weight %v would exceed 1", r.AliasName, other, r.Steps[n-1].Weight)
+	}
+
+	publish := fc.NewPublishServiceVersionInput(r.ServiceName)
+	if r.Description != "" {
+		publish.WithDescription(r.Description)
+	}
+	version, err := r.Client.PublishServiceVersion(publish)
+	if err != nil {
+		return result, fmt.Errorf("failed to publish version: %v", err)
+	}
+	if version.VersionID == nil {
+		return result, fmt.Errorf("published version of service %s has no version ID", r.ServiceName)
+	}
+	result.VersionID = *version.VersionID
+	if result.VersionID == result.PreviousVersionID {
+		result.Completed = true
+		return result, nil
+	}
+
+	etag := alias.GetEtag()
+	for _, step := range r.Steps {
+		stepContext := StepContext{
+			ServiceName:       r.ServiceName,
+			AliasName:         r.AliasName,
+			PreviousVersionID: result.PreviousVersionID,
+			VersionID:         result.VersionID,
+			Step:              step,
+		}
+		etag, err = r.update(result.PreviousVersionID, withWeight(weights, result.VersionID, step.Weight), etag)
+		if err != nil {
+			return result, r.rollback(result, weights, etag, fmt.Errorf("failed to shift %v to version %s: %w", step.Weight, result.VersionID, err))
+		}
+		if r.OnStep != nil {
+			r.OnStep(stepContext)
+		}
+		if err := r.bakeAndCheck(ctx, stepContext); err != nil {
+			return result, r.rollback(result, weights, etag, err)
+		}
+		result.StepsCompleted++
+	}
+
+	if _, err := r.update(result.VersionID, withWeight(weights, result.VersionID, 0), etag); err != nil {
+		return result, r.rollback(result, weights, etag, fmt.Errorf("failed to point alias at version %s: %w", result.VersionID, err))
+	}
+	result.Completed = true
+	return result, nil
+}
+
+func (r *Rollout) bakeAndCheck(ctx context.Context, step StepContext) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(step.Step.Bake):
+	}
+	for _, check := range r.Checks {
+		if err := check(ctx, step); err != nil {
+			return fmt.Errorf("check failed at weight %v: %v", step.Step.Weight, err)
+		}
+	}
+	return nil
+}
+
+// update points the alias at the version with the weights, guarded by the etag, and returns the new etag.
+func (r *Rollout) update(versionID string, weights map[string]float64, etag string) (string, error) {
+	input := fc.NewUpdateAliasInput(r.ServiceName, r.AliasName).
+		WithVersionID(versionID).
+		WithAdditionalVersionWeight(weights)
+	if etag != "" {
+		input.WithIfMatch(etag)
+	}
+	output, err := r.Client.UpdateAlias(input)
+	if err != nil {
+		return etag, err
+	}
+	return output.GetEtag(), nil
+}
+
+// withWeight returns a copy of weights routing weight to the version, or none when weight is 0.
+func withWeight(weights map[string]float64, versionID string, weight float64) map[string]float64 {
+	merged := make(map[string]float64, len(weights)+1)
+	for v, w := range weights {
+		merged[v] = w
+	}
+	delete(merged, versionID)
+	if weight > 0 {
+		merged[versionID] = weight
+	}
+	return merged
+}
+
+// rollback restores the previous version and weights unless the alias was modified by someone else.
+func (r *Rollout) rollback(result *Result, weights map[string]float64, etag string, cause error) error {
+	if isPreconditionFailed(cause) {
+		return fmt.Errorf("%v; alias %s was modified concurrently, not rolling back", cause, r.AliasName)
+	}
+	if _, err := r.update(result.PreviousVersionID, withWeight(weights, "", 0), etag); err != nil {
+		return fmt.Errorf("%v; rollback to version %s failed: %v", cause, result.PreviousVersionID, err)
+	}
+	result.RolledBack = true
+	return cause
+}
+
+func isPreconditionFailed(err error) bool {
+	var serviceError *fc.ServiceError
+	return errors.As(err, &serviceError) && serviceError.HTTPStatus == http.StatusPreconditionFailed
+}
@@ -0,0 +1,216 @@
+package rollout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeClient struct {
+	versionID string
+	weights   map[string]float64
+	etag      int
+	published string
+	updates   []string
+	failAt    int
+	noVersion bool
+	invoke    func(input *fc.InvokeFunctionInput) (*fc.InvokeFunctionOutput, error)
+}
+
+func (c *fakeClient) header() http.Header {
+	h := http.Header{}
+	h.Set(fc.HTTPHeaderEtag, fmt.Sprintf("etag-%d", c.etag))
+	return h
+}
+
+func (c *fakeClient) PublishServiceVersion(input *fc.PublishServiceVersionInput) (*fc.PublishServiceVersionOutput, error) {
+	output := &fc.PublishServiceVersionOutput{}
+	if !c.noVersion {
+		output.VersionID = &c.published
+	}
+	return output, nil
+}
+
+func (c *fakeClient) GetAlias(input *fc.GetAliasInput) (*fc.GetAliasOutput, error) {
+	output := &fc.GetAliasOutput{Header: c.header()}
+	versionID := c.versionID
+	output.VersionID = &versionID
+	output.AdditionalVersionWeight = c.weights
+	return output, nil
+}
+
+func (c *fakeClient) UpdateAlias(input *fc.UpdateAliasInput) (*fc.UpdateAliasOutput, error) {
+	if input.IfMatch == nil || *input.IfMatch != fmt.Sprintf("etag-%d", c.etag) {
+		return nil, &fc.ServiceError{HTTPStatus: http.StatusPreconditionFailed, ErrorCode: "PreconditionFailed"}
+	}
+	if c.failAt > 0 && len(c.updates)+1 == c.failAt {
+		c.updates = append(c.updates, "failed")
+		return nil, &fc.ServiceError{HTTPStatus: http.StatusInternalServerError, ErrorCode: "InternalServerError"}
+	}
+	c.versionID = *input.VersionID
+	c.weights = input.AdditionalVersionWeight
+	c.etag++
+	c.updates = append(c.updates, fmt.Sprintf("%s %v", c.versionID, c.weights))
+	return &fc.UpdateAliasOutput{Header: c.header()}, nil
+}
+
+func (c *fakeClient) InvokeFunction(input *fc.InvokeFunctionInput) (*fc.InvokeFunctionOutput, error) {
+	return c.invoke(input)
+}
+
+type RolloutTestSuite struct {
+	suite.Suite
+}
+
+func TestRollout(t *testing.T) {
+	suite.Run(t, new(RolloutTestSuite))
+}
+
+func (s *RolloutTestSuite) TestRunCompletes() {
+	assert := s.Require()
+
+	client := &fakeClient{versionID: "1", published: "2"}
+	var steps []float64
+	r := New(client, "service", "prod").
+		WithStep(0.1, 0).
+		WithStep(0.5, 0).
+		WithCheck(func(ctx context.Context, step StepContext) error {
+			assert.Equal("1", step.PreviousVersionID)
+			assert.Equal("2", step.VersionID)
+			return nil
+		}).
+		WithStepHandler(func(step StepContext) { steps = append(steps, step.Step.Weight) })
+	result, err := r.Run(context.Background())
+	assert.Nil(err)
+	assert.True(result.Completed)
+	assert.False(result.RolledBack)
+	assert.Equal(2, result.StepsCompleted)
+	assert.Equal([]float64{0.1, 0.5}, steps)
+	assert.Equal([]string{"1 map[2:0.1]", "1 map[2:0.5]", "2 map[]"}, client.updates)
+}
+
+func (s *RolloutTestSuite) TestRunRollsBackOnProbeFailure() {
+	assert := s.Require()
+
+	client := &fakeClient{versionID: "1", published: "2"}
+	client.invoke = func(input *fc.InvokeFunctionInput) (*fc.InvokeFunctionOutput, error) {
+		assert.Equal("2", *input.Qualifier)
+		header := http.Header{}
+		header.Set(fc.HTTPHeaderFCErrorType, "UnhandledInvocationError")
+		return &fc.InvokeFunctionOutput{Header: header, Payload: []byte("boom")}, nil
+	}
+	r := New(client, "service", "prod").
+		WithStep(0.2, time.Millisecond).
+		WithCheck(ErrorRateProbe(client, "function", []byte("{}"), 5, 0.5))
+	result, err := r.Run(context.Background())
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "5 of 5 invocations of function failed")
+	assert.True(result.RolledBack)
+	assert.False(result.Completed)
+	assert.Equal("1", client.versionID)
+	assert.Equal([]string{"1 map[2:0.2]", "1 map[]"}, client.updates)
+}
+
+func (s *RolloutTestSuite) TestRunUpdateFailure() {
+	assert := s.Require()
+
+	client := &fakeClient{versionID: "1", published: "2", failAt: 2}
+	result, err := New(client, "service", "prod").WithStep(0.1, 0).WithStep(0.5, 0).Run(context.Background())
+	assert.NotNil(err)
+	assert.True(result.RolledBack)
+	assert.Equal([]string{"1 map[2:0.1]", "failed", "1 map[]"}, client.updates)
+
+	// a concurrent modification is not overwritten
+	client = &fakeClient{versionID: "1", published: "2"}
+	r := New(client, "service", "prod").WithStep(0.1, 0).WithCheck(func(ctx context.Context, step StepContext) error {
+		client.etag++
+		return nil
+	})
+	result, err = r.Run(context.Background())
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "modified concurrently")
+	assert.False(result.RolledBack)
+}
+
+func (s *RolloutTestSuite) TestRunKeepsOtherWeights() {
+	assert := s.Require()
+
+	client := &fakeClient{versionID: "1", weights: map[string]float64{"0": 0.2}, published: "2"}
+	result, err := New(client, "service", "prod").WithStep(0.1, 0).Run(context.Background())
+	assert.Nil(err)
+	assert.True(result.Completed)
+	assert.Equal([]string{"1 map[0:0.2 2:0.1]", "2 map[0:0.2]"}, client.updates)
+
+	client = &fakeClient{versionID: "1", weights: map[string]float64{"0": 0.2}, published: "2"}
+	r := New(client, "service", "prod").WithStep(0.1, 0).WithCheck(func(ctx context.Context, step StepContext) error {
+		return fmt.Errorf("unhealthy")
+	})
+	result, err = r.Run(context.Background())
+	assert.NotNil(err)
+	assert.True(result.RolledBack)
+	assert.Equal([]string{"1 map[0:0.2 2:0.1]", "1 map[0:0.2]"}, client.updates)
+
+	client = &fakeClient{versionID: "1", weights: map[string]float64{"0": 0.6}, published: "2"}
+	_, err = New(client, "service", "prod").WithStep(0.5, 0).Run(context.Background())
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "would exceed 1")
+	assert.Empty(client.updates)
+}
+
+func (s *RolloutTestSuite) TestRunNoPublishedVersion() {
+	assert := s.Require()
+
+	client := &fakeClient{versionID: "1", noVersion: true}
+	_, err := New(client, "service", "prod").WithStep(0.5, 0).Run(context.Background())
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "has no version ID")
+	assert.Empty(client.updates)
+}
+
+func (s *RolloutTestSuite) TestRunNoChanges() {
+	assert := s.Require()
+
+	client := &fakeClient{versionID: "1", published: "1"}
+	result, err := New(client, "service", "prod").WithStep(0.5, 0).Run(context.Background())
+	assert.Nil(err)
+	assert.True(result.Completed)
+	assert.Empty(client.updates)
+}
+
+func (s *RolloutTestSuite) TestValidate() {
+	assert := s.Require()
+
+	client := &fakeClient{}
+	assert.NotNil(New(nil, "service", "prod").Validate())
+	assert.NotNil(New(client, "", "prod").Validate())
+	assert.NotNil(New(client, "service", "").Validate())
+	assert.NotNil(New(client, "service", "prod").WithStep(0.5, 0).WithStep(0.2, 0).Validate())
+	assert.NotNil(New(client, "service", "prod").WithStep(1, 0).Validate())
+	assert.Nil(New(client, "service", "prod").WithStep(0.2, 0).Validate())
+}
+
+func (s *RolloutTestSuite) TestHTTPHealthCheck() {
+	assert := s.Require()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/health" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	check := HTTPHealthCheck(nil, func(step StepContext) string { return server.URL + "/" + step.VersionID + "/health" })
+	assert.Nil(check(context.Background(), StepContext{VersionID: "2"}))
+	assert.NotNil(check(context.Background(), StepContext{VersionID: "3"}))
+
+	failing := ErrorRateProbe(&fakeClient{invoke: func(input *fc.InvokeFunctionInput) (*fc.InvokeFunctionOutput, error) {
+		return nil, errors.New("network")
+	}}, "function", nil, 10, 1)
+	assert.Nil(failing(context.Background(), StepContext{VersionID: "2"}))
+}
@@ -0,0 +1,127 @@
+package runtime
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers the platform sets on every request to a custom runtime
+const (
+	HeaderRequestID             = "X-Fc-Request-Id"
+	HeaderAccessKeyID           = "X-Fc-Access-Key-Id"
+	HeaderAccessKeySecret       = "X-Fc-Access-Key-Secret"
+	HeaderSecurityToken         = "X-Fc-Security-Token"
+	HeaderFunctionName          = "X-Fc-Function-Name"
+	HeaderFunctionHandler       = "X-Fc-Function-Handler"
+	HeaderFunctionMemory        = "X-Fc-Function-Memory"
+	HeaderFunctionTimeout       = "X-Fc-Function-Timeout"
+	HeaderFunctionInitializer   = "X-Fc-Function-Initializer"
+	HeaderInitializationTimeout = "X-Fc-Initialization-Timeout"
+	HeaderServiceName           = "X-Fc-Service-Name"
+	HeaderServiceLogProject     = "X-Fc-Service-Logproject"
+	HeaderServiceLogStore       = "X-Fc-Service-Logstore"
+	HeaderQualifier             = "X-Fc-Qualifier"
+	HeaderVersionID             = "X-Fc-Version-Id"
+	HeaderRegion                = "X-Fc-Region"
+	HeaderAccountID             = "X-Fc-Account-Id"
+
+	// HeaderStatus is set on a response to report an unhandled function error
+	HeaderStatus = "X-Fc-Status"
+)
+
+// Credentials are the temporary credentials of the service role.
+type Credentials struct {
+	AccessKeyID     string
+	AccessKeySecret string
+	SecurityToken   string
+}
+
+// FunctionMeta describes the invoked function.
+type FunctionMeta struct {
+	Name                  string
+	Handler               string
+	Memory                int
+	Timeout               int
+	Initializer           string
+	InitializationTimeout int
+}
+
+// ServiceMeta describes the service of the invoked function.
+type ServiceMeta struct {
+	Name       string
+	LogProject string
+	LogStore   string
+	Qualifier  string
+	VersionID  string
+}
+
+// Context is the invocation context handlers receive.
+type Context struct {
+	context.Context
+
+	RequestID   string
+	Credentials Credentials
+	Function    FunctionMeta
+	Service     ServiceMeta
+	Region      string
+	AccountID   string
+}
+
+// NewContext builds a Context from the x-fc-* headers of a request to the runtime.
+func NewContext(parent context.Context, header http.Header) *Context {
+	return &Context{
+		Context:   parent,
+		RequestID: header.Get(HeaderRequestID),
+		Credentials: Credentials{
+			AccessKeyID:     header.Get(HeaderAccessKeyID),
+			AccessKeySecret: header.Get(HeaderAccessKeySecret),
+			SecurityToken:   header.Get(HeaderSecurityToken),
+		},
+		Function: FunctionMeta{
+			Name:                  header.Get(HeaderFunctionName),
+			Handler:               header.Get(HeaderFunctionHandler),
+			Memory:                headerInt(header, HeaderFunctionMemory),
+			Timeout:               headerInt(header, HeaderFunctionTimeout),
+			Initializer:           header.Get(HeaderFunctionInitializer),
+			InitializationTimeout: headerInt(header, HeaderInitializationTimeout),
+		},
+		Service: ServiceMeta{
+			Name:       header.Get(HeaderServiceName),
+			LogProject: header.Get(HeaderServiceLogProject),
+			LogStore:   header.Get(HeaderServiceLogStore),
+			Qualifier:  header.Get(HeaderQualifier),
+			VersionID:  header.Get(HeaderVersionID),
+		},
+		Region:    header.Get(HeaderRegion),
+		AccountID: header.Get(HeaderAccountID),
+	}
+}
+
+// withTimeout bounds the context by the given timeout in seconds, if positive.
+func (c *Context) withTimeout(seconds int) (*Context, context.CancelFunc) {
+	if seconds <= 0 {
+		return c, func() {}
+	}
+	ctx, cancel := context.WithTimeout(c.Context, time.Duration(seconds)*time.Second)
+	copied := *c
+	copied.Context = ctx
+	return &copied, cancel
+}
+
+type contextKey struct{}
+
+// FromContext returns the invocation Context stored in ctx, e.g. the request context of an http.Handler.
+func FromContext(ctx context.Context) (*Context, bool) {
+	if c, ok := ctx.(*Context); ok {
+		return c, true
+	}
+	c, ok := ctx.Value(contextKey{}).(*Context)
+	return c, ok
+}
+
+func headerInt(header http.Header, key string) int {
+	v, _ := strconv.Atoi(header.Get(key))
+	return v
+}
@@ -0,0 +1,146 @@
+package runtime
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type RuntimeTestSuite struct {
+	suite.Suite
+}
+
+func TestRuntime(t *testing.T) {
+	suite.Run(t, new(RuntimeTestSuite))
+}
+
+func invokeRequest(path, method, body string) *http.Request {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set(HeaderRequestID, "req-1")
+	r.Header.Set(HeaderAccessKeyID, "ak")
+	r.Header.Set(HeaderAccessKeySecret, "sk")
+	r.Header.Set(HeaderSecurityToken, "token")
+	r.Header.Set(HeaderFunctionName, "fn")
+	r.Header.Set(HeaderFunctionMemory, "512")
+	r.Header.Set(HeaderFunctionTimeout, "3")
+	r.Header.Set(HeaderServiceName, "svc")
+	r.Header.Set(HeaderQualifier, "LATEST")
+	r.Header.Set(HeaderRegion, "cn-hangzhou")
+	return r
+}
+
+func (s *RuntimeTestSuite) TestNewContext() {
+	assert := s.Require()
+
+	ctx := NewContext(invokeRequest(PathInvoke, http.MethodPost, "").Context(), invokeRequest(PathInvoke, http.MethodPost, "").Header)
+	assert.Equal("req-1", ctx.RequestID)
+	assert.Equal(Credentials{AccessKeyID: "ak", AccessKeySecret: "sk", SecurityToken: "token"}, ctx.Credentials)
+	assert.Equal("fn", ctx.Function.Name)
+	assert.Equal(512, ctx.Function.Memory)
+	assert.Equal(3, ctx.Function.Timeout)
+	assert.Equal("svc", ctx.Service.Name)
+	assert.Equal("LATEST", ctx.Service.Qualifier)
+	assert.Equal("cn-hangzhou", ctx.Region)
+}
+
+func (s *RuntimeTestSuite) TestEventHandler() {
+	assert := s.Require()
+
+	server := NewServer().WithEventHandler(func(ctx *Context, event []byte) ([]byte, error) {
+		deadline, ok := ctx.Deadline()
+		assert.True(ok)
+		assert.True(time.Until(deadline) <= 3*time.Second)
+		return []byte(ctx.RequestID + ":" + string(event)), nil
+	})
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, invokeRequest(PathInvoke, http.MethodPost, "hello"))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("req-1:hello", w.Body.String())
+}
+
+func (s *RuntimeTestSuite) TestEventHandlerError() {
+	assert := s.Require()
+
+	server := NewServer().WithEventHandler(func(ctx *Context, event []byte) ([]byte, error) {
+		if string(event) == "panic" {
+			panic("boom")
+		}
+		return nil, errors.New("failed")
+	})
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, invokeRequest(PathInvoke, http.MethodPost, "error"))
+	assert.Empty(w.Header().Get(HeaderStatus))
+	resp := &ErrorResponse{}
+	assert.Nil(json.Unmarshal(w.Body.Bytes(), resp))
+	assert.Equal(ErrorResponse{ErrorMessage: "failed", ErrorType: "HandledError"}, *resp)
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, invokeRequest(PathInvoke, http.MethodPost, "panic"))
+	assert.Equal("404", w.Header().Get(HeaderStatus))
+	assert.Nil(json.Unmarshal(w.Body.Bytes(), resp))
+	assert.Equal(ErrorResponse{ErrorMessage: "panic: boom", ErrorType: "UnhandledError"}, *resp)
+}
+
+func (s *RuntimeTestSuite) TestLifecycleHooks() {
+	assert := s.Require()
+
+	var called []string
+	hook := func(name string) LifecycleHandler {
+		return func(ctx *Context) error {
+			called = append(called, name+":"+ctx.RequestID)
+			return nil
+		}
+	}
+	server := NewServer().
+		WithEventHandler(func(ctx *Context, event []byte) ([]byte, error) { return nil, nil }).
+		WithInitializer(hook("init")).
+		WithPreFreeze(hook("freeze")).
+		WithPreStop(func(ctx *Context) error { return errors.New("stop failed") })
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, invokeRequest(PathInitialize, http.MethodPost, ""))
+	assert.Equal(http.StatusOK, w.Code)
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, invokeRequest(PathPreFreeze, http.MethodGet, ""))
+	assert.Equal(http.StatusOK, w.Code)
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, invokeRequest(PathPreStop, http.MethodGet, ""))
+	assert.Equal(http.StatusInternalServerError, w.Code)
+	assert.Empty(w.Header().Get(HeaderStatus))
+	assert.Equal([]string{"init:req-1", "freeze:req-1"}, called)
+}
+
+func (s *RuntimeTestSuite) TestHTTPHandler() {
+	assert := s.Require()
+
+	server := NewServer().WithHTTPHandler(HTTPHandler(func(ctx *Context, w http.ResponseWriter, r *http.Request) {
+		fromRequest, ok := FromContext(r.Context())
+		assert.True(ok)
+		assert.Equal(ctx.RequestID, fromRequest.RequestID)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(r.Method + " " + r.URL.Path + " " + ctx.Function.Name))
+	}))
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, invokeRequest("/users/1", http.MethodPut, ""))
+	assert.Equal(http.StatusCreated, w.Code)
+	assert.Equal("PUT /users/1 fn", w.Body.String())
+
+	w = httptest.NewRecorder()
+	NewServer().WithEventHandler(func(ctx *Context, event []byte) ([]byte, error) { return nil, nil }).
+		ServeHTTP(w, invokeRequest("/users/1", http.MethodGet, ""))
+	assert.Equal(http.StatusNotFound, w.Code)
+}
+
+func (s *RuntimeTestSuite) TestStartUnsupportedHandler() {
+	assert := s.Require()
+
+	assert.NotNil(Start("handler"))
+	assert.NotNil(NewServer().ListenAndServe())
+}
@@ -0,0 +1,230 @@
+// Package runtime implements the custom runtime protocol so Go functions only provide handlers.
+//
+//	func main() {
+//		runtime.Start(func(ctx *runtime.Context, event []byte) ([]byte, error) {
+//			return []byte("hello " + ctx.RequestID), nil
+//		})
+//	}
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// Paths of the custom runtime protocol
+const (
+	PathInvoke     = "/invoke"
+	PathInitialize = "/initialize"
+	PathPreFreeze  = "/pre-freeze"
+	PathPreStop    = "/pre-stop"
+)
+
+// EnvServerPort is the environment variable holding the CAPort of the function.
+const EnvServerPort = "FC_SERVER_PORT"
+
+// EventHandler handles an event function invocation.
+type EventHandler func(ctx *Context, event []byte) ([]byte, error)
+
+// HTTPHandler handles an http trigger request.
+type HTTPHandler func(ctx *Context, w http.ResponseWriter, r *http.Request)
+
+// LifecycleHandler handles the initializer and the pre-freeze and pre-stop hooks.
+type LifecycleHandler func(ctx *Context) error
+
+// ErrorResponse is the body written when a handler fails.
+type ErrorResponse struct {
+	ErrorMessage string `json:"errorMessage"`
+	ErrorType    string `json:"errorType"`
+}
+
+// Server serves the custom runtime protocol.
+type Server struct {
+	Address     string
+	Event       EventHandler
+	HTTP        http.Handler
+	Initializer LifecycleHandler
+	PreFreeze   LifecycleHandler
+	PreStop     LifecycleHandler
+}
+
+// NewServer returns a server listening on the port from FC_SERVER_PORT, or
+// fc.DefaultCustomRuntimePort.
+func NewServer() *Server {
+	port := os.Getenv(EnvServerPort)
+	if port == "" {
+		port = fmt.Sprintf("%d", fc.DefaultCustomRuntimePort)
+	}
+	return &Server{Address: net.JoinHostPort("0.0.0.0", port)}
+}
+
+func (s *Server) WithAddress(address string) *Server {
+	s.Address = address
+	return s
+}
+
+func (s *Server) WithEventHandler(handler EventHandler) *Server {
+	s.Event = handler
+	return s
+}
+
+// WithHTTPHandler serves http trigger requests. The invocation Context is available through FromContext.
+func (s *Server) WithHTTPHandler(handler http.Handler) *Server {
+	s.HTTP = handler
+	return s
+}
+
+func (s *Server) WithInitializer(handler LifecycleHandler) *Server {
+	s.Initializer = handler
+	return s
+}
+
+func (s *Server) WithPreFreeze(handler LifecycleHandler) *Server {
+	s.PreFreeze = handler
+	return s
+}
+
+func (s *Server) WithPreStop(handler LifecycleHandler) *Server {
+	s.PreStop = handler
+	return s
+}
+
+// ServeHTTP dispatches a request from the platform to the configured handlers.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := NewContext(r.Context(), r.Header)
+	switch {
+	case r.URL.Path == PathInitialize && r.Method == http.MethodPost:
+		ctx, cancel := ctx.withTimeout(ctx.Function.InitializationTimeout)
+		defer cancel()
+		s.serveLifecycle(ctx, w, s.Initializer)
+	case r.URL.Path == PathPreFreeze && r.Method == http.MethodGet:
+		s.serveLifecycle(ctx, w, s.PreFreeze)
+	case r.URL.Path == PathPreStop && r.Method == http.MethodGet:
+		s.serveLifecycle(ctx, w, s.PreStop)
+	case r.URL.Path == PathInvoke && r.Method == http.MethodPost && s.Event != nil:
+		ctx, cancel := ctx.withTimeout(ctx.Function.Timeout)
+		defer cancel()
+		s.serveEvent(ctx, w, r)
+	case s.HTTP != nil:
+		ctx, cancel := ctx.withTimeout(ctx.Function.Timeout)
+		defer cancel()
+		s.serveHTTP(ctx, w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) serveLifecycle(ctx *Context, w http.ResponseWriter, handler LifecycleHandler) {
+	if handler != nil {
+		if err := s.call(ctx, func() error { return handler(ctx) }); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) serveEvent(ctx *Context, w http.ResponseWriter, r *http.Request) {
+	event, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var result []byte
+	err = s.call(ctx, func() error {
+		var err error
+		result, err = s.Event(ctx, event)
+		return err
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(result)
+}
+
+func (s *Server) serveHTTP(ctx *Context, w http.ResponseWriter, r *http.Request) {
+	r = r.WithContext(context.WithValue(ctx, contextKey{}, ctx))
+	err := s.call(ctx, func() error {
+		s.HTTP.ServeHTTP(w, r)
+		return nil
+	})
+	if err != nil {
+		writeError(w, err)
+	}
+}
+
+// call runs fn, turning a panic into an error so the instance keeps serving.
+func (s *Server) call(ctx *Context, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{value: r}
+		}
+	}()
+	return fn()
+}
+
+// ListenAndServe listens on the server address and serves the custom runtime protocol.
+func (s *Server) ListenAndServe() error {
+	if s.Event == nil && s.HTTP == nil {
+		return fmt.Errorf("Event or HTTP handler is required but not provided")
+	}
+	return http.ListenAndServe(s.Address, s)
+}
+
+// Start serves the handler on the function's CAPort and only returns on failure.
+// The handler is an EventHandler, an HTTPHandler or an http.Handler.
+func Start(handler interface{}) error {
+	s := NewServer()
+	switch h := handler.(type) {
+	case EventHandler:
+		s.WithEventHandler(h)
+	case func(*Context, []byte) ([]byte, error):
+		s.WithEventHandler(EventHandler(h))
+	case HTTPHandler:
+		s.WithHTTPHandler(h)
+	case func(*Context, http.ResponseWriter, *http.Request):
+		s.WithHTTPHandler(HTTPHandler(h))
+	case http.Handler:
+		s.WithHTTPHandler(h)
+	default:
+		return fmt.Errorf("unsupported handler type %T", handler)
+	}
+	return s.ListenAndServe()
+}
+
+// ServeHTTP lets an HTTPHandler be used as an http.Handler behind a Server.
+func (h HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, ok := FromContext(r.Context())
+	if !ok {
+		ctx = NewContext(r.Context(), r.Header)
+	}
+	h(ctx, w, r)
+}
+
+type panicError struct {
+	value interface{}
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	errorType := "HandledError"
+	if _, ok := err.(*panicError); ok {
+		errorType = "UnhandledError"
+		w.Header().Set(HeaderStatus, "404")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(&ErrorResponse{ErrorMessage: err.Error(), ErrorType: errorType})
+}
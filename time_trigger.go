@@ -1,5 +1,12 @@
 package fc
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/aliyun/fc-go-sdk/cron"
+)
+
 // TimeTriggerConfig defines the time trigger config
 type TimeTriggerConfig struct {
 	Payload        *string `json:"payload"`
@@ -17,6 +24,7 @@ func (ttc *TimeTriggerConfig) WithPayload(payload string) *TimeTriggerConfig {
 	return ttc
 }
 
+// WithCronExpression sets the schedule, e.g. "0 0 4 * * *", "@every 5m" or "CRON_TZ=Asia/Shanghai 0 0 8 * * *"
 func (ttc *TimeTriggerConfig) WithCronExpression(cronExpression string) *TimeTriggerConfig {
 	ttc.CronExpression = &cronExpression
 	return ttc
@@ -26,3 +34,29 @@ func (ttc *TimeTriggerConfig) WithEnable(enable bool) *TimeTriggerConfig {
 	ttc.Enable = &enable
 	return ttc
 }
+
+// Validate checks the cron expression, if set, against the dialect accepted by timer triggers
+func (ttc *TimeTriggerConfig) Validate() error {
+	if ttc.CronExpression == nil {
+		return nil
+	}
+	return cron.Validate(*ttc.CronExpression)
+}
+
+// NextFireTimes returns the next n times the trigger fires after from.
+// Intervals of "@every" expressions are counted from the given time. In a CRON_TZ time zone, times
+// in the hour repeated when daylight saving time ends fire only in its first occurrence; none fire
+// in the second, even when from is inside it.
+func (ttc *TimeTriggerConfig) NextFireTimes(n int, from time.Time) ([]time.Time, error) {
+	if IsBlank(ttc.CronExpression) {
+		return nil, fmt.Errorf("Cron expression is required but not provided")
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("invalid number of fire times %d", n)
+	}
+	schedule, err := cron.Parse(*ttc.CronExpression)
+	if err != nil {
+		return nil, err
+	}
+	return cron.NextN(schedule, n, from), nil
+}
@@ -0,0 +1,87 @@
+package fc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestTimeTriggerConfig(t *testing.T) {
+	suite.Run(t, new(TimeTriggerConfigTestSuite))
+}
+
+type TimeTriggerConfigTestSuite struct {
+	suite.Suite
+}
+
+func (s *TimeTriggerConfigTestSuite) TestValidateOnCreate() {
+	assert := s.Require()
+
+	input := NewCreateTriggerInput("service", "function").WithTriggerName("timer").
+		WithTriggerType(TRIGGER_TYPE_TIMER).
+		WithTypedTriggerConfig(NewTimeTriggerConfig().WithCronExpression("0 0 4 * * *").WithEnable(true))
+	assert.Nil(input.Validate())
+
+	input.WithTypedTriggerConfig(NewTimeTriggerConfig().WithCronExpression("0 4 * * *"))
+	assert.NotNil(input.Validate())
+
+	input.WithTypedTriggerConfig(NewTimeTriggerConfig().WithCronExpression("@every 10s"))
+	assert.NotNil(input.Validate())
+}
+
+func (s *TimeTriggerConfigTestSuite) TestValidateOnUpdate() {
+	assert := s.Require()
+
+	input := NewUpdateTriggerInput("service", "function", "timer").
+		WithTriggerConfig(NewTimeTriggerConfig().WithEnable(false))
+	assert.Nil(input.Validate())
+
+	input.WithTriggerConfig(NewTimeTriggerConfig().WithCronExpression("CRON_TZ=Nowhere 0 0 4 * * *"))
+	assert.NotNil(input.Validate())
+}
+
+func (s *TimeTriggerConfigTestSuite) TestNextFireTimes() {
+	assert := s.Require()
+
+	from := time.Date(2021, time.March, 1, 10, 15, 30, 0, time.UTC)
+	times, err := NewTimeTriggerConfig().WithCronExpression("0 0/30 * * * *").NextFireTimes(3, from)
+	assert.Nil(err)
+	assert.Equal([]time.Time{
+		time.Date(2021, time.March, 1, 10, 30, 0, 0, time.UTC),
+		time.Date(2021, time.March, 1, 11, 0, 0, 0, time.UTC),
+		time.Date(2021, time.March, 1, 11, 30, 0, 0, time.UTC),
+	}, times)
+
+	_, err = NewTimeTriggerConfig().NextFireTimes(3, from)
+	assert.NotNil(err)
+
+	_, err = NewTimeTriggerConfig().WithCronExpression("0 0/30 * * * *").NextFireTimes(-1, from)
+	assert.NotNil(err)
+	times, err = NewTimeTriggerConfig().WithCronExpression("0 0/30 * * * *").NextFireTimes(0, from)
+	assert.Nil(err)
+	assert.Empty(times)
+}
+
+func (s *TimeTriggerConfigTestSuite) TestNextFireTimesDaylightSavingFallBack() {
+	assert := s.Require()
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		s.T().Skip("time zone database not available")
+	}
+
+	// 01:00 to 02:00 happens twice on 2021-11-07, 05:00 to 06:00 UTC in EDT then 06:00 to 07:00 in EST
+	config := NewTimeTriggerConfig().WithCronExpression("CRON_TZ=America/New_York 0 0/30 * * * *")
+	times, err := config.NextFireTimes(4, time.Date(2021, time.November, 7, 4, 45, 0, 0, time.UTC))
+	assert.Nil(err)
+	assert.Equal([]time.Time{
+		time.Date(2021, time.November, 7, 5, 0, 0, 0, time.UTC),
+		time.Date(2021, time.November, 7, 5, 30, 0, 0, time.UTC),
+		time.Date(2021, time.November, 7, 7, 0, 0, 0, time.UTC),
+		time.Date(2021, time.November, 7, 7, 30, 0, 0, time.UTC),
+	}, times)
+
+	// nothing fires in the second occurrence, even from inside it
+	times, err = config.NextFireTimes(1, time.Date(2021, time.November, 7, 6, 10, 0, 0, time.UTC))
+	assert.Nil(err)
+	assert.Equal([]time.Time{time.Date(2021, time.November, 7, 7, 0, 0, 0, time.UTC)}, times)
+}
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+
+	"github.com/aliyun/fc-go-sdk/arn"
 )
 
 const (
@@ -36,6 +39,9 @@ type TriggerCreateObject struct {
 	TriggerConfig  *TriggerConfig `json:"triggerConfig"`
 	Qualifier      *string        `json:"qualifier"`
 
+	// TypedTriggerConfig, when set, is sent as the trigger config instead of TriggerConfig.
+	TypedTriggerConfig interface{} `json:"-"`
+
 	err error `json:"-"`
 }
 
@@ -88,6 +94,24 @@ func (i *CreateTriggerInput) WithTriggerConfig(config TriggerConfig) *CreateTrig
 	return i
 }
 
+// WithTypedTriggerConfig sets the config of the trigger type, such as *TimeTriggerConfig or
+// *OSSTriggerConfig, which is validated by Validate and sent instead of TriggerConfig.
+func (i *CreateTriggerInput) WithTypedTriggerConfig(config interface{}) *CreateTriggerInput {
+	i.TypedTriggerConfig = config
+	return i
+}
+
+// triggerConfig returns the config sent with the trigger.
+func (i *CreateTriggerInput) triggerConfig() interface{} {
+	if i.TypedTriggerConfig != nil {
+		return i.TypedTriggerConfig
+	}
+	if i.TriggerConfig != nil {
+		return i.TriggerConfig
+	}
+	return nil
+}
+
 func (i *CreateTriggerInput) WithHeader(key, value string) *CreateTriggerInput {
 	if i.headers == nil {
 		i.headers = make(Header)
@@ -113,7 +137,14 @@ func (i *CreateTriggerInput) GetHeaders() Header {
 }
 
 func (i *CreateTriggerInput) GetPayload() interface{} {
-	return i.TriggerCreateObject
+	if i.TypedTriggerConfig == nil {
+		return i.TriggerCreateObject
+	}
+	// the outer field takes precedence over the embedded one when marshaling
+	return struct {
+		TriggerCreateObject
+		TriggerConfig interface{} `json:"triggerConfig"`
+	}{i.TriggerCreateObject, i.TypedTriggerConfig}
 }
 
 func (i *CreateTriggerInput) Validate() error {
@@ -126,6 +157,125 @@ func (i *CreateTriggerInput) Validate() error {
 	if i.err != nil {
 		return i.err
 	}
+	if i.TriggerType != nil && !IsBlank(i.SourceARN) {
+		if err := validateSourceARN(*i.TriggerType, *i.SourceARN); err != nil {
+			return err
+		}
+	}
+	if !IsBlank(i.InvocationRole) {
+		if err := validateInvocationRole(*i.InvocationRole); err != nil {
+			return err
+		}
+	} else if i.TriggerType != nil && triggerTypeRequiresRole(*i.TriggerType) {
+		return fmt.Errorf("Invocation role is required by %s trigger but not provided", *i.TriggerType)
+	}
+	if i.TriggerType != nil {
+		if err := validateTriggerConfigType(*i.TriggerType, i.triggerConfig()); err != nil {
+			return err
+		}
+	}
+	if err := validateTriggerConfig(i.triggerConfig()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// triggerTypeRequiresRole reports whether the event source needs a role to invoke the function.
+func triggerTypeRequiresRole(triggerType string) bool {
+	switch triggerType {
+	case TRIGGER_TYPE_OSS, TRIGGER_TYPE_LOG, TRIGGER_TYPE_MNS_TOPIC, TRIGGER_TYPE_TABLESTORE, TRIGGER_TYPE_CDN_EVENTS:
+		return true
+	}
+	return false
+}
+
+// validateTriggerConfigType checks a typed trigger config matches the trigger type.
+func validateTriggerConfigType(triggerType string, config interface{}) error {
+	expected := ""
+	switch config.(type) {
+	case *OSSTriggerConfig:
+		expected = TRIGGER_TYPE_OSS
+	case *LogTriggerConfig:
+		expected = TRIGGER_TYPE_LOG
+	case *TimeTriggerConfig:
+		expected = TRIGGER_TYPE_TIMER
+	case *HTTPTriggerConfig:
+		expected = TRIGGER_TYPE_HTTP
+	case *TableStoreTriggerConfig:
+		expected = TRIGGER_TYPE_TABLESTORE
+	case *CDNEventsTriggerConfig:
+		expected = TRIGGER_TYPE_CDN_EVENTS
+	case *MnsTopicTriggerConfig:
+		expected = TRIGGER_TYPE_MNS_TOPIC
+	case *EventBridgeTriggerConfig:
+		expected = TRIGGER_TYPE_EVENTBRIDGE
+	default:
+		return nil
+	}
+	if expected != triggerType {
+		return fmt.Errorf("Trigger config %T does not match trigger type %s", config, triggerType)
+	}
+	return nil
+}
+
+// validateSourceARN checks the source ARN has the shape expected by the trigger type.
+// Source ARNs of http and timer triggers are ignored by the service and not checked.
+func validateSourceARN(triggerType, sourceARN string) error {
+	switch triggerType {
+	case TRIGGER_TYPE_OSS, TRIGGER_TYPE_LOG, TRIGGER_TYPE_MNS_TOPIC, TRIGGER_TYPE_TABLESTORE,
+		TRIGGER_TYPE_CDN_EVENTS, TRIGGER_TYPE_EVENTBRIDGE:
+	default:
+		return nil
+	}
+	a, err := arn.Parse(sourceARN)
+	if err != nil {
+		return fmt.Errorf("Source ARN of %s trigger is invalid: %v", triggerType, err)
+	}
+	switch triggerType {
+	case TRIGGER_TYPE_OSS:
+		_, err = a.OSSBucket()
+	case TRIGGER_TYPE_LOG:
+		_, err = a.LogProject()
+	case TRIGGER_TYPE_MNS_TOPIC:
+		_, err = a.MNSTopic()
+	case TRIGGER_TYPE_TABLESTORE:
+		_, _, err = a.TablestoreTable()
+	case TRIGGER_TYPE_CDN_EVENTS:
+		_, err = a.CDN()
+	case TRIGGER_TYPE_EVENTBRIDGE:
+		_, _, err = a.EventRule()
+	}
+	if err != nil {
+		return fmt.Errorf("Source ARN of %s trigger is invalid: %v", triggerType, err)
+	}
+	return nil
+}
+
+// validateInvocationRole checks the invocation role is a RAM role ARN.
+func validateInvocationRole(role string) error {
+	a, err := arn.Parse(role)
+	if err == nil {
+		_, err = a.RAMRole()
+	}
+	if err != nil {
+		return fmt.Errorf("Invocation role is invalid: %v", err)
+	}
+	return nil
+}
+
+// triggerConfigValidator is implemented by trigger configs which can be checked on client side.
+type triggerConfigValidator interface {
+	Validate() error
+}
+
+// validateTriggerConfig validates the config if it knows how to validate itself.
+func validateTriggerConfig(config interface{}) error {
+	if p, ok := config.(*interface{}); ok && p != nil {
+		config = *p
+	}
+	if v, ok := config.(triggerConfigValidator); ok {
+		return v.Validate()
+	}
 	return nil
 }
 
@@ -166,6 +316,11 @@ func (o CreateTriggerOutput) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// Validate checks the auth type and methods
+func (c TriggerConfig) Validate() error {
+	return validateHTTPTrigger(c.AuthType, c.Methods)
+}
+
 type triggerMetadata struct {
 	TriggerName      *string       `json:"triggerName"`
 	Description      *string       `json:"description"`
@@ -304,6 +459,101 @@ func (c *OSSTriggerConfig) WithFilterKeySuffix(suffix string) *OSSTriggerConfig
 	return c
 }
 
+// Validate checks the events are known oss events without overlaps and the key filter is well formed
+func (c *OSSTriggerConfig) Validate() error {
+	if len(c.Events) == 0 {
+		return fmt.Errorf("OSS trigger events are required but not provided")
+	}
+	for i, e := range c.Events {
+		if !isValidOSSEvent(e) {
+			return fmt.Errorf("OSS event %q is invalid", e)
+		}
+		for _, other := range c.Events[:i] {
+			if ossEventsOverlap(e, other) {
+				return fmt.Errorf("OSS events %q and %q overlap", other, e)
+			}
+		}
+	}
+	if c.Filter != nil && c.Filter.Key != nil {
+		for _, v := range []*string{c.Filter.Key.Prefix, c.Filter.Key.Suffix} {
+			if v != nil && strings.ContainsAny(*v, "*?") {
+				return fmt.Errorf("OSS filter %q is invalid, wildcards are not supported", *v)
+			}
+		}
+		if c.Filter.Key.Prefix != nil && strings.HasPrefix(*c.Filter.Key.Prefix, "/") {
+			return fmt.Errorf("OSS filter prefix %q is invalid, object keys do not start with '/'", *c.Filter.Key.Prefix)
+		}
+	}
+	return nil
+}
+
+// ConflictsWith reports whether both configs would fire on the same object event.
+// OSS rejects triggers on the same bucket whose events and key filters overlap.
+func (c *OSSTriggerConfig) ConflictsWith(other *OSSTriggerConfig) bool {
+	eventsOverlap := false
+	for _, e := range c.Events {
+		for _, o := range other.Events {
+			if ossEventsOverlap(e, o) {
+				eventsOverlap = true
+			}
+		}
+	}
+	if !eventsOverlap {
+		return false
+	}
+	prefix, suffix := c.keyFilter()
+	otherPrefix, otherSuffix := other.keyFilter()
+	prefixOverlap := strings.HasPrefix(prefix, otherPrefix) || strings.HasPrefix(otherPrefix, prefix)
+	suffixOverlap := strings.HasSuffix(suffix, otherSuffix) || strings.HasSuffix(otherSuffix, suffix)
+	return prefixOverlap && suffixOverlap
+}
+
+func (c *OSSTriggerConfig) keyFilter() (prefix, suffix string) {
+	if c.Filter == nil || c.Filter.Key == nil {
+		return "", ""
+	}
+	if c.Filter.Key.Prefix != nil {
+		prefix = *c.Filter.Key.Prefix
+	}
+	if c.Filter.Key.Suffix != nil {
+		suffix = *c.Filter.Key.Suffix
+	}
+	return prefix, suffix
+}
+
+func isValidOSSEvent(event string) bool {
+	switch OSSEvent(event) {
+	case OSSEventObjectCreatedAll,
+		OSSEventObjectCreatedPutObject,
+		OSSEventObjectCreatedPutSymlink,
+		OSSEventObjectCreatedPostObject,
+		OSSEventObjectCreatedCopyObject,
+		OSSEventObjectCreatedInitiateMultipartUpload,
+		OSSEventObjectCreatedUploadPart,
+		OSSEventObjectCreatedUploadPartCopy,
+		OSSEventObjectCreatedCompleteMultipartUpload,
+		OSSEventObjectCreatedAppendObject,
+		OSSEventObjectRemovedDeleteObject,
+		OSSEventObjectRemovedDeleteObjects,
+		OSSEventObjectRemovedAbortMultipartUpload,
+		OSSEventObjectReplicationObjectCreated,
+		OSSEventObjectReplicationObjectRemoved,
+		OSSEventObjectReplicationObjectModified:
+		return true
+	}
+	return false
+}
+
+// ossEventsOverlap reports whether two events are equal or one is the wildcard covering the other.
+func ossEventsOverlap(a, b string) bool {
+	if a == b {
+		return true
+	}
+	wildcard := string(OSSEventObjectCreatedAll)
+	created := strings.TrimSuffix(wildcard, "*")
+	return (a == wildcard && strings.HasPrefix(b, created)) || (b == wildcard && strings.HasPrefix(a, created))
+}
+
 type OSSTriggerFilter struct {
 	Key *OSSTriggerKey `json:"key"`
 }
@@ -529,6 +779,14 @@ func (i *UpdateTriggerInput) Validate() error {
 	if i.err != nil {
 		return i.err
 	}
+	if !IsBlank(i.InvocationRole) {
+		if err := validateInvocationRole(*i.InvocationRole); err != nil {
+			return err
+		}
+	}
+	if err := validateTriggerConfig(i.TriggerConfig); err != nil {
+		return err
+	}
 	return nil
 }
 
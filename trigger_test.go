@@ -0,0 +1,186 @@
+package fc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func TestTriggerStructs(t *testing.T) {
+	suite.Run(t, new(TriggerStructsTestSuite))
+}
+
+type TriggerStructsTestSuite struct {
+	suite.Suite
+}
+
+func (s *TriggerStructsTestSuite) TestSourceARN() {
+	assert := s.Require()
+
+	for triggerType, sourceARN := range map[string]string{
+		TRIGGER_TYPE_OSS:         "acs:oss:cn-shanghai:123:bucket",
+		TRIGGER_TYPE_LOG:         "acs:log:cn-shanghai:123:project/x",
+		TRIGGER_TYPE_MNS_TOPIC:   "acs:mns:cn-shanghai:123:/topics/x",
+		TRIGGER_TYPE_TABLESTORE:  "acs:ots:cn-shanghai:123:instance/x/table/y",
+		TRIGGER_TYPE_CDN_EVENTS:  "acs:cdn:*:123",
+		TRIGGER_TYPE_EVENTBRIDGE: "acs:eventbridge:cn-shanghai:123:eventbus/default/rule/r",
+		TRIGGER_TYPE_HTTP:        "dummy_arn",
+		TRIGGER_TYPE_TIMER:       "dummy_arn",
+	} {
+		input := NewCreateTriggerInput("service", "function").WithTriggerName("t").
+			WithTriggerType(triggerType).WithSourceARN(sourceARN).WithInvocationRole("acs:ram::123:role/fc-invoke")
+		assert.Nil(input.Validate(), triggerType)
+	}
+
+	for triggerType, sourceARN := range map[string]string{
+		TRIGGER_TYPE_OSS:         "acs:log:cn-shanghai:123:project/x",
+		TRIGGER_TYPE_LOG:         "acs:log:cn-shanghai:123:x",
+		TRIGGER_TYPE_MNS_TOPIC:   "acs:mns:cn-shanghai:123:topics/x",
+		TRIGGER_TYPE_TABLESTORE:  "acs:ots:cn-shanghai:123:instance/x",
+		TRIGGER_TYPE_CDN_EVENTS:  "cdn",
+		TRIGGER_TYPE_EVENTBRIDGE: "acs:eventbridge:cn-shanghai:123:eventbus/default",
+	} {
+		input := NewCreateTriggerInput("service", "function").WithTriggerName("t").
+			WithTriggerType(triggerType).WithSourceARN(sourceARN).WithInvocationRole("acs:ram::123:role/fc-invoke")
+		assert.NotNil(input.Validate(), triggerType)
+	}
+}
+
+func (s *TriggerStructsTestSuite) TestInvocationRoleARN() {
+	assert := s.Require()
+
+	input := NewCreateTriggerInput("service", "function").WithTriggerName("t").
+		WithTriggerType(TRIGGER_TYPE_OSS).WithSourceARN("acs:oss:cn-shanghai:123:bucket").
+		WithInvocationRole("acs:ram::123:role/fc-invoke")
+	assert.Nil(input.Validate())
+
+	input.WithInvocationRole("fc-invoke")
+	assert.NotNil(input.Validate())
+
+	update := NewUpdateTriggerInput("service", "function", "t").WithInvocationRole("acs:ram::123:user/u")
+	assert.NotNil(update.Validate())
+}
+
+func (s *TriggerStructsTestSuite) TestInvocationRoleRequired() {
+	assert := s.Require()
+
+	input := NewCreateTriggerInput("service", "function").WithTriggerName("t").
+		WithTriggerType(TRIGGER_TYPE_LOG).WithSourceARN("acs:log:cn-shanghai:123:project/x")
+	assert.NotNil(input.Validate())
+
+	input = NewCreateTriggerInput("service", "function").WithTriggerName("t").
+		WithTriggerType(TRIGGER_TYPE_HTTP).WithTypedTriggerConfig(NewHTTPTriggerConfig().WithAuthType(AuthAnonymous))
+	assert.Nil(input.Validate())
+}
+
+func (s *TriggerStructsTestSuite) TestConfigTypeMismatch() {
+	assert := s.Require()
+
+	input := NewCreateTriggerInput("service", "function").WithTriggerName("t").
+		WithTriggerType(TRIGGER_TYPE_TIMER).WithTypedTriggerConfig(NewHTTPTriggerConfig().WithAuthType(AuthAnonymous))
+	assert.NotNil(input.Validate())
+}
+
+func (s *TriggerStructsTestSuite) TestCreateTriggerPayload() {
+	assert := s.Require()
+
+	input := NewCreateTriggerInput("service", "function").WithTriggerName("t").
+		WithTriggerType(TRIGGER_TYPE_HTTP).WithTriggerConfig(TriggerConfig{AuthType: AuthAnonymous, Methods: []string{"GET"}})
+	assert.Equal([]string{"GET"}, input.TriggerConfig.Methods)
+	b, err := json.Marshal(input.GetPayload())
+	assert.Nil(err)
+	assert.Contains(string(b), `"triggerConfig":{"methods":["GET"],"authType":"anonymous","disableURLInternet":false}`)
+
+	input.WithTriggerType(TRIGGER_TYPE_TIMER).WithTypedTriggerConfig(NewTimeTriggerConfig().WithCronExpression("0 0 4 * * *"))
+	b, err = json.Marshal(input.GetPayload())
+	assert.Nil(err)
+	assert.Contains(string(b), `"triggerConfig":{"payload":null,"cronExpression":"0 0 4 * * *"`)
+	assert.Contains(string(b), `"triggerName":"t"`)
+}
+
+func (s *TriggerStructsTestSuite) TestOSSTriggerConfig() {
+	assert := s.Require()
+
+	assert.Nil(NewOSSTriggerConfig().WithEvents([]string{
+		string(OSSEventObjectCreatedPutObject), string(OSSEventObjectRemovedDeleteObject),
+	}).WithFilterKeyPrefix("images/").WithFilterKeySuffix(".jpg").Validate())
+
+	assert.NotNil(NewOSSTriggerConfig().Validate())
+	assert.NotNil(NewOSSTriggerConfig().WithEvents([]string{"oss:ObjectCreated:Put"}).Validate())
+	assert.NotNil(NewOSSTriggerConfig().WithEvents([]string{
+		string(OSSEventObjectCreatedAll), string(OSSEventObjectCreatedPutObject),
+	}).Validate())
+	assert.NotNil(NewOSSTriggerConfig().WithEvents([]string{
+		string(OSSEventObjectCreatedPutObject), string(OSSEventObjectCreatedPutObject),
+	}).Validate())
+	assert.NotNil(NewOSSTriggerConfig().WithEvents([]string{string(OSSEventObjectCreatedAll)}).
+		WithFilterKeyPrefix("images/*").Validate())
+	assert.NotNil(NewOSSTriggerConfig().WithEvents([]string{string(OSSEventObjectCreatedAll)}).
+		WithFilterKeyPrefix("/images").Validate())
+}
+
+func (s *TriggerStructsTestSuite) TestOSSTriggerConflicts() {
+	assert := s.Require()
+
+	created := NewOSSTriggerConfig().WithEvents([]string{string(OSSEventObjectCreatedAll)}).
+		WithFilterKeyPrefix("images/").WithFilterKeySuffix(".jpg")
+	put := NewOSSTriggerConfig().WithEvents([]string{string(OSSEventObjectCreatedPutObject)}).
+		WithFilterKeyPrefix("images/thumbs/")
+	removed := NewOSSTriggerConfig().WithEvents([]string{string(OSSEventObjectRemovedDeleteObject)})
+	videos := NewOSSTriggerConfig().WithEvents([]string{string(OSSEventObjectCreatedAll)}).
+		WithFilterKeyPrefix("videos/")
+	png := NewOSSTriggerConfig().WithEvents([]string{string(OSSEventObjectCreatedAll)}).
+		WithFilterKeyPrefix("images/").WithFilterKeySuffix(".png")
+
+	assert.True(created.ConflictsWith(put))
+	assert.True(put.ConflictsWith(created))
+	assert.False(created.ConflictsWith(removed))
+	assert.False(created.ConflictsWith(videos))
+	assert.False(created.ConflictsWith(png))
+}
+
+func (s *TriggerStructsTestSuite) TestHTTPTriggerConfig() {
+	assert := s.Require()
+
+	assert.Nil(NewHTTPTriggerConfig().WithAuthType(AuthFunction).WithMethods("GET", "POST").Validate())
+	assert.NotNil(NewHTTPTriggerConfig().WithAuthType("token").Validate())
+	assert.NotNil(NewHTTPTriggerConfig().WithMethods("GET", "FETCH").Validate())
+	assert.NotNil(NewHTTPTriggerConfig().WithMethods("get").Validate())
+	assert.NotNil(NewHTTPTriggerConfig().WithMethods("GET", "GET").Validate())
+
+	input := NewCreateTriggerInput("service", "function").WithTriggerName("t").WithTriggerType(TRIGGER_TYPE_HTTP).
+		WithTriggerConfig(TriggerConfig{AuthType: AuthAnonymous, Methods: []string{"TRACE"}})
+	assert.NotNil(input.Validate())
+}
+
+func (s *TriggerStructsTestSuite) TestLogTriggerConfig() {
+	assert := s.Require()
+
+	assert.Nil(NewLogTriggerConfig().WithJobConfig(NewJobConfig().WithTriggerInterval(60).WithMaxRetryTime(10)).Validate())
+	assert.NotNil(NewLogTriggerConfig().WithJobConfig(NewJobConfig().WithTriggerInterval(2)).Validate())
+	assert.NotNil(NewLogTriggerConfig().WithJobConfig(NewJobConfig().WithTriggerInterval(601)).Validate())
+	assert.NotNil(NewLogTriggerConfig().WithJobConfig(NewJobConfig().WithMaxRetryTime(-1)).Validate())
+	assert.NotNil(NewLogTriggerConfig().WithJobConfig(NewJobConfig().WithMaxRetryTime(101)).Validate())
+}
+
+func (s *TriggerStructsTestSuite) TestMnsTopicTriggerConfig() {
+	assert := s.Require()
+
+	assert.Nil(NewMnsTopicTriggerConfig().WithNotifyStrategy(NotifyStrategyBackoffRetry).
+		WithNotifyContentFormat(NotifyContentFormatJSON).WithFilterTag("tag").Validate())
+	assert.NotNil(NewMnsTopicTriggerConfig().WithNotifyStrategy("RETRY").Validate())
+	assert.NotNil(NewMnsTopicTriggerConfig().WithNotifyContentFormat("XML").Validate())
+	assert.NotNil(NewMnsTopicTriggerConfig().WithFilterTag("a-tag-longer-than-16").Validate())
+}
+
+func (s *TriggerStructsTestSuite) TestEventBridgeTriggerConfig() {
+	assert := s.Require()
+
+	input := NewCreateTriggerInput("service", "function").WithTriggerName("t").WithTriggerType(TRIGGER_TYPE_EVENTBRIDGE).
+		WithTypedTriggerConfig(NewEventBridgeTriggerConfig().WithEventRuleFilterPattern(`{"source": ["acs.oss"]}`))
+	assert.Nil(input.Validate())
+
+	input.WithTypedTriggerConfig(NewEventBridgeTriggerConfig().WithEventRuleFilterPattern(`{"source": "acs.oss"`))
+	assert.NotNil(input.Validate())
+}
@@ -0,0 +1,401 @@
+// Package versiongc deletes service versions that nothing references.
+//
+// A version is kept when an alias points at it or weights traffic to it, when a provision config,
+// on-demand config, async invoke config or trigger uses it as qualifier (directly or through an
+// alias), when it is among the newest KeepLast versions, or when it is younger than MinAge.
+//
+//	report, err := versiongc.New(client, "service").WithKeepLast(10).WithMinAge(72 * time.Hour).WithDryRun().Run()
+package versiongc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// Client is the subset of fc.Client the collector uses.
+type Client interface {
+	ListServiceVersions(input *fc.ListServiceVersionsInput) (*fc.ListServiceVersionsOutput, error)
+	ListAliases(input *fc.ListAliasesInput) (*fc.ListAliasesOutput, error)
+	ListFunctions(input *fc.ListFunctionsInput) (*fc.ListFunctionsOutput, error)
+	ListTriggers(input *fc.ListTriggersInput) (*fc.ListTriggersOutput, error)
+	ListProvisionConfigs(input *fc.ListProvisionConfigsInput) (*fc.ListProvisionConfigsOutput, error)
+	ListOnDemandConfigs(input *fc.ListOnDemandConfigsInput) (*fc.ListOnDemandConfigsOutput, error)
+	ListFunctionAsyncInvokeConfigs(input *fc.ListFunctionAsyncInvokeConfigsInput) (*fc.ListFunctionAsyncInvokeConfigsOutput, error)
+	DeleteServiceVersion(input *fc.DeleteServiceVersionInput) (*fc.DeleteServiceVersionOutput, error)
+}
+
+// Actions taken on a version
+const (
+	ActionKeep    = "keep"
+	ActionDelete  = "delete"
+	ActionDeleted = "deleted"
+	ActionFailed  = "failed"
+)
+
+// Collector finds and deletes the unreferenced versions of a service.
+type Collector struct {
+	Client      Client
+	ServiceName string
+	KeepLast    int
+	MinAge      time.Duration
+	DryRun      bool
+
+	now func() time.Time
+}
+
+// New returns a collector for the service.
+func New(client Client, serviceName string) *Collector {
+	return &Collector{Client: client, ServiceName: serviceName, now: time.Now}
+}
+
+// WithKeepLast keeps the newest n versions regardless of references.
+func (c *Collector) WithKeepLast(n int) *Collector {
+	c.KeepLast = n
+	return c
+}
+
+// WithMinAge keeps versions created less than age ago.
+func (c *Collector) WithMinAge(age time.Duration) *Collector {
+	c.MinAge = age
+	return c
+}
+
+// WithDryRun reports the versions that would be deleted without deleting them.
+func (c *Collector) WithDryRun() *Collector {
+	c.DryRun = true
+	return c
+}
+
+// Validate ...
+func (c *Collector) Validate() error {
+	if c.Client == nil {
+		return fmt.Errorf("Client is required but not provided")
+	}
+	if c.ServiceName == "" {
+		return fmt.Errorf("Service name is required but not provided")
+	}
+	if c.KeepLast < 0 || c.MinAge < 0 {
+		return fmt.Errorf("invalid keep last %d or min age %s", c.KeepLast, c.MinAge)
+	}
+	return nil
+}
+
+// Report lists every version of the service with the action taken and why.
+type Report struct {
+	ServiceName string          `json:"serviceName"`
+	DryRun      bool            `json:"dryRun"`
+	Versions    []VersionReport `json:"versions"`
+}
+
+// VersionReport ...
+type VersionReport struct {
+	VersionID   string   `json:"versionId"`
+	CreatedTime string   `json:"createdTime"`
+	Action      string   `json:"action"`
+	Reasons     []string `json:"reasons,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// Deleted returns the IDs of versions deleted, or to be deleted on a dry run.
+func (r *Report) Deleted() []string {
+	var ids []string
+	for _, v := range r.Versions {
+		if v.Action == ActionDelete || v.Action == ActionDeleted {
+			ids = append(ids, v.VersionID)
+		}
+	}
+	return ids
+}
+
+func (r *Report) String() string {
+	b, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// Run plans the collection and, unless dry running, deletes the unreferenced versions.
+// Deletion failures are recorded on the report and do not stop the run.
+func (c *Collector) Run() (*Report, error) {
+	report, err := c.Plan()
+	if err != nil || c.DryRun {
+		return report, err
+	}
+	for i, v := range report.Versions {
+		if v.Action != ActionDelete {
+			continue
+		}
+		if _, err := c.Client.DeleteServiceVersion(fc.NewDeleteServiceVersionInput(c.ServiceName, v.VersionID)); err != nil {
+			report.Versions[i].Action = ActionFailed
+			report.Versions[i].Error = err.Error()
+			continue
+		}
+		report.Versions[i].Action = ActionDeleted
+	}
+	return report, nil
+}
+
+// Plan decides the action for every version without deleting anything.
+func (c *Collector) Plan() (*Report, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	versions, err := c.listVersions()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := c.references()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{ServiceName: c.ServiceName, DryRun: c.DryRun}
+	for i, v := range versions {
+		id := *v.VersionID
+		created := ""
+		if v.CreatedTime != nil {
+			created = *v.CreatedTime
+		}
+		reasons := refs[id]
+		if i < c.KeepLast {
+			reasons = append(reasons, fmt.Sprintf("among the last %d versions", c.KeepLast))
+		}
+		if c.MinAge > 0 {
+			t, err := parseCreatedTime(created)
+			if err != nil {
+				reasons = append(reasons, "unknown created time")
+			} else if age := c.now().Sub(t); age < c.MinAge {
+				reasons = append(reasons, fmt.Sprintf("younger than %s", c.MinAge))
+			}
+		}
+		action := ActionDelete
+		if len(reasons) > 0 {
+			action = ActionKeep
+		}
+		report.Versions = append(report.Versions, VersionReport{VersionID: id, CreatedTime: created, Action: action, Reasons: reasons})
+	}
+	return report, nil
+}
+
+// createdTimeLayout is the layout of the created time of versions, e.g. 2021-01-02T15:04:05.000+0000.
+const createdTimeLayout = "2006-01-02T15:04:05.000Z0700"
+
+// parseCreatedTime parses the created time of a version, falling back to RFC 3339.
+func parseCreatedTime(s string) (time.Time, error) {
+	if t, err := time.Parse(createdTimeLayout, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// version is a listed version.
+type version struct {
+	VersionID   *string
+	CreatedTime *string
+}
+
+// listVersions returns all versions, newest first.
+func (c *Collector) listVersions() ([]version, error) {
+	var versions []version
+	input := fc.NewListServiceVersionsInput(c.ServiceName).WithLimit(100)
+	for {
+		output, err := c.Client.ListServiceVersions(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions: %v", err)
+		}
+		for _, v := range output.Versions {
+			if v.VersionID != nil {
+				versions = append(versions, version{VersionID: v.VersionID, CreatedTime: v.CreatedTime})
+			}
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		input.WithNextToken(*output.NextToken)
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versionNumber(*versions[i].VersionID) > versionNumber(*versions[j].VersionID)
+	})
+	return versions, nil
+}
+
+func versionNumber(id string) int64 {
+	n, _ := strconv.ParseInt(id, 10, 64)
+	return n
+}
+
+// references maps version IDs to the reasons they are kept.
+func (c *Collector) references() (map[string][]string, error) {
+	refs := map[string][]string{}
+	aliases := map[string][]string{}
+
+	aliasInput := fc.NewListAliasesInput(c.ServiceName).WithLimit(100)
+	for {
+		output, err := c.Client.ListAliases(aliasInput)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list aliases: %v", err)
+		}
+		for _, a := range output.Aliases {
+			if a.AliasName == nil {
+				continue
+			}
+			if a.VersionID != nil {
+				aliases[*a.AliasName] = append(aliases[*a.AliasName], *a.VersionID)
+				refs[*a.VersionID] = append(refs[*a.VersionID], "alias "+*a.AliasName)
+			}
+			for id := range a.AdditionalVersionWeight {
+				aliases[*a.AliasName] = append(aliases[*a.AliasName], id)
+				refs[id] = append(refs[id], "additional version of alias "+*a.AliasName)
+			}
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		aliasInput.WithNextToken(*output.NextToken)
+	}
+
+	// qualifier references resolve aliases to their versions
+	reference := func(qualifier, reason string) {
+		if qualifier == "" || qualifier == "LATEST" {
+			return
+		}
+		if ids, ok := aliases[qualifier]; ok {
+			for _, id := range ids {
+				refs[id] = append(refs[id], reason+" through alias "+qualifier)
+			}
+			return
+		}
+		refs[qualifier] = append(refs[qualifier], reason)
+	}
+
+	provisionInput := fc.NewListProvisionConfigsInput().WithServiceName(c.ServiceName).WithLimit(100)
+	for {
+		output, err := c.Client.ListProvisionConfigs(provisionInput)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list provision configs: %v", err)
+		}
+		for _, p := range output.ProvisionConfigs {
+			if p.Resource == nil {
+				continue
+			}
+			if qualifier, function, ok := parseProvisionResource(*p.Resource, c.ServiceName); ok {
+				reference(qualifier, "provision config of "+function)
+			}
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		provisionInput.WithNextToken(*output.NextToken)
+	}
+
+	onDemandInput := fc.NewListOnDemandConfigsInput().WithPrefix("services/" + c.ServiceName + ".")
+	for {
+		output, err := c.Client.ListOnDemandConfigs(onDemandInput)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list on-demand configs: %v", err)
+		}
+		for _, o := range output.Configs {
+			if o.Resource == nil {
+				continue
+			}
+			if qualifier, function, ok := parseOnDemandResource(*o.Resource, c.ServiceName); ok {
+				reference(qualifier, "on-demand config of "+function)
+			}
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		onDemandInput.WithNextToken(*output.NextToken)
+	}
+
+	functions, err := c.listFunctions()
+	if err != nil {
+		return nil, err
+	}
+	for _, function := range functions {
+		asyncInput := fc.NewListFunctionAsyncInvokeConfigsInput(c.ServiceName, function).WithLimit(100)
+		for {
+			output, err := c.Client.ListFunctionAsyncInvokeConfigs(asyncInput)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list async invoke configs of %s: %v", function, err)
+			}
+			for _, a := range output.Configs {
+				if a.Qualifier != nil {
+					reference(*a.Qualifier, "async invoke config of "+function)
+				}
+			}
+			if output.NextToken == nil || *output.NextToken == "" {
+				break
+			}
+			asyncInput.WithNextToken(*output.NextToken)
+		}
+
+		triggerInput := fc.NewListTriggersInput(c.ServiceName, function).WithLimit(100)
+		for {
+			output, err := c.Client.ListTriggers(triggerInput)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list triggers of %s: %v", function, err)
+			}
+			for _, t := range output.Triggers {
+				if t.Qualifier != nil && t.TriggerName != nil {
+					reference(*t.Qualifier, "trigger "+*t.TriggerName+" of "+function)
+				}
+			}
+			if output.NextToken == nil || *output.NextToken == "" {
+				break
+			}
+			triggerInput.WithNextToken(*output.NextToken)
+		}
+	}
+	return refs, nil
+}
+
+func (c *Collector) listFunctions() ([]string, error) {
+	var functions []string
+	input := fc.NewListFunctionsInput(c.ServiceName).WithLimit(100)
+	for {
+		output, err := c.Client.ListFunctions(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list functions: %v", err)
+		}
+		for _, f := range output.Functions {
+			if f.FunctionName != nil {
+				functions = append(functions, *f.FunctionName)
+			}
+		}
+		if output.NextToken == nil || *output.NextToken == "" {
+			break
+		}
+		input.WithNextToken(*output.NextToken)
+	}
+	return functions, nil
+}
+
+// parseProvisionResource parses "<accountID>#<service>#<qualifier>#<function>".
+func parseProvisionResource(resource, serviceName string) (qualifier, function string, ok bool) {
+	parts := strings.Split(resource, "#")
+	if len(parts) != 4 || parts[1] != serviceName {
+		return "", "", false
+	}
+	return parts[2], parts[3], true
+}
+
+// parseOnDemandResource parses "services/<service>.<qualifier>/functions/<function>".
+func parseOnDemandResource(resource, serviceName string) (qualifier, function string, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(resource, "/"), "/")
+	if len(parts) != 4 || parts[0] != "services" || parts[2] != "functions" {
+		return "", "", false
+	}
+	prefix := serviceName + "."
+	if !strings.HasPrefix(parts[1], prefix) {
+		return "", "", false
+	}
+	return strings.TrimPrefix(parts[1], prefix), parts[3], true
+}
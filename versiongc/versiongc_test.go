@@ -0,0 +1,215 @@
+package versiongc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+	"github.com/stretchr/testify/suite"
+)
+
+// fakeClient serves JSON fixtures, paging versions two at a time.
+type fakeClient struct {
+	versions []string
+	deleted  []string
+	failing  string
+}
+
+func unmarshal(s string, v interface{}) {
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		panic(err)
+	}
+}
+
+func (c *fakeClient) ListServiceVersions(input *fc.ListServiceVersionsInput) (*fc.ListServiceVersionsOutput, error) {
+	start := 0
+	if input.NextToken != nil {
+		unmarshal(*input.NextToken, &start)
+	}
+	end := start + 2
+	if end > len(c.versions) {
+		end = len(c.versions)
+	}
+	output := &fc.ListServiceVersionsOutput{}
+	unmarshal(`{"versions":[`+joinJSON(c.versions[start:end])+`]}`, output)
+	if end < len(c.versions) {
+		next, _ := json.Marshal(end)
+		token := string(next)
+		output.NextToken = &token
+	}
+	return output, nil
+}
+
+func joinJSON(items []string) string {
+	s := ""
+	for i, item := range items {
+		if i > 0 {
+			s += ","
+		}
+		s += item
+	}
+	return s
+}
+
+func (c *fakeClient) ListAliases(input *fc.ListAliasesInput) (*fc.ListAliasesOutput, error) {
+	output := &fc.ListAliasesOutput{}
+	unmarshal(`{"aliases":[{"aliasName":"prod","versionId":"3","additionalVersionWeight":{"5":0.1}},{"aliasName":"staging","versionId":"7"}]}`, output)
+	return output, nil
+}
+
+func (c *fakeClient) ListFunctions(input *fc.ListFunctionsInput) (*fc.ListFunctionsOutput, error) {
+	output := &fc.ListFunctionsOutput{}
+	unmarshal(`{"functions":[{"functionName":"f1"}]}`, output)
+	return output, nil
+}
+
+func (c *fakeClient) ListTriggers(input *fc.ListTriggersInput) (*fc.ListTriggersOutput, error) {
+	output := &fc.ListTriggersOutput{}
+	unmarshal(`{"triggers":[{"triggerName":"t1","triggerType":"timer","qualifier":"2","triggerConfig":{}}]}`, output)
+	return output, nil
+}
+
+func (c *fakeClient) ListProvisionConfigs(input *fc.ListProvisionConfigsInput) (*fc.ListProvisionConfigsOutput, error) {
+	output := &fc.ListProvisionConfigsOutput{}
+	unmarshal(`{"provisionConfigs":[{"resource":"123#service#staging#f1","target":1},{"resource":"123#other#1#f1","target":1}]}`, output)
+	return output, nil
+}
+
+func (c *fakeClient) ListOnDemandConfigs(input *fc.ListOnDemandConfigsInput) (*fc.ListOnDemandConfigsOutput, error) {
+	output := &fc.ListOnDemandConfigsOutput{}
+	unmarshal(`{"configs":[{"resource":"services/service.8/functions/f1","maximumInstanceCount":1}]}`, output)
+	return output, nil
+}
+
+func (c *fakeClient) ListFunctionAsyncInvokeConfigs(input *fc.ListFunctionAsyncInvokeConfigsInput) (*fc.ListFunctionAsyncInvokeConfigsOutput, error) {
+	output := &fc.ListFunctionAsyncInvokeConfigsOutput{}
+	unmarshal(`{"configs":[{"function":"f1","qualifier":"4"},{"function":"f1","qualifier":"LATEST"}]}`, output)
+	return output, nil
+}
+
+func (c *fakeClient) DeleteServiceVersion(input *fc.DeleteServiceVersionInput) (*fc.DeleteServiceVersionOutput, error) {
+	if *input.VersionID == c.failing {
+		return nil, errors.New("in use")
+	}
+	c.deleted = append(c.deleted, *input.VersionID)
+	return &fc.DeleteServiceVersionOutput{}, nil
+}
+
+func newFakeClient() *fakeClient {
+	c := &fakeClient{}
+	for i := 1; i <= 10; i++ {
+		c.versions = append(c.versions, fmt.Sprintf(`{"versionId":"%d","createdTime":"2021-01-%02dT00:00:00.000+0000"}`, i, i))
+	}
+	return c
+}
+
+type VersionGCTestSuite struct {
+	suite.Suite
+}
+
+func TestVersionGC(t *testing.T) {
+	suite.Run(t, new(VersionGCTestSuite))
+}
+
+func (s *VersionGCTestSuite) TestPlan() {
+	assert := s.Require()
+
+	client := newFakeClient()
+	c := New(client, "service").WithKeepLast(2).WithDryRun()
+	report, err := c.Run()
+	assert.Nil(err)
+	assert.Empty(client.deleted)
+	assert.True(report.DryRun)
+	assert.Len(report.Versions, 10)
+	assert.Equal("10", report.Versions[0].VersionID)
+	// 10, 9: keep last; 8: on-demand; 7: staging alias and provision through it; 5: weight; 4: async; 3: prod; 2: trigger
+	assert.Equal([]string{"6", "1"}, report.Deleted())
+
+	byID := map[string]VersionReport{}
+	for _, v := range report.Versions {
+		byID[v.VersionID] = v
+	}
+	assert.Equal([]string{"alias staging", "provision config of f1 through alias staging"}, byID["7"].Reasons)
+	assert.Equal([]string{"additional version of alias prod"}, byID["5"].Reasons)
+	assert.Equal([]string{"trigger t1 of f1"}, byID["2"].Reasons)
+	assert.Equal([]string{"on-demand config of f1"}, byID["8"].Reasons)
+	assert.Equal([]string{"among the last 2 versions"}, byID["9"].Reasons)
+	assert.Equal(ActionDelete, byID["6"].Action)
+}
+
+func (s *VersionGCTestSuite) TestRunDeletesWithMinAge() {
+	assert := s.Require()
+
+	client := newFakeClient()
+	client.failing = "1"
+	c := New(client, "service").WithMinAge(48 * time.Hour)
+	c.now = func() time.Time { return time.Date(2021, 1, 8, 12, 0, 0, 0, time.UTC) }
+	report, err := c.Run()
+	assert.Nil(err)
+	// versions 7 and later are younger than two days
+	assert.Equal([]string{"6"}, report.Deleted())
+	assert.Equal([]string{"6"}, client.deleted)
+	for _, v := range report.Versions {
+		if v.VersionID == "1" {
+			assert.Equal(ActionFailed, v.Action)
+			assert.Equal("in use", v.Error)
+		}
+	}
+}
+
+func (s *VersionGCTestSuite) TestParseCreatedTime() {
+	assert := s.Require()
+
+	expected := time.Date(2021, 1, 2, 7, 4, 5, 123000000, time.UTC)
+	for _, created := range []string{"2021-01-02T15:04:05.123+0800", "2021-01-02T07:04:05.123Z", "2021-01-02T15:04:05.123+08:00"} {
+		t, err := parseCreatedTime(created)
+		assert.Nil(err, created)
+		assert.True(expected.Equal(t), created)
+	}
+	_, err := parseCreatedTime("2021-01-02")
+	assert.NotNil(err)
+
+	// versions with an unparseable created time are kept
+	client := newFakeClient()
+	client.versions[0] = `{"versionId":"1","createdTime":"yesterday"}`
+	c := New(client, "service").WithMinAge(time.Hour).WithDryRun()
+	report, err := c.Run()
+	assert.Nil(err)
+	for _, v := range report.Versions {
+		if v.VersionID == "1" {
+			assert.Equal(ActionKeep, v.Action)
+			assert.Contains(v.Reasons, "unknown created time")
+		} else {
+			assert.NotContains(v.Reasons, "unknown created time")
+		}
+	}
+}
+
+func (s *VersionGCTestSuite) TestValidate() {
+	assert := s.Require()
+
+	assert.NotNil(New(nil, "service").Validate())
+	assert.NotNil(New(newFakeClient(), "").Validate())
+	assert.NotNil(New(newFakeClient(), "service").WithKeepLast(-1).Validate())
+}
+
+func (s *VersionGCTestSuite) TestParseResources() {
+	assert := s.Require()
+
+	qualifier, function, ok := parseProvisionResource("123#service#prod#f1", "service")
+	assert.True(ok)
+	assert.Equal("prod", qualifier)
+	assert.Equal("f1", function)
+	_, _, ok = parseProvisionResource("123#service#prod", "service")
+	assert.False(ok)
+
+	qualifier, function, ok = parseOnDemandResource("/services/service.3/functions/f2", "service")
+	assert.True(ok)
+	assert.Equal("3", qualifier)
+	assert.Equal("f2", function)
+	_, _, ok = parseOnDemandResource("services/service-2.3/functions/f2", "service")
+	assert.False(ok)
+}
@@ -0,0 +1,296 @@
+// Package warmer keeps functions without enough provisioned instances warm by periodically
+// invoking them in parallel with a recognizable warm-up request.
+//
+// Handlers should return early on warm-up requests:
+//
+//	if warmer.IsWarmupEvent(event) {
+//		return nil, nil
+//	}
+package warmer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+)
+
+// HeaderWarmup is set on every warm-up invocation.
+const HeaderWarmup = "X-Fc-Warmup"
+
+// DefaultInterval keeps instances from being reclaimed for idleness.
+const DefaultInterval = 5 * time.Minute
+
+// Payload is the event sent by warm-up invocations.
+var Payload = []byte(`{"warmup":true}`)
+
+// IsWarmupEvent reports whether an event function received a warm-up invocation.
+func IsWarmupEvent(event []byte) bool {
+	return bytes.Equal(bytes.TrimSpace(event), Payload)
+}
+
+// IsWarmupRequest reports whether a request carries the warm-up header.
+func IsWarmupRequest(header http.Header) bool {
+	return header.Get(HeaderWarmup) != ""
+}
+
+// Client is the subset of fc.Client the warmer uses.
+type Client interface {
+	InvokeFunction(input *fc.InvokeFunctionInput) (*fc.InvokeFunctionOutput, error)
+	GetProvisionConfig(input *fc.GetProvisionConfigInput) (*fc.GetProvisionConfigOutput, error)
+}
+
+// Target is a function to keep Concurrency instances warm for.
+type Target struct {
+	ServiceName  string `json:"serviceName"`
+	FunctionName string `json:"functionName"`
+	Qualifier    string `json:"qualifier"`
+	Concurrency  int    `json:"concurrency"`
+}
+
+func (t Target) String() string {
+	if t.Qualifier == "" {
+		return t.ServiceName + "/" + t.FunctionName
+	}
+	return t.ServiceName + "." + t.Qualifier + "/" + t.FunctionName
+}
+
+// Warmer periodically warms its targets.
+type Warmer struct {
+	Client   Client
+	Targets  []Target
+	Interval time.Duration
+	OnReport func(report *Report)
+
+	// seen holds the instance IDs each target has been served by in earlier rounds.
+	mu   sync.Mutex
+	seen map[string]map[string]bool
+}
+
+// New returns a warmer warming every DefaultInterval.
+func New(client Client) *Warmer {
+	return &Warmer{Client: client, Interval: DefaultInterval}
+}
+
+func (w *Warmer) WithTarget(serviceName, functionName, qualifier string, concurrency int) *Warmer {
+	w.Targets = append(w.Targets, Target{
+		ServiceName:  serviceName,
+		FunctionName: functionName,
+		Qualifier:    qualifier,
+		Concurrency:  concurrency,
+	})
+	return w
+}
+
+func (w *Warmer) WithInterval(interval time.Duration) *Warmer {
+	w.Interval = interval
+	return w
+}
+
+// WithReportHandler sets the callback receiving the report of every round.
+func (w *Warmer) WithReportHandler(handler func(report *Report)) *Warmer {
+	w.OnReport = handler
+	return w
+}
+
+// Validate ...
+func (w *Warmer) Validate() error {
+	if w.Client == nil {
+		return fmt.Errorf("Client is required but not provided")
+	}
+	if w.Interval <= 0 {
+		return fmt.Errorf("invalid interval %s", w.Interval)
+	}
+	for _, t := range w.Targets {
+		if t.ServiceName == "" || t.FunctionName == "" {
+			return fmt.Errorf("Service name and function name are required but not provided")
+		}
+		if t.Concurrency <= 0 {
+			return fmt.Errorf("invalid concurrency %d for %s", t.Concurrency, t)
+		}
+	}
+	return nil
+}
+
+// Run warms the targets immediately and then every interval until the context is canceled.
+func (w *Warmer) Run(ctx context.Context) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+	for {
+		report := w.WarmOnce(ctx)
+		if w.OnReport != nil {
+			w.OnReport(report)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// WarmOnce runs one round over all targets in parallel.
+func (w *Warmer) WarmOnce(ctx context.Context) *Report {
+	report := &Report{Time: time.Now(), Results: make([]TargetResult, len(w.Targets))}
+	var wg sync.WaitGroup
+	for i, target := range w.Targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			report.Results[i] = w.warm(ctx, target)
+		}(i, target)
+	}
+	wg.Wait()
+	return report
+}
+
+// warm invokes the target as many times in parallel as its concurrency is not covered by provisioned instances.
+func (w *Warmer) warm(ctx context.Context, target Target) TargetResult {
+	result := TargetResult{Target: target}
+	provisioned, err := w.provisioned(target)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	result.Provisioned = provisioned
+	count := target.Concurrency - int(provisioned)
+	if count <= 0 {
+		result.Skipped = true
+		return result
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	instances := map[string]struct{}{}
+	for i := 0; i < count; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			input := fc.NewInvokeFunctionInput(target.ServiceName, target.FunctionName).
+				WithPayload(Payload).
+				WithHeader(HeaderWarmup, "true")
+			if target.Qualifier != "" {
+				input.WithQualifier(target.Qualifier)
+			}
+			output, err := w.Client.InvokeFunction(input)
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Invoked++
+			if err != nil {
+				result.Errors = append(result.Errors, err.Error())
+				return
+			}
+			if errorType := output.GetErrorType(); errorType != "" {
+				result.Errors = append(result.Errors, errorType+": "+string(output.Payload))
+				return
+			}
+			result.Succeeded++
+			if id := output.GetInstanceID(); id != "" {
+				instances[id] = struct{}{}
+			}
+		}()
+	}
+	wg.Wait()
+	result.Instances = len(instances)
+	result.NewInstances = w.newInstances(target, instances)
+	return result
+}
+
+// newInstances records the instances serving the target and returns how many did not serve it in
+// earlier rounds. Nothing is new on the first round of a target.
+func (w *Warmer) newInstances(target Target, instances map[string]struct{}) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.seen == nil {
+		w.seen = map[string]map[string]bool{}
+	}
+	key := target.String()
+	seen, ok := w.seen[key]
+	if !ok {
+		seen = map[string]bool{}
+		w.seen[key] = seen
+	}
+	n := 0
+	for id := range instances {
+		if !seen[id] {
+			seen[id] = true
+			if ok {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// provisioned returns the provisioned instance target of the function. Provisioning requires a
+// qualifier, so unqualified targets are never covered.
+func (w *Warmer) provisioned(target Target) (int64, error) {
+	if target.Qualifier == "" {
+		return 0, nil
+	}
+	output, err := w.Client.GetProvisionConfig(fc.NewGetProvisionConfigInput(target.ServiceName, target.Qualifier, target.FunctionName))
+	if err != nil {
+		if e, ok := err.(*fc.ServiceError); ok && e.HTTPStatus == http.StatusNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get provision config of %s: %v", target, err)
+	}
+	if output.Target == nil {
+		return 0, nil
+	}
+	return *output.Target, nil
+}
+
+// Report is the outcome of one warm-up round.
+type Report struct {
+	Time    time.Time      `json:"time"`
+	Results []TargetResult `json:"results"`
+}
+
+// Misses returns the results of targets that were not kept warm.
+func (r *Report) Misses() []TargetResult {
+	var misses []TargetResult
+	for _, result := range r.Results {
+		if result.Missed() {
+			misses = append(misses, result)
+		}
+	}
+	return misses
+}
+
+func (r *Report) String() string {
+	b, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// TargetResult is the outcome of warming one target.
+type TargetResult struct {
+	Target       Target   `json:"target"`
+	Provisioned  int64    `json:"provisioned"`
+	Skipped      bool     `json:"skipped"`
+	Invoked      int      `json:"invoked"`
+	Succeeded    int      `json:"succeeded"`
+	Instances    int      `json:"instances"`
+	NewInstances int      `json:"newInstances"`
+	Errors       []string `json:"errors,omitempty"`
+}
+
+// Missed reports whether a warm-up invocation failed or was served by an instance not seen in
+// earlier rounds, meaning the previous round did not keep the function warm.
+func (r TargetResult) Missed() bool {
+	return !r.Skipped && (r.NewInstances > 0 || r.Succeeded < r.Invoked)
+}
@@ -0,0 +1,152 @@
+package warmer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	fc "github.com/aliyun/fc-go-sdk"
+	"github.com/stretchr/testify/suite"
+)
+
+type fakeClient struct {
+	mu          sync.Mutex
+	invocations map[string]int
+	provisioned map[string]int64
+	cold        map[string]bool
+	failing     map[string]bool
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{
+		invocations: map[string]int{},
+		provisioned: map[string]int64{},
+		cold:        map[string]bool{},
+		failing:     map[string]bool{},
+	}
+}
+
+func (c *fakeClient) InvokeFunction(input *fc.InvokeFunctionInput) (*fc.InvokeFunctionOutput, error) {
+	if !IsWarmupRequest(http.Header{HeaderWarmup: []string{input.GetHeaders()[HeaderWarmup]}}) || !IsWarmupEvent(*input.Payload) {
+		return nil, errors.New("not a warm-up invocation")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	name := *input.FunctionName
+	c.invocations[name]++
+	if c.failing[name] {
+		return nil, errors.New("failed")
+	}
+	header := http.Header{}
+	header.Set(fc.HTTPHeaderInstanceID, name+"-instance")
+	if c.cold[name] {
+		// every invocation is served by a new instance
+		header.Set(fc.HTTPHeaderInstanceID, fmt.Sprintf("%s-instance-%d", name, c.invocations[name]))
+	}
+	return &fc.InvokeFunctionOutput{Header: header}, nil
+}
+
+func (c *fakeClient) GetProvisionConfig(input *fc.GetProvisionConfigInput) (*fc.GetProvisionConfigOutput, error) {
+	target, ok := c.provisioned[*input.FunctionName]
+	if !ok {
+		return nil, &fc.ServiceError{HTTPStatus: http.StatusNotFound, ErrorCode: "ProvisionConfigNotExist"}
+	}
+	output := &fc.GetProvisionConfigOutput{}
+	output.Target = &target
+	return output, nil
+}
+
+type WarmerTestSuite struct {
+	suite.Suite
+}
+
+func TestWarmer(t *testing.T) {
+	suite.Run(t, new(WarmerTestSuite))
+}
+
+func (s *WarmerTestSuite) TestWarmOnce() {
+	assert := s.Require()
+
+	client := newFakeClient()
+	client.provisioned["covered"] = 5
+	client.provisioned["partial"] = 2
+	client.cold["cold"] = true
+	client.failing["broken"] = true
+	w := New(client).
+		WithTarget("svc", "covered", "prod", 3).
+		WithTarget("svc", "partial", "prod", 5).
+		WithTarget("svc", "cold", "", 2).
+		WithTarget("svc", "broken", "prod", 1)
+
+	report := w.WarmOnce(context.Background())
+	assert.Len(report.Results, 4)
+
+	covered := report.Results[0]
+	assert.True(covered.Skipped)
+	assert.Equal(int64(5), covered.Provisioned)
+	assert.Equal(0, client.invocations["covered"])
+
+	partial := report.Results[1]
+	assert.False(partial.Skipped)
+	assert.Equal(3, partial.Invoked)
+	assert.Equal(3, partial.Succeeded)
+	assert.Equal(1, partial.Instances)
+	assert.False(partial.Missed())
+
+	// instances serving the first round are not new
+	cold := report.Results[2]
+	assert.Equal(2, cold.Instances)
+	assert.Equal(0, cold.NewInstances)
+	assert.False(cold.Missed())
+
+	broken := report.Results[3]
+	assert.Equal(1, broken.Invoked)
+	assert.Equal(0, broken.Succeeded)
+	assert.Equal([]string{"failed"}, broken.Errors)
+
+	misses := report.Misses()
+	assert.Len(misses, 1)
+	assert.Equal("svc.prod/broken", misses[0].Target.String())
+
+	report = w.WarmOnce(context.Background())
+	assert.Equal(0, report.Results[1].NewInstances)
+	assert.Equal(2, report.Results[2].NewInstances)
+	misses = report.Misses()
+	assert.Len(misses, 2)
+	assert.Equal("svc/cold", misses[0].Target.String())
+	assert.Equal("svc.prod/broken", misses[1].Target.String())
+}
+
+func (s *WarmerTestSuite) TestRun() {
+	assert := s.Require()
+
+	client := newFakeClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	var reports []*Report
+	w := New(client).WithTarget("svc", "fn", "", 2).WithInterval(10 * time.Millisecond).
+		WithReportHandler(func(report *Report) {
+			reports = append(reports, report)
+			if len(reports) == 3 {
+				cancel()
+			}
+		})
+	assert.Nil(w.Run(ctx))
+	assert.Len(reports, 3)
+	assert.Equal(6, client.invocations["fn"])
+
+	assert.NotNil(New(nil).Run(context.Background()))
+	assert.NotNil(New(client).WithTarget("svc", "fn", "", 0).Run(context.Background()))
+	assert.NotNil(New(client).WithInterval(0).Run(context.Background()))
+}
+
+func (s *WarmerTestSuite) TestIsWarmup() {
+	assert := s.Require()
+
+	assert.True(IsWarmupEvent([]byte(" {\"warmup\":true}\n")))
+	assert.False(IsWarmupEvent([]byte(`{"key":"value"}`)))
+	assert.False(IsWarmupRequest(http.Header{}))
+}
@@ -3,60 +3,37 @@ package fc
 import (
 	"archive/zip"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
 // TempZipDir zips everything from source dir into a temporary zip file which doesn't include the source dir but its content.
+// Files matched by the IgnoreFileName file in the source dir are left out.
 // Return the location of the temporary zip file.
 func TempZipDir(dir string) (string, error) {
-	// Collect files to zip.
-	fs, err := ioutil.ReadDir(dir)
-	if err != nil {
-		return "", err
-	}
-	files := []string{}
-	for _, f := range fs {
-		files = append(files, filepath.Join(dir, f.Name()))
-	}
-	return TmpZip(files)
+	return TempZipDirWithOptions(dir, ZipOptions{})
 }
 
 // TmpZip everything from source file into a temporary zip file.
 // Return the location of the temporary zip file.
 func TmpZip(files []string) (string, error) {
-	zipfile, err := ioutil.TempFile("", "fc_temp_file_")
-	if err != nil {
-		return "", err
-	}
-
-	archive := zip.NewWriter(zipfile)
-	defer archive.Close()
-
-	for _, source := range files {
-		if err := compress(source, archive); err != nil {
-			return "", err
-		}
-	}
-
-	return zipfile.Name(), nil
+	return TmpZipWithOptions(files, ZipOptions{})
 }
 
 // Zip everything from the source (either file/directory) recursively into target zip file.
+// Files matched by the IgnoreFileName file in a source directory are left out.
 func Zip(files []string, target string) error {
-	zipfile, err := os.Create(target)
-	if err != nil {
-		return err
-	}
-	defer zipfile.Close()
+	return ZipWithOptions(files, target, ZipOptions{})
+}
 
-	archive := zip.NewWriter(zipfile)
+// zipSources compresses each source into the output archive.
+func zipSources(sources []zipSource, output io.Writer) error {
+	archive := zip.NewWriter(output)
 	defer archive.Close()
 
-	for _, f := range files {
-		if err := compress(f, archive); err != nil {
+	for _, s := range sources {
+		if err := compress(s.path, archive, s.filter); err != nil {
 			return err
 		}
 	}
@@ -65,7 +42,7 @@ func Zip(files []string, target string) error {
 }
 
 // Compress zips source file into archive file.
-func compress(source string, archive *zip.Writer) error {
+func compress(source string, archive *zip.Writer, filter *packageFilter) error {
 	srcInfo, err := os.Stat(source)
 	if err != nil {
 		return err
@@ -76,6 +53,13 @@ func compress(source string, archive *zip.Writer) error {
 			return err
 		}
 
+		if filter.prune(path, info) {
+			return filepath.SkipDir
+		}
+		if filter.excluded(path, info) {
+			return nil
+		}
+
 		header, err := zip.FileInfoHeader(info)
 		if err != nil {
 			return err
@@ -121,20 +105,26 @@ func compress(source string, archive *zip.Writer) error {
 }
 
 // ZipDir zip up a directory and preserve symlinks and empty directories.
+// Files matched by the IgnoreFileName file in the directory are left out.
 func ZipDir(srcDir string, output io.Writer) error {
+	return ZipDirWithOptions(srcDir, output, ZipOptions{})
+}
+
+// zipDir zips up the absolute srcDir, skipping what the filter excludes.
+func zipDir(srcDir string, output io.Writer, filter *packageFilter) error {
 	zipWriter := zip.NewWriter(output)
 	defer zipWriter.Close()
 
-	// Convert the input dir path to absolute path if necessary.
-	srcDir, err := filepath.Abs(srcDir)
-	if err != nil {
-		return err
-	}
-
 	return filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
+		if filter.prune(path, info) {
+			return filepath.SkipDir
+		}
+		if filter.excluded(path, info) {
+			return nil
+		}
 		header, err := zip.FileInfoHeader(info)
 		if err != nil {
 			return err
@@ -0,0 +1,361 @@
+package fc
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// deterministicModTime is the timestamp of every entry in a deterministic archive, the zip epoch.
+var deterministicModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// ZipOptions controls how code packages are built.
+type ZipOptions struct {
+	// Deterministic builds the same archive from the same content: entries are sorted by name,
+	// timestamps are fixed, permissions are normalized to 0755 for directories and executables
+	// and 0644 for other files, and names must be valid UTF-8.
+	Deterministic bool
+	// Includes, when set, limits the package to files matching one of these gitignore style patterns.
+	Includes []string
+	// Excludes are gitignore style patterns of files left out, applied after the IgnoreFileName file.
+	Excludes []string
+	// DisableIgnoreFile packages files even if the IgnoreFileName file matches them.
+	DisableIgnoreFile bool
+}
+
+// ZipDirWithOptions zips up a directory like ZipDir, applying the options.
+func ZipDirWithOptions(srcDir string, output io.Writer, opts ZipOptions) error {
+	srcDir, err := filepath.Abs(srcDir)
+	if err != nil {
+		return err
+	}
+	filter, err := newPackageFilter(srcDir, opts)
+	if err != nil {
+		return err
+	}
+	if !opts.Deterministic {
+		return zipDir(srcDir, output, filter)
+	}
+	entries, err := collectZipEntries(srcDir, "", filter)
+	if err != nil {
+		return err
+	}
+	return writeZipEntries(output, entries, opts)
+}
+
+// ZipWithOptions zips the files into the target like Zip, applying the options.
+func ZipWithOptions(files []string, target string, opts ZipOptions) error {
+	sources, err := newZipSources(files, opts)
+	if err != nil {
+		return err
+	}
+	zipfile, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	defer zipfile.Close()
+	return zipFilesWithOptions(sources, zipfile, opts)
+}
+
+// TmpZipWithOptions zips the files into a temporary zip file like TmpZip, applying the options.
+// Directories are filtered by their own IgnoreFileName file, while files given explicitly are
+// only filtered by the Includes and Excludes patterns on their base name.
+func TmpZipWithOptions(files []string, opts ZipOptions) (string, error) {
+	sources, err := newZipSources(files, opts)
+	if err != nil {
+		return "", err
+	}
+	return tmpZipSources(sources, opts)
+}
+
+// TempZipDirWithOptions zips the content of the dir into a temporary zip file like TempZipDir, applying the options.
+func TempZipDirWithOptions(dir string, opts ZipOptions) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	filter, err := newPackageFilter(dir, opts)
+	if err != nil {
+		return "", err
+	}
+	// Collect files to zip.
+	fs, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	sources := []zipSource{}
+	for _, f := range fs {
+		path := filepath.Join(dir, f.Name())
+		if filter.prune(path, f) || filter.excluded(path, f) && !f.IsDir() {
+			continue
+		}
+		sources = append(sources, zipSource{path: path, filter: filter})
+	}
+	return tmpZipSources(sources, opts)
+}
+
+// ListPackageFiles returns the sorted entry names, directories ending in "/", of the archive
+// ZipDirWithOptions would build from the directory, without building it.
+func ListPackageFiles(dir string, opts ZipOptions) ([]string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := newPackageFilter(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := collectZipEntries(dir, "", filter)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, e.name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func tmpZipSources(sources []zipSource, opts ZipOptions) (string, error) {
+	zipfile, err := ioutil.TempFile("", "fc_temp_file_")
+	if err != nil {
+		return "", err
+	}
+	defer zipfile.Close()
+	if err := zipFilesWithOptions(sources, zipfile, opts); err != nil {
+		os.Remove(zipfile.Name())
+		return "", err
+	}
+	return zipfile.Name(), nil
+}
+
+// zipFilesWithOptions zips each file, or directory under its base name, as compress does.
+func zipFilesWithOptions(sources []zipSource, output io.Writer, opts ZipOptions) error {
+	if !opts.Deterministic {
+		return zipSources(sources, output)
+	}
+	var entries []zipEntry
+	for _, s := range sources {
+		info, err := os.Lstat(s.path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			if !s.filter.excluded(s.path, info) {
+				entries = append(entries, zipEntry{path: s.path, name: filepath.Base(s.path), info: info})
+			}
+			continue
+		}
+		prefix := filepath.Base(s.path) + "/"
+		if !s.filter.excluded(s.path, info) {
+			entries = append(entries, zipEntry{path: s.path, name: prefix, info: info})
+		}
+		dirEntries, err := collectZipEntries(s.path, prefix, s.filter)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, dirEntries...)
+	}
+	return writeZipEntries(output, entries, opts)
+}
+
+// zipSource is an absolute file or directory to compress with the filter to apply.
+type zipSource struct {
+	path   string
+	filter *packageFilter
+}
+
+func newZipSources(files []string, opts ZipOptions) ([]zipSource, error) {
+	sources := make([]zipSource, 0, len(files))
+	for _, f := range files {
+		path, err := filepath.Abs(f)
+		if err != nil {
+			return nil, err
+		}
+		info, err := os.Lstat(path)
+		if err != nil {
+			return nil, err
+		}
+		root, rootOpts := path, opts
+		if !info.IsDir() {
+			root, rootOpts.DisableIgnoreFile = filepath.Dir(path), true
+		}
+		filter, err := newPackageFilter(root, rootOpts)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, zipSource{path: path, filter: filter})
+	}
+	return sources, nil
+}
+
+// packageFilter decides which paths under the absolute root are packaged.
+type packageFilter struct {
+	root     string
+	ignore   *IgnoreMatcher
+	includes *IgnoreMatcher
+}
+
+func newPackageFilter(root string, opts ZipOptions) (*packageFilter, error) {
+	var patterns []string
+	if !opts.DisableIgnoreFile {
+		lines, err := readIgnoreFile(root)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, lines...)
+	}
+	patterns = append(patterns, opts.Excludes...)
+	ignore, err := NewIgnoreMatcher(patterns...)
+	if err != nil {
+		return nil, err
+	}
+	filter := &packageFilter{root: root, ignore: ignore}
+	if len(opts.Includes) > 0 {
+		if filter.includes, err = NewIgnoreMatcher(opts.Includes...); err != nil {
+			return nil, err
+		}
+	}
+	return filter, nil
+}
+
+func (f *packageFilter) rel(path string) string {
+	rel, err := filepath.Rel(f.root, path)
+	if err != nil {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// prune reports whether the path is an ignored directory whose content is skipped.
+func (f *packageFilter) prune(path string, info os.FileInfo) bool {
+	return f != nil && info.IsDir() && f.ignore.Match(f.rel(path), true)
+}
+
+// excluded reports whether the path itself is left out of the package. Directories not matching
+// the includes are left out but still walked for files that do match.
+func (f *packageFilter) excluded(path string, info os.FileInfo) bool {
+	if f == nil {
+		return false
+	}
+	rel := f.rel(path)
+	if rel == "." || rel == "" {
+		return false
+	}
+	if f.ignore.Match(rel, info.IsDir()) {
+		return true
+	}
+	return f.includes != nil && !f.includes.Match(rel, info.IsDir())
+}
+
+// ZipContentHash returns the hex encoded SHA-256 of a zip archive.
+func ZipContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type zipEntry struct {
+	path string
+	name string
+	info os.FileInfo
+}
+
+// collectZipEntries walks the directory, naming entries by their slash separated path relative
+// to root with the prefix. The root itself is not included.
+func collectZipEntries(root, prefix string, filter *packageFilter) ([]zipEntry, error) {
+	var entries []zipEntry
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		if filter.prune(path, info) {
+			return filepath.SkipDir
+		}
+		if filter.excluded(path, info) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		name := prefix + filepath.ToSlash(rel)
+		if info.IsDir() {
+			name += "/"
+		}
+		entries = append(entries, zipEntry{path: path, name: name, info: info})
+		return nil
+	})
+	return entries, err
+}
+
+// writeZipEntries writes the entries sorted by name with normalized headers.
+func writeZipEntries(output io.Writer, entries []zipEntry, opts ZipOptions) error {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	archive := zip.NewWriter(output)
+	for _, e := range entries {
+		if err := writeZipEntry(archive, e); err != nil {
+			archive.Close()
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+func writeZipEntry(archive *zip.Writer, e zipEntry) error {
+	if !utf8.ValidString(e.name) {
+		return fmt.Errorf("file name %q is not valid UTF-8", e.name)
+	}
+	header := &zip.FileHeader{
+		Name:     e.name,
+		Modified: deterministicModTime,
+		// names are always flagged UTF-8 so they decode the same on every platform
+		Flags: 1 << 11,
+	}
+	mode := e.info.Mode()
+	switch {
+	case mode.IsDir():
+		header.SetMode(os.ModeDir | 0755)
+	case mode&os.ModeSymlink != 0:
+		header.SetMode(os.ModeSymlink | 0777)
+	case mode&0111 != 0:
+		header.Method = zip.Deflate
+		header.SetMode(0755)
+	default:
+		header.Method = zip.Deflate
+		header.SetMode(0644)
+	}
+	writer, err := archive.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	switch {
+	case mode.IsDir():
+		return nil
+	case mode&os.ModeSymlink != 0:
+		dest, err := os.Readlink(e.path)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write([]byte(dest))
+		return err
+	}
+	file, err := os.Open(e.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(writer, file)
+	return err
+}
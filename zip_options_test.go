@@ -0,0 +1,147 @@
+package fc
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ZipOptionsTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestZipOptions(t *testing.T) {
+	suite.Run(t, new(ZipOptionsTestSuite))
+}
+
+func (s *ZipOptionsTestSuite) SetupTest() {
+	assert := s.Require()
+
+	dir, err := ioutil.TempDir("", "fc_zip_options_")
+	assert.Nil(err)
+	s.dir = dir
+	assert.Nil(os.MkdirAll(filepath.Join(dir, "lib", "empty"), 0700))
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "main.py"), []byte("print(1)"), 0600))
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "bootstrap"), []byte("#!/bin/sh"), 0700))
+	assert.Nil(ioutil.WriteFile(filepath.Join(dir, "lib", "util.py"), []byte("x = 1"), 0666))
+	assert.Nil(os.Symlink("main.py", filepath.Join(dir, "link.py")))
+}
+
+func (s *ZipOptionsTestSuite) TearDownTest() {
+	os.RemoveAll(s.dir)
+}
+
+func (s *ZipOptionsTestSuite) zipDir() []byte {
+	buf := &bytes.Buffer{}
+	s.Require().Nil(ZipDirWithOptions(s.dir, buf, ZipOptions{Deterministic: true}))
+	return buf.Bytes()
+}
+
+func (s *ZipOptionsTestSuite) TestDeterministicZipDir() {
+	assert := s.Require()
+
+	first := s.zipDir()
+	later := time.Now().Add(time.Hour)
+	assert.Nil(os.Chtimes(filepath.Join(s.dir, "main.py"), later, later))
+	assert.Nil(os.Chmod(filepath.Join(s.dir, "lib", "util.py"), 0600))
+	second := s.zipDir()
+	assert.Equal(first, second)
+	assert.Equal(ZipContentHash(first), ZipContentHash(second))
+
+	reader, err := zip.NewReader(bytes.NewReader(first), int64(len(first)))
+	assert.Nil(err)
+	names := []string{}
+	modes := map[string]os.FileMode{}
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+		modes[f.Name] = f.Mode()
+		assert.True(f.Modified.Equal(deterministicModTime), f.Name)
+		assert.NotZero(f.Flags & (1 << 11))
+	}
+	assert.Equal([]string{"bootstrap", "lib/", "lib/empty/", "lib/util.py", "link.py", "main.py"}, names)
+	assert.Equal(os.FileMode(0755), modes["bootstrap"])
+	assert.Equal(os.FileMode(0644), modes["main.py"])
+	assert.Equal(os.ModeDir|0755, modes["lib/"])
+	assert.Equal(os.ModeSymlink|0777, modes["link.py"])
+}
+
+func (s *ZipOptionsTestSuite) TestDeterministicZipFiles() {
+	assert := s.Require()
+
+	target := filepath.Join(s.dir, "..", filepath.Base(s.dir)+".zip")
+	defer os.Remove(target)
+	assert.Nil(ZipWithOptions([]string{filepath.Join(s.dir, "lib"), filepath.Join(s.dir, "main.py")}, target, ZipOptions{Deterministic: true}))
+	reader, err := zip.OpenReader(target)
+	assert.Nil(err)
+	defer reader.Close()
+	names := []string{}
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal([]string{"lib/", "lib/empty/", "lib/util.py", "main.py"}, names)
+
+	tmp, err := TmpZipWithOptions([]string{filepath.Join(s.dir, "main.py")}, ZipOptions{Deterministic: true})
+	assert.Nil(err)
+	os.Remove(tmp)
+}
+
+func (s *ZipOptionsTestSuite) TestDeterministicZipKeepsSymlinks() {
+	assert := s.Require()
+
+	assert.Nil(os.Symlink("lib", filepath.Join(s.dir, "linkdir")))
+	files := []string{filepath.Join(s.dir, "link.py"), filepath.Join(s.dir, "linkdir")}
+	for _, deterministic := range []bool{false, true} {
+		buf := &bytes.Buffer{}
+		sources, err := newZipSources(files, ZipOptions{})
+		assert.Nil(err)
+		assert.Nil(zipFilesWithOptions(sources, buf, ZipOptions{Deterministic: deterministic}))
+		reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.Nil(err)
+		targets := map[string]string{}
+		for _, f := range reader.File {
+			assert.Equal(os.ModeSymlink, f.Mode()&os.ModeType, f.Name)
+			r, err := f.Open()
+			assert.Nil(err)
+			target, err := ioutil.ReadAll(r)
+			r.Close()
+			assert.Nil(err)
+			targets[f.Name] = string(target)
+		}
+		assert.Equal(map[string]string{"link.py": "main.py", "linkdir": "lib"}, targets, "deterministic %v", deterministic)
+	}
+}
+
+func (s *ZipOptionsTestSuite) TestCodeContentHash() {
+	assert := s.Require()
+
+	code := NewCode().WithZipOptions(ZipOptions{Deterministic: true}).WithDir(s.dir)
+	assert.Nil(code.err)
+	hash, err := code.ContentHash()
+	assert.Nil(err)
+	assert.Equal(ZipContentHash(s.zipDir()), hash)
+
+	again, err := NewCode().WithZipOptions(ZipOptions{Deterministic: true}).WithFiles(filepath.Join(s.dir, "main.py")).ContentHash()
+	assert.Nil(err)
+	other, err := NewCode().WithZipOptions(ZipOptions{Deterministic: true}).WithFiles(filepath.Join(s.dir, "main.py")).ContentHash()
+	assert.Nil(err)
+	assert.Equal(again, other)
+
+	_, err = NewCode().ContentHash()
+	assert.NotNil(err)
+}
+
+func (s *ZipOptionsTestSuite) TestInvalidUTF8Name() {
+	assert := s.Require()
+
+	assert.Nil(ioutil.WriteFile(filepath.Join(s.dir, "bad\xff.txt"), nil, 0644))
+	err := ZipDirWithOptions(s.dir, &bytes.Buffer{}, ZipOptions{Deterministic: true})
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "not valid UTF-8")
+}
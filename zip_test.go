@@ -62,54 +62,57 @@ func TestZipEmptyDir(t *testing.T) {
 }
 
 func TestZipDirWithSymbolLinks(t *testing.T)  {
-	// Cleanup the last data to prevent from confliction.
-	os.RemoveAll("./TestZipDirWithSymbolLinks/")
+	root, err := ioutil.TempDir("", "TestZipDirWithSymbolLinks")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(root)
 
 	// Create file and symbol links.
-	err := os.MkdirAll("./TestZipDirWithSymbolLinks/src/", 0777)
+	err = os.MkdirAll(filepath.Join(root, "src"), 0777)
 	if err != nil {
 		panic(err)
 	}
-	err = ioutil.WriteFile("./TestZipDirWithSymbolLinks/src/main.py", []byte("this is a file."), 0666)
+	err = ioutil.WriteFile(filepath.Join(root, "src", "main.py"), []byte("this is a file."), 0666)
 	if err != nil {
 		panic(err)
 	}
 	// Write link with absolute path.
-	absPath, _ := filepath.Abs("./TestZipDirWithSymbolLinks/src/main.py")
-	err = os.Symlink(absPath, "./TestZipDirWithSymbolLinks/src/symbol_link")
+	absPath, _ := filepath.Abs(filepath.Join(root, "src", "main.py"))
+	err = os.Symlink(absPath, filepath.Join(root, "src", "symbol_link"))
 	if err != nil {
 		panic(err)
 	}
 
 	// Zip the directory.
-	err = os.MkdirAll("./TestZipDirWithSymbolLinks/dst", 0777)
+	err = os.MkdirAll(filepath.Join(root, "dst"), 0777)
 	if err != nil {
 		panic(err)
 	}
-	zip, err := os.Create("./TestZipDirWithSymbolLinks/dst/link.zip")
+	zip, err := os.Create(filepath.Join(root, "dst", "link.zip"))
 	defer zip.Close()
-	err = ZipDir("./TestZipDirWithSymbolLinks/src", zip)
+	err = ZipDir(filepath.Join(root, "src"), zip)
 	if err != nil {
 		panic(err)
 	}
 
 	// Use system command to unzip the file.
 	cmd := exec.Command("sh", "-c", "unzip link.zip")
-	cmd.Dir = "./TestZipDirWithSymbolLinks/dst/"
+	cmd.Dir = filepath.Join(root, "dst")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		t.Fatalf("%v: %s", err, out)
 	}
 
 	// Verify the file attributes.
-	dir, err := os.Open("./TestZipDirWithSymbolLinks/dst")
+	dir, err := os.Open(filepath.Join(root, "dst"))
 	entries, err := dir.Readdir(-1)
 	// 3 elems: zip file, file and its links.
 	if len(entries) != 3 {
 		t.Fatalf("%v", entries)
 	}
 	dir.Close()
-	info, err := os.Lstat("./TestZipDirWithSymbolLinks/dst/symbol_link")
+	info, err := os.Lstat(filepath.Join(root, "dst", "symbol_link"))
 	if err != nil {
 		panic(err)
 	}
@@ -118,14 +121,14 @@ func TestZipDirWithSymbolLinks(t *testing.T)  {
 	}
 
 	// Verify the file and its symbol link.
-	data, err := ioutil.ReadFile("./TestZipDirWithSymbolLinks/dst/main.py")
+	data, err := ioutil.ReadFile(filepath.Join(root, "dst", "main.py"))
 	if err != nil {
 		panic(err)
 	}
 	if string(data) != "this is a file." {
 		t.Fatalf("%s", string(data))
 	}
-	linkData, err := ioutil.ReadFile("./TestZipDirWithSymbolLinks/dst/symbol_link")
+	linkData, err := ioutil.ReadFile(filepath.Join(root, "dst", "symbol_link"))
 	if err != nil {
 		panic(err)
 	}